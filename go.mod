@@ -5,6 +5,8 @@ go 1.21
 require (
 	github.com/gorilla/mux v1.8.1
 	github.com/rs/zerolog v1.34.0
+	go.etcd.io/bbolt v1.3.9
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// batchEntry is a single summoner and match history in a batch request
+type batchEntry struct {
+	Summoner *models.Summoner       `json:"summoner"`
+	Matches  json.RawMessage        `json:"matches"`
+	Options  models.AnalysisOptions `json:"options"`
+}
+
+// batchOutcome is the result of analyzing a single batch entry
+type batchOutcome struct {
+	result *models.AnalysisResult
+	err    error
+}
+
+// AnalyzeBatch handles batch analysis requests for several summoners
+// Entries are analyzed concurrently; a failing entry is reported in the errors map, with the same error
+// envelope a single analysis would return, without failing the batch
+func (handler *Handler) AnalyzeBatch(writer http.ResponseWriter, request *http.Request) {
+	var batchRequest struct {
+		Entries []batchEntry `json:"entries"`
+	}
+
+	if apiError := decodeBody(request, &batchRequest); apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	if len(batchRequest.Entries) == 0 {
+		apierror.Write(writer, request, apierror.Validation("At least one entry is required", apierror.FieldError{
+			Pointer: "/entries",
+			Message: "must contain at least one entry",
+		}))
+		return
+	}
+
+	if len(batchRequest.Entries) > handler.maxBatchSize {
+		message := fmt.Sprintf("Batch exceeds the maximum of %d entries", handler.maxBatchSize)
+		apierror.Write(writer, request, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, message))
+		return
+	}
+
+	// Repeated PUUIDs are rejected up front so a duplicate is never analyzed or stored in the history
+	duplicates := duplicateEntries(batchRequest.Entries)
+
+	format := request.URL.Query().Get("format")
+	outcomes := handler.analyzeEntries(request.Context(), batchRequest.Entries, format, duplicates)
+
+	batchResult := &models.BatchAnalysisResult{
+		Results: make(map[string]*models.AnalysisResult),
+		Errors:  make(map[string]*apierror.Error),
+	}
+
+	for index, entry := range batchRequest.Entries {
+		key := batchEntryKey(index, entry)
+
+		if duplicates[index] {
+			batchResult.Errors[fmt.Sprintf("entries[%d]", index)] = apierror.Validation(fmt.Sprintf("Duplicate entry for PUUID %q", key), apierror.FieldError{
+				Pointer: fmt.Sprintf("/entries/%d/summoner/puuid", index),
+				Message: "duplicates an earlier entry",
+			})
+			continue
+		}
+
+		if outcomes[index].err != nil {
+			batchResult.Errors[key] = entryError(outcomes[index].err)
+			continue
+		}
+
+		batchResult.Results[key] = outcomes[index].result
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(batchResult)
+}
+
+// duplicateEntries marks every entry whose PUUID already appeared in an earlier entry
+func duplicateEntries(entries []batchEntry) map[int]bool {
+	duplicates := make(map[int]bool)
+	seenPUUIDs := make(map[string]bool)
+	for index, entry := range entries {
+		if entry.Summoner == nil || entry.Summoner.PUUID == "" {
+			continue
+		}
+		if seenPUUIDs[entry.Summoner.PUUID] {
+			duplicates[index] = true
+		}
+		seenPUUIDs[entry.Summoner.PUUID] = true
+	}
+	return duplicates
+}
+
+// analyzeEntries analyzes batch entries with a bounded worker pool, preserving entry order
+// Entries marked in skip are not analyzed and keep an empty outcome
+func (handler *Handler) analyzeEntries(ctx context.Context, entries []batchEntry, format string, skip map[int]bool) []batchOutcome {
+	outcomes := make([]batchOutcome, len(entries))
+
+	workers := handler.batchWorkers
+	if workers > len(entries) {
+		workers = len(entries)
+	}
+
+	jobs := make(chan int)
+	var waitGroup sync.WaitGroup
+
+	for worker := 0; worker < workers; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range jobs {
+				outcomes[index] = handler.analyzeEntry(ctx, &entries[index], format, fmt.Sprintf("/entries/%d", index))
+			}
+		}()
+	}
+
+	for index := range entries {
+		if !skip[index] {
+			jobs <- index
+		}
+	}
+	close(jobs)
+
+	waitGroup.Wait()
+	return outcomes
+}
+
+// analyzeEntry validates and analyzes a single batch entry located at pointerPrefix in the request body
+// A panic during analysis is reported as the entry's error so other entries still complete
+func (handler *Handler) analyzeEntry(ctx context.Context, entry *batchEntry, format string, pointerPrefix string) (outcome batchOutcome) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err, isError := recovered.(error)
+			if !isError {
+				err = fmt.Errorf("%v", recovered)
+			}
+			outcome = batchOutcome{err: fmt.Errorf("analysis failed: %w", err)}
+		}
+	}()
+
+	matches, apiError := handler.prepareAnalysis(entry.Summoner, entry.Matches, entry.Options, format, pointerPrefix)
+	if apiError != nil {
+		return batchOutcome{err: apiError}
+	}
+
+	analysisResult := handler.analysisService.AnalyzePlayerWithOptions(ctx, entry.Summoner, matches, entry.Options)
+	if analysisResult != nil {
+		for index := range analysisResult.Warnings {
+			analysisResult.Warnings[index].Pointer = pointerPrefix + analysisResult.Warnings[index].Pointer
+		}
+		handler.storeAnalysis(ctx, entry.Summoner.PUUID, analysisResult)
+	}
+
+	return batchOutcome{result: analysisResult}
+}
+
+// entryError converts an entry's failure into the error envelope reported for it
+// Failures other than API errors, such as a recovered panic, are reported as internal errors
+func entryError(err error) *apierror.Error {
+	var apiError *apierror.Error
+	if errors.As(err, &apiError) {
+		return apiError
+	}
+	return apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "Analysis failed")
+}
+
+// batchEntryKey returns the key an entry is reported under: its PUUID, or its position when the PUUID is missing
+func batchEntryKey(index int, entry batchEntry) string {
+	if entry.Summoner != nil && entry.Summoner.PUUID != "" {
+		return entry.Summoner.PUUID
+	}
+	return fmt.Sprintf("entries[%d]", index)
+}
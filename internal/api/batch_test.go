@@ -0,0 +1,192 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+)
+
+// postBatch sends a batch request to the handler and returns the recorder
+func postBatch(t *testing.T, handler *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyze/batch", bytes.NewBufferString(body))
+	request.Header.Set("Content-Type", "application/json")
+
+	responseRecorder := httptest.NewRecorder()
+	handler.AnalyzeBatch(responseRecorder, request)
+	return responseRecorder
+}
+
+// TestAnalyzeBatch_PartialFailure tests that failing entries are reported without failing the batch
+func TestAnalyzeBatch_PartialFailure(t *testing.T) {
+	mockService := &MockAnalysisService{
+		AnalyzePlayerFunc: func(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult {
+			if summoner.PUUID == "panics" {
+				panic("unexpected match data")
+			}
+			return &models.AnalysisResult{PlayerStats: models.PlayerStats{PUUID: summoner.PUUID, TotalMatches: len(matches)}}
+		},
+	}
+
+	handler := NewHandler(mockService)
+
+	responseRecorder := postBatch(t, handler, `{
+		"entries": [
+			{"summoner": {"puuid": "player-1"}, "matches": [
+				{"matchId": "m1", "gameDuration": 1800, "participants": [{"puuid": "player-1"}]},
+				{"matchId": "m2", "gameDuration": 1800, "participants": [{"puuid": "player-1"}]}
+			]},
+			{"summoner": {"puuid": "player-2", "tier": "WOOD"}, "matches": []},
+			{"matches": []},
+			{"summoner": {"puuid": "panics"}, "matches": []},
+			{"summoner": {"puuid": "player-1"}, "matches": []}
+		]
+	}`)
+
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, responseRecorder.Code)
+	}
+
+	var batchResult models.BatchAnalysisResult
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&batchResult); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(batchResult.Results) != 1 || batchResult.Results["player-1"].PlayerStats.TotalMatches != 2 {
+		t.Errorf("Expected only player-1 to succeed with 2 matches, got %+v", batchResult.Results)
+	}
+
+	expectedErrors := map[string]struct {
+		code    string
+		message string
+		pointer string
+	}{
+		"player-2":   {apierror.CodeValidationFailed, "Invalid benchmark selection", "/entries/1/summoner/tier"},
+		"entries[2]": {apierror.CodeValidationFailed, "Summoner data is required", "/entries/2/summoner"},
+		"panics":     {apierror.CodeInternal, "Analysis failed", ""},
+		"entries[4]": {apierror.CodeValidationFailed, "Duplicate entry", "/entries/4/summoner/puuid"},
+	}
+
+	for key, expected := range expectedErrors {
+		entryError := batchResult.Errors[key]
+		if entryError == nil {
+			t.Errorf("Expected an error for '%s'", key)
+			continue
+		}
+		if entryError.Code != expected.code || !strings.Contains(entryError.Message, expected.message) {
+			t.Errorf("Expected %s error for '%s' containing '%s', got %+v", expected.code, key, expected.message, entryError)
+		}
+		if expected.pointer == "" {
+			if len(entryError.Details) != 0 {
+				t.Errorf("Expected no details for '%s', got %+v", key, entryError.Details)
+			}
+		} else if len(entryError.Details) == 0 || entryError.Details[0].Pointer != expected.pointer {
+			t.Errorf("Expected pointer '%s' for '%s', got %+v", expected.pointer, key, entryError.Details)
+		}
+	}
+}
+
+// TestAnalyzeBatch_DuplicatesNotAnalyzed tests that a repeated PUUID is neither analyzed nor stored
+func TestAnalyzeBatch_DuplicatesNotAnalyzed(t *testing.T) {
+	var analyzed int32
+	mockService := &MockAnalysisService{
+		AnalyzePlayerFunc: func(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult {
+			atomic.AddInt32(&analyzed, 1)
+			return &models.AnalysisResult{PlayerStats: models.PlayerStats{PUUID: summoner.PUUID}}
+		},
+	}
+
+	analysisStore := storage.NewMemoryStore()
+	handler := NewHandler(mockService, WithAnalysisStore(analysisStore))
+
+	responseRecorder := postBatch(t, handler, `{"entries": [
+		{"summoner": {"puuid": "player-1"}, "matches": []},
+		{"summoner": {"puuid": "player-1"}, "matches": []}
+	]}`)
+
+	var batchResult models.BatchAnalysisResult
+	json.NewDecoder(responseRecorder.Body).Decode(&batchResult)
+	if duplicateError := batchResult.Errors["entries[1]"]; duplicateError == nil || !strings.Contains(duplicateError.Message, "Duplicate entry") {
+		t.Errorf("Expected the second entry to be reported as a duplicate, got %+v", batchResult.Errors)
+	}
+
+	if count := atomic.LoadInt32(&analyzed); count != 1 {
+		t.Errorf("Expected 1 analysis, got %d", count)
+	}
+	if history, _ := analysisStore.List("player-1", storage.ListQuery{}); len(history.Analyses) != 1 {
+		t.Errorf("Expected 1 stored analysis, got %d", len(history.Analyses))
+	}
+}
+
+// TestAnalyzeBatch_BoundedConcurrency tests that no more than the configured workers run at once
+func TestAnalyzeBatch_BoundedConcurrency(t *testing.T) {
+	var running int32
+	var maxRunning int32
+
+	mockService := &MockAnalysisService{
+		AnalyzePlayerFunc: func(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				observed := atomic.LoadInt32(&maxRunning)
+				if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return &models.AnalysisResult{}
+		},
+	}
+
+	handler := NewHandler(mockService, WithBatchWorkers(2))
+
+	entries := make([]string, 0, 8)
+	for index := 0; index < 8; index++ {
+		entries = append(entries, fmt.Sprintf(`{"summoner": {"puuid": "player-%d"}, "matches": []}`, index))
+	}
+
+	responseRecorder := postBatch(t, handler, `{"entries": [`+strings.Join(entries, ",")+`]}`)
+
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, responseRecorder.Code)
+	}
+
+	if maxRunning > 2 {
+		t.Errorf("Expected at most 2 concurrent analyses, got %d", maxRunning)
+	}
+
+	var batchResult models.BatchAnalysisResult
+	json.NewDecoder(responseRecorder.Body).Decode(&batchResult)
+	if len(batchResult.Results) != 8 {
+		t.Errorf("Expected 8 results, got %d", len(batchResult.Results))
+	}
+}
+
+// TestAnalyzeBatch_Limits tests empty and oversized batches
+func TestAnalyzeBatch_Limits(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{}, WithMaxBatchSize(1))
+
+	if code := postBatch(t, handler, `{"entries": []}`).Code; code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for an empty batch, got %d", http.StatusBadRequest, code)
+	}
+
+	oversized := `{"entries": [{"summoner": {"puuid": "a"}}, {"summoner": {"puuid": "b"}}]}`
+	if code := postBatch(t, handler, oversized).Code; code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status code %d for an oversized batch, got %d", http.StatusRequestEntityTooLarge, code)
+	}
+
+	if code := postBatch(t, handler, "invalid").Code; code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid JSON, got %d", http.StatusBadRequest, code)
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// ComparePlayers handles requests to put two or more players side by side
+func (handler *Handler) ComparePlayers(writer http.ResponseWriter, request *http.Request) {
+	var compareRequest struct {
+		Players []batchEntry `json:"players"`
+	}
+
+	if apiError := decodeBody(request, &compareRequest); apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	if len(compareRequest.Players) < 2 {
+		apierror.Write(writer, request, apierror.Validation("At least two players are required", apierror.FieldError{
+			Pointer: "/players",
+			Message: "must contain at least two players",
+		}))
+		return
+	}
+
+	if len(compareRequest.Players) > handler.maxBatchSize {
+		message := fmt.Sprintf("Comparison exceeds the maximum of %d players", handler.maxBatchSize)
+		apierror.Write(writer, request, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, message))
+		return
+	}
+
+	format := request.URL.Query().Get("format")
+	seenPUUIDs := make(map[string]bool)
+	entries := make([]models.ComparisonEntry, 0, len(compareRequest.Players))
+
+	for index, player := range compareRequest.Players {
+		pointerPrefix := fmt.Sprintf("/players/%d", index)
+
+		matches, apiError := handler.prepareAnalysis(player.Summoner, player.Matches, player.Options, format, pointerPrefix)
+		if apiError != nil {
+			apierror.Write(writer, request, apiError)
+			return
+		}
+
+		if seenPUUIDs[player.Summoner.PUUID] {
+			apierror.Write(writer, request, apierror.Validation("A unique summoner PUUID is required for every player", apierror.FieldError{
+				Pointer: pointerPrefix + "/summoner/puuid",
+				Message: fmt.Sprintf("duplicate PUUID %q", player.Summoner.PUUID),
+			}))
+			return
+		}
+		seenPUUIDs[player.Summoner.PUUID] = true
+
+		entries = append(entries, models.ComparisonEntry{Summoner: player.Summoner, Matches: matches, Options: player.Options})
+	}
+
+	comparisonResult := handler.analysisService.ComparePlayers(entries)
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(comparisonResult)
+}
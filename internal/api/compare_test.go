@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// postCompare sends a comparison request to the handler and returns the recorder
+func postCompare(t *testing.T, handler *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request, _ := http.NewRequest("POST", "/api/v1/compare", bytes.NewBufferString(body))
+	request.Header.Set("Content-Type", "application/json")
+
+	responseRecorder := httptest.NewRecorder()
+	handler.ComparePlayers(responseRecorder, request)
+	return responseRecorder
+}
+
+// TestComparePlayers_Success tests that players reach the service in request order
+func TestComparePlayers_Success(t *testing.T) {
+	var receivedEntries []models.ComparisonEntry
+	mockService := &MockAnalysisService{
+		ComparePlayersFunc: func(entries []models.ComparisonEntry) *models.ComparisonResult {
+			receivedEntries = entries
+			return &models.ComparisonResult{Summary: "ok"}
+		},
+	}
+
+	handler := NewHandler(mockService)
+
+	responseRecorder := postCompare(t, handler, `{
+		"players": [
+			{"summoner": {"puuid": "starter"}, "matches": [{"matchId": "m1", "gameDuration": 1800, "participants": [{"puuid": "starter"}]}]},
+			{"summoner": {"puuid": "sub"}, "matches": []}
+		]
+	}`)
+
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, responseRecorder.Code)
+	}
+
+	if len(receivedEntries) != 2 || receivedEntries[0].Summoner.PUUID != "starter" || len(receivedEntries[0].Matches) != 1 {
+		t.Errorf("Expected both entries in request order, got %+v", receivedEntries)
+	}
+}
+
+// TestComparePlayers_InvalidRequests tests rejected comparison requests
+func TestComparePlayers_InvalidRequests(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{})
+
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{"invalid JSON", "invalid"},
+		{"single player", `{"players": [{"summoner": {"puuid": "a"}}]}`},
+		{"missing summoner", `{"players": [{"summoner": {"puuid": "a"}}, {"matches": []}]}`},
+		{"duplicate PUUID", `{"players": [{"summoner": {"puuid": "a"}}, {"summoner": {"puuid": "a"}}]}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if code := postCompare(t, handler, testCase.body).Code; code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, code)
+			}
+		})
+	}
+}
+
+// TestComparePlayers_FieldPointers tests that field errors point into the offending player
+func TestComparePlayers_FieldPointers(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{})
+
+	responseRecorder := postCompare(t, handler, `{"players": [{"summoner": {"puuid": "a"}}, {"summoner": {"puuid": "b", "division": "V"}}]}`)
+
+	apiError := decodeAPIError(t, responseRecorder)
+	if len(apiError.Details) != 1 || apiError.Details[0].Pointer != "/players/1/summoner/division" {
+		t.Errorf("Expected field error at /players/1/summoner/division, got %+v", apiError)
+	}
+
+	if apiError.Code != apierror.CodeValidationFailed {
+		t.Errorf("Expected code '%s', got '%s'", apierror.CodeValidationFailed, apiError.Code)
+	}
+}
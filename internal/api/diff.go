@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
+)
+
+// diffSide selects one analysis of a diff: a stored analysis by ID or a result supplied in the request
+type diffSide struct {
+	ID     string                 `json:"id"`
+	Result *models.AnalysisResult `json:"result"`
+}
+
+// DiffAnalyses handles requests for the changes between two analyses of a player
+func (handler *Handler) DiffAnalyses(writer http.ResponseWriter, request *http.Request) {
+	var diffRequest struct {
+		PUUID  string   `json:"puuid"`
+		Base   diffSide `json:"base"`
+		Target diffSide `json:"target"`
+	}
+
+	if apiError := decodeBody(request, &diffRequest); apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	base, apiError := handler.resolveDiffSide(request.Context(), diffRequest.PUUID, diffRequest.Base, "/base")
+	if apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	target, apiError := handler.resolveDiffSide(request.Context(), diffRequest.PUUID, diffRequest.Target, "/target")
+	if apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	analysisDiff, err := handler.analysisService.DiffAnalyses(base, target)
+	if err != nil {
+		apierror.Write(writer, request, fieldValidationError("Analyses cannot be compared", err, ""))
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(analysisDiff)
+}
+
+// resolveDiffSide returns the analysis selected by one side of a diff request located at pointer
+func (handler *Handler) resolveDiffSide(ctx context.Context, puuid string, side diffSide, pointer string) (*models.AnalysisResult, *apierror.Error) {
+	switch {
+	case side.ID != "" && side.Result != nil:
+		return nil, apierror.Validation("Either an analysis ID or a result is required, not both", apierror.FieldError{
+			Pointer: pointer,
+			Message: "set either id or result",
+		})
+	case side.Result != nil && puuid != "" && side.Result.PlayerStats.PUUID != puuid:
+		return nil, apierror.Validation("The supplied analysis is of a different player", apierror.FieldError{
+			Pointer: pointer + "/result/playerStats/puuid",
+			Message: fmt.Sprintf("result is of player %q, not %q", side.Result.PlayerStats.PUUID, puuid),
+		})
+	case side.Result != nil:
+		return side.Result, nil
+	case side.ID == "":
+		return nil, apierror.Validation("An analysis ID or a result is required", apierror.FieldError{
+			Pointer: pointer,
+			Message: "set either id or result",
+		})
+	}
+
+	if handler.analysisStore == nil {
+		return nil, apierror.New(http.StatusNotImplemented, apierror.CodeHistoryDisabled, "Analysis history is not enabled")
+	}
+
+	if puuid == "" {
+		return nil, apierror.Validation("A PUUID is required to look up stored analyses", apierror.FieldError{
+			Pointer: "/puuid",
+			Message: "puuid is required when an analysis is selected by id",
+		})
+	}
+
+	_, span := tracing.Start(ctx, "storage.Get")
+	storedAnalysis, err := handler.analysisStore.Get(puuid, side.ID)
+	span.SetError(err)
+	span.End()
+
+	if errors.Is(err, storage.ErrNotFound) {
+		notFound := apierror.New(http.StatusNotFound, apierror.CodeAnalysisNotFound, "Stored analysis not found")
+		notFound.Details = []apierror.FieldError{{Pointer: pointer + "/id", Message: "no stored analysis with this id for the player"}}
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "Failed to load stored analysis")
+	}
+
+	return storedAnalysis.Result, nil
+}
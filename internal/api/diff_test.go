@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/services"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+)
+
+// postDiff sends a diff request to the handler and returns the recorder
+func postDiff(t *testing.T, handler *Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyses/diff", bytes.NewBufferString(body))
+	request.Header.Set("Content-Type", "application/json")
+
+	responseRecorder := httptest.NewRecorder()
+	handler.DiffAnalyses(responseRecorder, request)
+	return responseRecorder
+}
+
+// TestDiffAnalyses_StoredAndSupplied tests diffing a stored analysis against one supplied in the request
+func TestDiffAnalyses_StoredAndSupplied(t *testing.T) {
+	var receivedBase, receivedTarget *models.AnalysisResult
+	mockService := &MockAnalysisService{
+		DiffAnalysesFunc: func(base *models.AnalysisResult, target *models.AnalysisResult) (*models.AnalysisDiff, error) {
+			receivedBase, receivedTarget = base, target
+			return &models.AnalysisDiff{}, nil
+		},
+	}
+
+	analysisStore := storage.NewMemoryStore()
+	stored, _ := analysisStore.Save("test-puuid", &models.AnalysisResult{
+		PlayerStats: models.PlayerStats{PUUID: "test-puuid", TotalMatches: 10},
+		AnalyzedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	handler := NewHandler(mockService, WithAnalysisStore(analysisStore))
+
+	responseRecorder := postDiff(t, handler, `{
+		"puuid": "test-puuid",
+		"base": {"id": "`+stored.ID+`"},
+		"target": {"result": {"playerStats": {"puuid": "test-puuid", "totalMatches": 20}}}
+	}`)
+
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+	}
+
+	if receivedBase.PlayerStats.TotalMatches != 10 || receivedTarget.PlayerStats.TotalMatches != 20 {
+		t.Errorf("Expected the stored base and supplied target, got %+v and %+v", receivedBase.PlayerStats, receivedTarget.PlayerStats)
+	}
+}
+
+// TestDiffAnalyses_InvalidRequests tests rejected diff requests
+func TestDiffAnalyses_InvalidRequests(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{}, WithAnalysisStore(storage.NewMemoryStore()))
+
+	testCases := []struct {
+		name            string
+		body            string
+		expectedStatus  int
+		expectedCode    string
+		expectedPointer string
+	}{
+		{"missing base", `{"target": {"result": {}}}`, http.StatusBadRequest, apierror.CodeValidationFailed, "/base"},
+		{"both id and result", `{"puuid": "p", "base": {"result": {"playerStats": {"puuid": "p"}}}, "target": {"id": "x", "result": {}}}`, http.StatusBadRequest, apierror.CodeValidationFailed, "/target"},
+		{"id without puuid", `{"base": {"id": "abc"}, "target": {"result": {}}}`, http.StatusBadRequest, apierror.CodeValidationFailed, "/puuid"},
+		{"unknown id", `{"puuid": "p", "base": {"id": "abc"}, "target": {"result": {}}}`, http.StatusNotFound, apierror.CodeAnalysisNotFound, "/base/id"},
+		{"supplied result of another player", `{"puuid": "p", "base": {"result": {"playerStats": {"puuid": "q"}}}, "target": {"result": {"playerStats": {"puuid": "p"}}}}`, http.StatusBadRequest, apierror.CodeValidationFailed, "/base/result/playerStats/puuid"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			responseRecorder := postDiff(t, handler, testCase.body)
+
+			if responseRecorder.Code != testCase.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", testCase.expectedStatus, responseRecorder.Code)
+			}
+
+			apiError := decodeAPIError(t, responseRecorder)
+			if apiError.Code != testCase.expectedCode || len(apiError.Details) != 1 || apiError.Details[0].Pointer != testCase.expectedPointer {
+				t.Errorf("Expected %s at %s, got %+v", testCase.expectedCode, testCase.expectedPointer, apiError)
+			}
+		})
+	}
+}
+
+// TestDiffAnalyses_DifferentPlayers tests that analyses of two different players are not diffed
+func TestDiffAnalyses_DifferentPlayers(t *testing.T) {
+	handler := NewHandler(services.NewAnalysisService())
+
+	responseRecorder := postDiff(t, handler, `{
+		"base": {"result": {"playerStats": {"puuid": "player-1"}}},
+		"target": {"result": {"playerStats": {"puuid": "player-2"}}}
+	}`)
+
+	if responseRecorder.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, responseRecorder.Code)
+	}
+
+	apiError := decodeAPIError(t, responseRecorder)
+	if apiError.Code != apierror.CodeValidationFailed || len(apiError.Details) != 1 || apiError.Details[0].Pointer != "/target" {
+		t.Errorf("Expected a validation error at /target, got %+v", apiError)
+	}
+}
@@ -1,23 +1,143 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/auth"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/metrics"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/middleware"
 	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/riot"
 	"github.com/OPGLOL/opgl-cortex-engine-service/internal/services"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
+)
+
+// Default limits for batch analysis
+const (
+	defaultBatchWorkers = 4
+	defaultMaxBatchSize = 50
 )
 
 // Handler manages HTTP request handlers for the cortex engine
 type Handler struct {
 	analysisService services.AnalysisServiceInterface
+	// Number of entries of a batch analyzed concurrently
+	batchWorkers int
+	// Maximum number of entries accepted in a single batch
+	maxBatchSize int
+	// Validation mode used when a request does not set options.validationMode
+	validationMode string
+	// History of analysis results; nil disables persistence and the history endpoint
+	analysisStore storage.AnalysisStore
+	// Stored match histories with running aggregates; nil disables the ingestion endpoints
+	matchIngestor services.MatchIngestorInterface
+	// Metrics exposed on /metrics; nil leaves the endpoint unregistered
+	serviceMetrics *metrics.ServiceMetrics
+	// API keys required by every route except /health and /metrics; nil leaves the routes open
+	keyStore *auth.KeyStore
+	// Per-client token buckets for analyze-scope and batch routes; nil leaves the routes unlimited
+	analyzeLimiter *middleware.RateLimiter
+	batchLimiter   *middleware.RateLimiter
+	// Daily per-client cap on submitted matches; nil disables the quota
+	matchQuota *middleware.MatchQuota
+}
+
+// HandlerOption configures optional Handler settings
+type HandlerOption func(handler *Handler)
+
+// WithBatchWorkers sets how many batch entries are analyzed concurrently
+func WithBatchWorkers(workers int) HandlerOption {
+	return func(handler *Handler) {
+		if workers > 0 {
+			handler.batchWorkers = workers
+		}
+	}
+}
+
+// WithMaxBatchSize sets the maximum number of entries accepted in a single batch
+func WithMaxBatchSize(maxBatchSize int) HandlerOption {
+	return func(handler *Handler) {
+		if maxBatchSize > 0 {
+			handler.maxBatchSize = maxBatchSize
+		}
+	}
+}
+
+// WithValidationMode sets the default validation mode ("strict" or "lenient"); unknown modes are ignored
+func WithValidationMode(mode string) HandlerOption {
+	return func(handler *Handler) {
+		if normalizedMode := services.NormalizeValidationMode(mode); normalizedMode != "" {
+			handler.validationMode = normalizedMode
+		}
+	}
+}
+
+// WithAnalysisStore persists every analysis result in store and enables the history endpoint
+func WithAnalysisStore(store storage.AnalysisStore) HandlerOption {
+	return func(handler *Handler) {
+		handler.analysisStore = store
+	}
+}
+
+// WithMatchIngestor enables the match ingestion, all-time stats and stored-match analysis endpoints
+func WithMatchIngestor(matchIngestor services.MatchIngestorInterface) HandlerOption {
+	return func(handler *Handler) {
+		handler.matchIngestor = matchIngestor
+	}
+}
+
+// WithMetrics exposes serviceMetrics on the /metrics endpoint
+func WithMetrics(serviceMetrics *metrics.ServiceMetrics) HandlerOption {
+	return func(handler *Handler) {
+		handler.serviceMetrics = serviceMetrics
+	}
+}
+
+// WithAPIKeys requires an API key with the route's scope on every route except /health and /metrics
+func WithAPIKeys(keyStore *auth.KeyStore) HandlerOption {
+	return func(handler *Handler) {
+		handler.keyStore = keyStore
+	}
+}
+
+// WithRateLimits limits analyze-scope routes and the batch route with separate per-client token buckets
+// Either limiter may be nil to leave its routes unlimited
+func WithRateLimits(analyzeLimiter *middleware.RateLimiter, batchLimiter *middleware.RateLimiter) HandlerOption {
+	return func(handler *Handler) {
+		handler.analyzeLimiter = analyzeLimiter
+		handler.batchLimiter = batchLimiter
+	}
+}
+
+// WithMatchQuota caps the matches each client may submit per day on analyze-scope and batch routes
+func WithMatchQuota(matchQuota *middleware.MatchQuota) HandlerOption {
+	return func(handler *Handler) {
+		handler.matchQuota = matchQuota
+	}
 }
 
 // NewHandler creates a new Handler instance
-func NewHandler(analysisService services.AnalysisServiceInterface) *Handler {
-	return &Handler{
+func NewHandler(analysisService services.AnalysisServiceInterface, options ...HandlerOption) *Handler {
+	handler := &Handler{
 		analysisService: analysisService,
+		batchWorkers:    defaultBatchWorkers,
+		maxBatchSize:    defaultMaxBatchSize,
+		validationMode:  services.ValidationLenient,
+	}
+
+	for _, option := range options {
+		option(handler)
 	}
+
+	return handler
 }
 
 // HealthCheck handles health check requests
@@ -33,22 +153,187 @@ func (handler *Handler) HealthCheck(writer http.ResponseWriter, request *http.Re
 // AnalyzePlayer handles player analysis requests
 func (handler *Handler) AnalyzePlayer(writer http.ResponseWriter, request *http.Request) {
 	var analyzeRequest struct {
-		Summoner *models.Summoner `json:"summoner"`
-		Matches  []models.Match   `json:"matches"`
+		Summoner *models.Summoner       `json:"summoner"`
+		Matches  json.RawMessage        `json:"matches"`
+		Options  models.AnalysisOptions `json:"options"`
 	}
 
-	if err := json.NewDecoder(request.Body).Decode(&analyzeRequest); err != nil {
-		http.Error(writer, "Invalid request body", http.StatusBadRequest)
+	if apiError := decodeBody(request, &analyzeRequest); apiError != nil {
+		apierror.Write(writer, request, apiError)
 		return
 	}
 
-	if analyzeRequest.Summoner == nil {
-		http.Error(writer, "Summoner data is required", http.StatusBadRequest)
+	matches, apiError := handler.prepareAnalysis(analyzeRequest.Summoner, analyzeRequest.Matches, analyzeRequest.Options, request.URL.Query().Get("format"), "")
+	if apiError != nil {
+		apierror.Write(writer, request, apiError)
 		return
 	}
 
-	analysisResult := handler.analysisService.AnalyzePlayer(analyzeRequest.Summoner, analyzeRequest.Matches)
+	analysisResult := handler.analysisService.AnalyzePlayerWithOptions(request.Context(), analyzeRequest.Summoner, matches, analyzeRequest.Options)
+	if analysisResult != nil {
+		handler.storeAnalysis(request.Context(), analyzeRequest.Summoner.PUUID, analysisResult)
+	}
 
 	writer.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(writer).Encode(analysisResult)
 }
+
+// decodeBody decodes a JSON request body into target
+// Type mismatches are reported with the JSON pointer of the offending field
+func decodeBody(request *http.Request, target interface{}) *apierror.Error {
+	_, span := tracing.Start(request.Context(), "decodeBody")
+	defer span.End()
+
+	err := json.NewDecoder(request.Body).Decode(target)
+	if err == nil {
+		return nil
+	}
+	span.SetError(err)
+
+	invalidBody := apierror.New(http.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body")
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		invalidBody.Details = []apierror.FieldError{{
+			Pointer: "/" + strings.ReplaceAll(typeError.Field, ".", "/"),
+			Message: fmt.Sprintf("expected %s, got %s", typeError.Type, typeError.Value),
+		}}
+	}
+
+	return invalidBody
+}
+
+// storeAnalysis saves an analysis result in the history when a store is configured
+// A failed save is logged without failing the request
+func (handler *Handler) storeAnalysis(ctx context.Context, puuid string, analysisResult *models.AnalysisResult) {
+	if handler.analysisStore == nil {
+		return
+	}
+
+	_, span := tracing.Start(ctx, "storage.Save")
+	defer span.End()
+
+	if _, err := handler.analysisStore.Save(puuid, analysisResult); err != nil {
+		span.SetError(err)
+		requestctx.Logger(ctx).Error().Err(err).Str("puuid", puuid).Msg("Failed to store analysis")
+	}
+}
+
+// prepareAnalysis validates the summoner and options of an analysis and decodes and validates its matches
+// pointerPrefix locates the analysis within the request body (e.g., /players/1) for field errors
+// Only strict validation rejects matches here; in lenient mode the analysis service drops invalid
+// matches itself and reports them as warnings
+func (handler *Handler) prepareAnalysis(summoner *models.Summoner, rawMatches json.RawMessage, options models.AnalysisOptions, format string, pointerPrefix string) ([]models.Match, *apierror.Error) {
+	if summoner == nil {
+		return nil, apierror.Validation("Summoner data is required", apierror.FieldError{
+			Pointer: pointerPrefix + "/summoner",
+			Message: "summoner is required",
+		})
+	}
+
+	validationMode, apiError := handler.resolveValidationMode(options, pointerPrefix)
+	if apiError != nil {
+		return nil, apiError
+	}
+
+	matches, err := decodeMatches(rawMatches, format)
+	if err != nil {
+		return nil, apierror.Validation("Invalid matches: "+err.Error(), apierror.FieldError{
+			Pointer: pointerPrefix + "/matches",
+			Message: err.Error(),
+		})
+	}
+
+	if err := services.ValidateBenchmarkSelection(summoner, options); err != nil {
+		return nil, fieldValidationError("Invalid benchmark selection", err, pointerPrefix)
+	}
+
+	if err := services.ValidateFilters(options.Filters); err != nil {
+		return nil, fieldValidationError("Invalid filters", err, pointerPrefix)
+	}
+
+	if _, _, err := services.ValidateMatches(summoner, matches, validationMode); err != nil {
+		return nil, fieldValidationError("Invalid matches", err, pointerPrefix)
+	}
+
+	return matches, nil
+}
+
+// resolveValidationMode returns the validation mode requested in options, or the handler's default
+func (handler *Handler) resolveValidationMode(options models.AnalysisOptions, pointerPrefix string) (string, *apierror.Error) {
+	if options.ValidationMode == "" {
+		return handler.validationMode, nil
+	}
+
+	validationMode := services.NormalizeValidationMode(options.ValidationMode)
+	if validationMode == "" {
+		return "", apierror.Validation("Invalid validation mode", apierror.FieldError{
+			Pointer: pointerPrefix + "/options/validationMode",
+			Message: fmt.Sprintf("unknown validation mode %q", options.ValidationMode),
+		})
+	}
+	return validationMode, nil
+}
+
+// fieldValidationError converts a service validation error into a validation Error
+func fieldValidationError(summary string, err error, pointerPrefix string) *apierror.Error {
+	validationError := apierror.Validation(summary + ": " + err.Error())
+
+	var fieldErrors services.ValidationErrors
+	var fieldError *services.FieldError
+	switch {
+	case errors.As(err, &fieldErrors):
+	case errors.As(err, &fieldError):
+		fieldErrors = services.ValidationErrors{fieldError}
+	}
+
+	for _, fieldError := range fieldErrors {
+		validationError.Details = append(validationError.Details, apierror.FieldError{
+			Pointer: pointerPrefix + fieldError.Pointer,
+			Message: fieldError.Message,
+		})
+	}
+
+	return validationError
+}
+
+// decodeMatches decodes the matches of an analyze request in the requested format
+// The default format is models.Match; "riot-v5" accepts raw Riot match-v5 DTOs
+func decodeMatches(rawMatches json.RawMessage, format string) ([]models.Match, error) {
+	if len(rawMatches) == 0 || string(rawMatches) == "null" {
+		return nil, nil
+	}
+
+	switch format {
+	case "":
+		var matches []models.Match
+		if err := json.Unmarshal(rawMatches, &matches); err != nil {
+			return nil, err
+		}
+		return matches, nil
+	case riot.FormatRiotV5:
+		var matchDTOs []riot.MatchDTO
+		if err := json.Unmarshal(rawMatches, &matchDTOs); err != nil {
+			return nil, err
+		}
+		return riot.ConvertMatches(matchDTOs)
+	default:
+		return nil, fmt.Errorf("unsupported match format %q", format)
+	}
+}
+
+// ReloadRules handles requests to hot-reload the improvement rule file
+func (handler *Handler) ReloadRules(writer http.ResponseWriter, request *http.Request) {
+	version, err := handler.analysisService.ReloadRules()
+	if err != nil {
+		apierror.Write(writer, request, apierror.New(http.StatusUnprocessableEntity, apierror.CodeRuleReloadFailed, "Failed to reload rules: "+err.Error()))
+		return
+	}
+
+	response := map[string]string{
+		"status":  "reloaded",
+		"version": version,
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(response)
+}
@@ -2,18 +2,27 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
 	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/services"
 )
 
 // MockAnalysisService is a mock implementation of AnalysisServiceInterface for testing
 type MockAnalysisService struct {
-	AnalyzePlayerFunc func(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult
+	AnalyzePlayerFunc            func(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult
+	AnalyzePlayerWithOptionsFunc func(ctx context.Context, summoner *models.Summoner, matches []models.Match, options models.AnalysisOptions) *models.AnalysisResult
+	ComparePlayersFunc           func(entries []models.ComparisonEntry) *models.ComparisonResult
+	DiffAnalysesFunc             func(base *models.AnalysisResult, target *models.AnalysisResult) (*models.AnalysisDiff, error)
+	ReloadRulesFunc              func() (string, error)
 }
 
 func (m *MockAnalysisService) AnalyzePlayer(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult {
@@ -23,6 +32,46 @@ func (m *MockAnalysisService) AnalyzePlayer(summoner *models.Summoner, matches [
 	return nil
 }
 
+// AnalyzePlayerWithOptions falls back to AnalyzePlayerFunc so tests that ignore options keep working
+func (m *MockAnalysisService) AnalyzePlayerWithOptions(ctx context.Context, summoner *models.Summoner, matches []models.Match, options models.AnalysisOptions) *models.AnalysisResult {
+	if m.AnalyzePlayerWithOptionsFunc != nil {
+		return m.AnalyzePlayerWithOptionsFunc(ctx, summoner, matches, options)
+	}
+	return m.AnalyzePlayer(summoner, matches)
+}
+
+func (m *MockAnalysisService) ComparePlayers(entries []models.ComparisonEntry) *models.ComparisonResult {
+	if m.ComparePlayersFunc != nil {
+		return m.ComparePlayersFunc(entries)
+	}
+	return &models.ComparisonResult{}
+}
+
+func (m *MockAnalysisService) DiffAnalyses(base *models.AnalysisResult, target *models.AnalysisResult) (*models.AnalysisDiff, error) {
+	if m.DiffAnalysesFunc != nil {
+		return m.DiffAnalysesFunc(base, target)
+	}
+	return &models.AnalysisDiff{}, nil
+}
+
+func (m *MockAnalysisService) ReloadRules() (string, error) {
+	if m.ReloadRulesFunc != nil {
+		return m.ReloadRulesFunc()
+	}
+	return "", nil
+}
+
+// decodeAPIError decodes the JSON error envelope of a failed response
+func decodeAPIError(t *testing.T, responseRecorder *httptest.ResponseRecorder) apierror.Error {
+	t.Helper()
+
+	var apiError apierror.Error
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode error envelope: %v", err)
+	}
+	return apiError
+}
+
 // TestNewHandler tests the NewHandler constructor
 func TestNewHandler(t *testing.T) {
 	mockService := &MockAnalysisService{}
@@ -282,3 +331,333 @@ func TestAnalyzePlayer_ContentType(t *testing.T) {
 		t.Errorf("Expected Content-Type 'application/json', got '%s'", contentType)
 	}
 }
+
+// TestAnalyzePlayer_PassesOptions tests that request options reach the analysis service
+func TestAnalyzePlayer_PassesOptions(t *testing.T) {
+	var receivedOptions models.AnalysisOptions
+	mockService := &MockAnalysisService{
+		AnalyzePlayerWithOptionsFunc: func(ctx context.Context, summoner *models.Summoner, matches []models.Match, options models.AnalysisOptions) *models.AnalysisResult {
+			receivedOptions = options
+			return &models.AnalysisResult{}
+		},
+	}
+
+	handler := NewHandler(mockService)
+
+	requestBody := map[string]interface{}{
+		"summoner": map[string]interface{}{
+			"puuid":    "test-puuid",
+			"tier":     "GOLD",
+			"division": "II",
+		},
+		"matches": []map[string]interface{}{},
+		"options": map[string]interface{}{
+			"goal": "climb",
+		},
+	}
+	bodyBytes, _ := json.Marshal(requestBody)
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyze", bytes.NewBuffer(bodyBytes))
+	request.Header.Set("Content-Type", "application/json")
+
+	responseRecorder := httptest.NewRecorder()
+	handler.AnalyzePlayer(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, responseRecorder.Code)
+	}
+
+	if receivedOptions.Goal != "climb" {
+		t.Errorf("Expected goal 'climb', got '%s'", receivedOptions.Goal)
+	}
+}
+
+// TestAnalyzePlayer_PassesRequestContext tests that the request context, with its request ID, reaches the service
+func TestAnalyzePlayer_PassesRequestContext(t *testing.T) {
+	var receivedRequestID string
+	mockService := &MockAnalysisService{
+		AnalyzePlayerWithOptionsFunc: func(ctx context.Context, summoner *models.Summoner, matches []models.Match, options models.AnalysisOptions) *models.AnalysisResult {
+			receivedRequestID = requestctx.RequestID(ctx)
+			return &models.AnalysisResult{}
+		},
+	}
+
+	handler := NewHandler(mockService)
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyze", bytes.NewBufferString(`{"summoner": {"puuid": "test-puuid"}, "matches": []}`))
+	request = request.WithContext(requestctx.WithRequestID(request.Context(), "request-1"))
+
+	handler.AnalyzePlayer(httptest.NewRecorder(), request)
+
+	if receivedRequestID != "request-1" {
+		t.Errorf("Expected request ID 'request-1' in the service context, got '%s'", receivedRequestID)
+	}
+}
+
+// TestAnalyzePlayer_UnknownTier tests that an unknown tier is rejected
+func TestAnalyzePlayer_UnknownTier(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{})
+
+	requestBody := map[string]interface{}{
+		"summoner": map[string]interface{}{
+			"puuid": "test-puuid",
+			"tier":  "WOOD",
+		},
+		"matches": []map[string]interface{}{},
+	}
+	bodyBytes, _ := json.Marshal(requestBody)
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyze", bytes.NewBuffer(bodyBytes))
+	request.Header.Set("Content-Type", "application/json")
+
+	responseRecorder := httptest.NewRecorder()
+	handler.AnalyzePlayer(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, responseRecorder.Code)
+	}
+}
+
+// TestAnalyzePlayer_RiotV5Format tests that raw match-v5 payloads are converted before analysis
+func TestAnalyzePlayer_RiotV5Format(t *testing.T) {
+	var receivedMatches []models.Match
+	mockService := &MockAnalysisService{
+		AnalyzePlayerFunc: func(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult {
+			receivedMatches = matches
+			return &models.AnalysisResult{}
+		},
+	}
+
+	handler := NewHandler(mockService)
+
+	requestBody := `{
+		"summoner": {"puuid": "test-puuid"},
+		"matches": [{
+			"metadata": {"matchId": "EUW1_1"},
+			"info": {
+				"gameCreation": 1700000000000,
+				"gameDuration": 1800000,
+				"participants": [{"puuid": "test-puuid", "riotIdGameName": "Tester", "championName": "Ahri", "teamPosition": "MIDDLE", "teamId": 100}]
+			}
+		}]
+	}`
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyze?format=riot-v5", bytes.NewBufferString(requestBody))
+	request.Header.Set("Content-Type", "application/json")
+
+	responseRecorder := httptest.NewRecorder()
+	handler.AnalyzePlayer(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+	}
+
+	if len(receivedMatches) != 1 {
+		t.Fatalf("Expected 1 converted match, got %d", len(receivedMatches))
+	}
+
+	if receivedMatches[0].GameDuration != 1800 || receivedMatches[0].Participants[0].SummonerName != "Tester" {
+		t.Errorf("Expected converted match, got %+v", receivedMatches[0])
+	}
+}
+
+// TestAnalyzePlayer_UnsupportedFormat tests that an unknown match format is rejected
+func TestAnalyzePlayer_UnsupportedFormat(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{})
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyze?format=riot-v4", bytes.NewBufferString(`{"summoner": {"puuid": "test-puuid"}, "matches": []}`))
+	request.Header.Set("Content-Type", "application/json")
+
+	responseRecorder := httptest.NewRecorder()
+	handler.AnalyzePlayer(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, responseRecorder.Code)
+	}
+}
+
+// TestAnalyzePlayer_InvalidFilters tests that filters which can never match are rejected
+func TestAnalyzePlayer_InvalidFilters(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{})
+
+	testCases := []struct {
+		name            string
+		filters         string
+		expectedPointer string
+	}{
+		{"unknown role", `{"roles": ["MID", "SWEEPER"]}`, "/options/filters/roles/1"},
+		{"from after to", `{"from": "2024-02-01T00:00:00Z", "to": "2024-01-01T00:00:00Z"}`, "/options/filters/from"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			body := `{"summoner": {"puuid": "test-puuid"}, "matches": [], "options": {"filters": ` + testCase.filters + `}}`
+			request, _ := http.NewRequest("POST", "/api/v1/analyze", bytes.NewBufferString(body))
+			request.Header.Set("Content-Type", "application/json")
+
+			responseRecorder := httptest.NewRecorder()
+			handler.AnalyzePlayer(responseRecorder, request)
+
+			if responseRecorder.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, responseRecorder.Code)
+			}
+
+			apiError := decodeAPIError(t, responseRecorder)
+			if apiError.Code != apierror.CodeValidationFailed || len(apiError.Details) != 1 || apiError.Details[0].Pointer != testCase.expectedPointer {
+				t.Errorf("Expected validation failure at %s, got %+v", testCase.expectedPointer, apiError)
+			}
+		})
+	}
+}
+
+// TestAnalyzePlayer_ErrorEnvelope tests the error codes and field pointers of rejected requests
+func TestAnalyzePlayer_ErrorEnvelope(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{})
+
+	testCases := []struct {
+		name            string
+		body            string
+		expectedCode    string
+		expectedPointer string
+	}{
+		{"malformed JSON", "invalid", apierror.CodeInvalidBody, ""},
+		{"wrong field type", `{"summoner": {"puuid": "test-puuid", "summonerLevel": "high"}}`, apierror.CodeInvalidBody, "/summoner/summonerLevel"},
+		{"missing summoner", `{"matches": []}`, apierror.CodeValidationFailed, "/summoner"},
+		{"unknown tier", `{"summoner": {"puuid": "test-puuid", "tier": "WOOD"}}`, apierror.CodeValidationFailed, "/summoner/tier"},
+		{"unknown goal", `{"summoner": {"puuid": "test-puuid"}, "options": {"goal": "smurf"}}`, apierror.CodeValidationFailed, "/options/goal"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, _ := http.NewRequest("POST", "/api/v1/analyze", bytes.NewBufferString(testCase.body))
+			request.Header.Set("Content-Type", "application/json")
+
+			responseRecorder := httptest.NewRecorder()
+			handler.AnalyzePlayer(responseRecorder, request)
+
+			if responseRecorder.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, responseRecorder.Code)
+			}
+
+			apiError := decodeAPIError(t, responseRecorder)
+			if apiError.Code != testCase.expectedCode || apiError.Message == "" || apiError.RequestID == "" {
+				t.Errorf("Expected code '%s' with a message and request ID, got %+v", testCase.expectedCode, apiError)
+			}
+
+			if testCase.expectedPointer == "" {
+				if len(apiError.Details) != 0 {
+					t.Errorf("Expected no field details, got %+v", apiError.Details)
+				}
+				return
+			}
+
+			if len(apiError.Details) != 1 || apiError.Details[0].Pointer != testCase.expectedPointer {
+				t.Errorf("Expected field error at %s, got %+v", testCase.expectedPointer, apiError.Details)
+			}
+		})
+	}
+}
+
+// TestAnalyzePlayer_ValidationModes tests strict rejection, lenient warnings and the handler default
+func TestAnalyzePlayer_ValidationModes(t *testing.T) {
+	var receivedMatches []models.Match
+	mockService := &MockAnalysisService{
+		AnalyzePlayerFunc: func(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult {
+			receivedMatches = matches
+			return &models.AnalysisResult{}
+		},
+	}
+
+	matches := `[
+		{"matchId": "m1", "gameDuration": 1800, "participants": [{"puuid": "test-puuid"}]},
+		{"matchId": "m2", "gameDuration": -1, "participants": [{"puuid": "test-puuid"}]}
+	]`
+
+	postAnalyze := func(handler *Handler, options string) *httptest.ResponseRecorder {
+		body := `{"summoner": {"puuid": "test-puuid"}, "matches": ` + matches + `, "options": ` + options + `}`
+		request, _ := http.NewRequest("POST", "/api/v1/analyze", bytes.NewBufferString(body))
+		request.Header.Set("Content-Type", "application/json")
+
+		responseRecorder := httptest.NewRecorder()
+		handler.AnalyzePlayer(responseRecorder, request)
+		return responseRecorder
+	}
+
+	// Lenient is the default: the request reaches the analysis service, which drops and reports the invalid match
+	responseRecorder := postAnalyze(NewHandler(mockService), `{}`)
+	if responseRecorder.Code != http.StatusOK || len(receivedMatches) != 2 {
+		t.Fatalf("Expected both matches to reach the service, got %d with %d matches", responseRecorder.Code, len(receivedMatches))
+	}
+
+	responseRecorder = postAnalyze(NewHandler(services.NewAnalysisService()), `{}`)
+	var analysisResult models.AnalysisResult
+	json.NewDecoder(responseRecorder.Body).Decode(&analysisResult)
+	if analysisResult.PlayerStats.TotalMatches != 1 || len(analysisResult.Warnings) != 1 || analysisResult.Warnings[0].Pointer != "/matches/1" {
+		t.Errorf("Expected 1 match analyzed and a warning for /matches/1, got %d matches and %+v", analysisResult.PlayerStats.TotalMatches, analysisResult.Warnings)
+	}
+
+	// The request option overrides the handler default
+	responseRecorder = postAnalyze(NewHandler(mockService), `{"validationMode": "strict"}`)
+	apiError := decodeAPIError(t, responseRecorder)
+	if responseRecorder.Code != http.StatusBadRequest || len(apiError.Details) != 1 || apiError.Details[0].Pointer != "/matches/1/gameDuration" {
+		t.Errorf("Expected strict mode to reject /matches/1/gameDuration, got %d %+v", responseRecorder.Code, apiError)
+	}
+
+	// The handler default applies when the request sets no mode
+	responseRecorder = postAnalyze(NewHandler(mockService, WithValidationMode("strict")), `{}`)
+	if responseRecorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected the strict handler default to reject the request, got %d", responseRecorder.Code)
+	}
+
+	responseRecorder = postAnalyze(NewHandler(mockService), `{"validationMode": "paranoid"}`)
+	if apiError := decodeAPIError(t, responseRecorder); len(apiError.Details) != 1 || apiError.Details[0].Pointer != "/options/validationMode" {
+		t.Errorf("Expected unknown validation mode to be rejected, got %+v", apiError)
+	}
+}
+
+// TestReloadRules_Success tests a successful rule reload
+func TestReloadRules_Success(t *testing.T) {
+	mockService := &MockAnalysisService{
+		ReloadRulesFunc: func() (string, error) {
+			return "2025.01", nil
+		},
+	}
+
+	handler := NewHandler(mockService)
+
+	request, _ := http.NewRequest("POST", "/api/v1/admin/rules/reload", nil)
+	responseRecorder := httptest.NewRecorder()
+	handler.ReloadRules(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, responseRecorder.Code)
+	}
+
+	var response map[string]string
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response["version"] != "2025.01" {
+		t.Errorf("Expected version '2025.01', got '%s'", response["version"])
+	}
+}
+
+// TestReloadRules_Failure tests that a failed reload is reported
+func TestReloadRules_Failure(t *testing.T) {
+	mockService := &MockAnalysisService{
+		ReloadRulesFunc: func() (string, error) {
+			return "", errors.New("invalid rule configuration: version is required")
+		},
+	}
+
+	handler := NewHandler(mockService)
+
+	request, _ := http.NewRequest("POST", "/api/v1/admin/rules/reload", nil)
+	responseRecorder := httptest.NewRecorder()
+	handler.ReloadRules(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnprocessableEntity, responseRecorder.Code)
+	}
+}
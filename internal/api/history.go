@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
+	"github.com/gorilla/mux"
+)
+
+// ListAnalyses handles requests to browse a player's stored analyses, newest first
+// Query parameters: from and to (RFC 3339, inclusive), limit, and cursor (nextCursor of the previous page)
+func (handler *Handler) ListAnalyses(writer http.ResponseWriter, request *http.Request) {
+	if handler.analysisStore == nil {
+		apierror.Write(writer, request, apierror.New(http.StatusNotImplemented, apierror.CodeHistoryDisabled, "Analysis history is not enabled"))
+		return
+	}
+
+	query, apiError := parseListQuery(request)
+	if apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	_, span := tracing.Start(request.Context(), "storage.List")
+	history, err := handler.analysisStore.List(mux.Vars(request)["puuid"], query)
+	span.SetError(err)
+	span.End()
+
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		apierror.Write(writer, request, apierror.Validation("Invalid cursor", apierror.FieldError{
+			Parameter: "cursor",
+			Message:   "cursor must be the nextCursor of a previous page",
+		}))
+		return
+	}
+	if err != nil {
+		apierror.Write(writer, request, apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "Failed to list analyses"))
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(history)
+}
+
+// parseListQuery reads the time range and pagination parameters of a history request
+func parseListQuery(request *http.Request) (storage.ListQuery, *apierror.Error) {
+	parameters := request.URL.Query()
+	var query storage.ListQuery
+	var details []apierror.FieldError
+
+	for _, name := range []string{"from", "to"} {
+		value := parameters.Get(name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			details = append(details, apierror.FieldError{Parameter: name, Message: "must be an RFC 3339 timestamp"})
+			continue
+		}
+
+		if name == "from" {
+			query.From = &parsed
+		} else {
+			query.To = &parsed
+		}
+	}
+
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		details = append(details, apierror.FieldError{Parameter: "from", Message: "must not be after to"})
+	}
+
+	if value := parameters.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > storage.MaxPageSize {
+			details = append(details, apierror.FieldError{Parameter: "limit", Message: "must be between 1 and " + strconv.Itoa(storage.MaxPageSize)})
+		}
+		query.Limit = limit
+	}
+
+	query.Cursor = parameters.Get("cursor")
+
+	if len(details) > 0 {
+		return storage.ListQuery{}, apierror.Validation("Invalid query parameters", details...)
+	}
+
+	return query, nil
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+)
+
+// getHistory sends a history request through the router and returns the recorder
+func getHistory(t *testing.T, handler *Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request, _ := http.NewRequest("GET", path, nil)
+	responseRecorder := httptest.NewRecorder()
+	SetupRouter(handler).ServeHTTP(responseRecorder, request)
+	return responseRecorder
+}
+
+// TestListAnalyses_PersistsAnalyses tests that analyze and batch results are stored and listed
+func TestListAnalyses_PersistsAnalyses(t *testing.T) {
+	analyzedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	mockService := &MockAnalysisService{
+		AnalyzePlayerFunc: func(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult {
+			analyzedAt = analyzedAt.Add(time.Hour)
+			return &models.AnalysisResult{PlayerStats: models.PlayerStats{PUUID: summoner.PUUID}, AnalyzedAt: analyzedAt}
+		},
+	}
+
+	handler := NewHandler(mockService, WithAnalysisStore(storage.NewMemoryStore()))
+
+	for index := 0; index < 2; index++ {
+		request, _ := http.NewRequest("POST", "/api/v1/analyze", bytes.NewBufferString(`{"summoner": {"puuid": "test-puuid"}, "matches": []}`))
+		handler.AnalyzePlayer(httptest.NewRecorder(), request)
+	}
+	postBatch(t, handler, `{"entries": [{"summoner": {"puuid": "test-puuid"}, "matches": []}]}`)
+
+	responseRecorder := getHistory(t, handler, "/api/v1/players/test-puuid/analyses?limit=2")
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+	}
+
+	var history models.AnalysisHistory
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&history); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(history.Analyses) != 2 || history.NextCursor == "" {
+		t.Fatalf("Expected a full first page with a cursor, got %+v", history)
+	}
+
+	// The batch analysis ran last, so it is listed first
+	if !history.Analyses[0].AnalyzedAt.Equal(analyzedAt) {
+		t.Errorf("Expected newest analysis first, got %s", history.Analyses[0].AnalyzedAt)
+	}
+
+	responseRecorder = getHistory(t, handler, "/api/v1/players/test-puuid/analyses?limit=2&cursor="+history.NextCursor)
+	var lastPage models.AnalysisHistory
+	json.NewDecoder(responseRecorder.Body).Decode(&lastPage)
+	if len(lastPage.Analyses) != 1 || lastPage.NextCursor != "" {
+		t.Errorf("Expected the last analysis on the second page, got %+v", lastPage)
+	}
+}
+
+// TestListAnalyses_InvalidQuery tests rejected query parameters
+func TestListAnalyses_InvalidQuery(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{}, WithAnalysisStore(storage.NewMemoryStore()))
+
+	testCases := []struct {
+		name              string
+		query             string
+		expectedParameter string
+	}{
+		{"malformed from", "from=yesterday", "from"},
+		{"from after to", "from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z", "from"},
+		{"limit too large", "limit=1000", "limit"},
+		{"malformed cursor", "cursor=abc", "cursor"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			responseRecorder := getHistory(t, handler, "/api/v1/players/test-puuid/analyses?"+testCase.query)
+
+			if responseRecorder.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, responseRecorder.Code)
+			}
+
+			apiError := decodeAPIError(t, responseRecorder)
+			if len(apiError.Details) != 1 || apiError.Details[0].Parameter != testCase.expectedParameter {
+				t.Errorf("Expected a failure for parameter '%s', got %+v", testCase.expectedParameter, apiError)
+			}
+		})
+	}
+}
+
+// TestListAnalyses_Disabled tests the response when no store is configured
+func TestListAnalyses_Disabled(t *testing.T) {
+	responseRecorder := getHistory(t, NewHandler(&MockAnalysisService{}), "/api/v1/players/test-puuid/analyses")
+
+	if responseRecorder.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotImplemented, responseRecorder.Code)
+	}
+
+	if apiError := decodeAPIError(t, responseRecorder); apiError.Code != apierror.CodeHistoryDisabled {
+		t.Errorf("Expected code '%s', got '%s'", apierror.CodeHistoryDisabled, apiError.Code)
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/services"
+	"github.com/gorilla/mux"
+)
+
+// IngestMatches handles requests to append new matches to a player's stored match history
+// Every match needs a matchId and gameCreation; matches already stored are counted as duplicates and ignored
+func (handler *Handler) IngestMatches(writer http.ResponseWriter, request *http.Request) {
+	if handler.matchIngestor == nil {
+		apierror.Write(writer, request, ingestionDisabled())
+		return
+	}
+
+	var ingestRequest struct {
+		Summoner *models.Summoner       `json:"summoner"`
+		Matches  json.RawMessage        `json:"matches"`
+		Options  models.AnalysisOptions `json:"options"`
+	}
+
+	if apiError := decodeBody(request, &ingestRequest); apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	summoner, apiError := ingestionSummoner(ingestRequest.Summoner, mux.Vars(request)["puuid"])
+	if apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	validationMode, apiError := handler.resolveValidationMode(ingestRequest.Options, "")
+	if apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	matches, err := decodeMatches(ingestRequest.Matches, request.URL.Query().Get("format"))
+	if err != nil {
+		apierror.Write(writer, request, apierror.Validation("Invalid matches: "+err.Error(), apierror.FieldError{
+			Pointer: "/matches",
+			Message: err.Error(),
+		}))
+		return
+	}
+
+	if err := services.ValidateIngestedMatches(matches); err != nil {
+		apierror.Write(writer, request, fieldValidationError("Invalid matches", err, ""))
+		return
+	}
+
+	// In lenient mode the ingestor drops invalid matches itself and reports them as warnings
+	if _, _, err := services.ValidateMatches(summoner, matches, validationMode); err != nil {
+		apierror.Write(writer, request, fieldValidationError("Invalid matches", err, ""))
+		return
+	}
+
+	ingestResult, err := handler.matchIngestor.Ingest(request.Context(), summoner, matches)
+	if err != nil {
+		requestctx.Logger(request.Context()).Error().Err(err).Str("puuid", summoner.PUUID).Msg("Failed to ingest matches")
+		apierror.Write(writer, request, apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "Failed to store matches"))
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(ingestResult)
+}
+
+// PlayerStats handles requests for a player's all-time statistics over the stored match history
+func (handler *Handler) PlayerStats(writer http.ResponseWriter, request *http.Request) {
+	if handler.matchIngestor == nil {
+		apierror.Write(writer, request, ingestionDisabled())
+		return
+	}
+
+	summoner := &models.Summoner{PUUID: mux.Vars(request)["puuid"]}
+	playerStats, err := handler.matchIngestor.Stats(request.Context(), summoner)
+	if err != nil {
+		requestctx.Logger(request.Context()).Error().Err(err).Str("puuid", summoner.PUUID).Msg("Failed to load player stats")
+		apierror.Write(writer, request, apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "Failed to load player stats"))
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(playerStats)
+}
+
+// AnalyzeStoredMatches handles requests to analyze a player's last N stored matches without re-sending them
+// lastN of zero (or omitted) analyzes the whole stored history
+func (handler *Handler) AnalyzeStoredMatches(writer http.ResponseWriter, request *http.Request) {
+	if handler.matchIngestor == nil {
+		apierror.Write(writer, request, ingestionDisabled())
+		return
+	}
+
+	var analyzeRequest struct {
+		Summoner *models.Summoner       `json:"summoner"`
+		LastN    int                    `json:"lastN"`
+		Options  models.AnalysisOptions `json:"options"`
+	}
+
+	if apiError := decodeBody(request, &analyzeRequest); apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	summoner, apiError := ingestionSummoner(analyzeRequest.Summoner, mux.Vars(request)["puuid"])
+	if apiError != nil {
+		apierror.Write(writer, request, apiError)
+		return
+	}
+
+	if analyzeRequest.LastN < 0 {
+		apierror.Write(writer, request, apierror.Validation("Invalid lastN", apierror.FieldError{
+			Pointer: "/lastN",
+			Message: "lastN must not be negative",
+		}))
+		return
+	}
+
+	if err := services.ValidateBenchmarkSelection(summoner, analyzeRequest.Options); err != nil {
+		apierror.Write(writer, request, fieldValidationError("Invalid benchmark selection", err, ""))
+		return
+	}
+
+	if err := services.ValidateFilters(analyzeRequest.Options.Filters); err != nil {
+		apierror.Write(writer, request, fieldValidationError("Invalid filters", err, ""))
+		return
+	}
+
+	analysisResult, err := handler.matchIngestor.AnalyzeRecent(request.Context(), summoner, analyzeRequest.LastN, analyzeRequest.Options)
+	if err != nil {
+		requestctx.Logger(request.Context()).Error().Err(err).Str("puuid", summoner.PUUID).Msg("Failed to analyze stored matches")
+		apierror.Write(writer, request, apierror.New(http.StatusInternalServerError, apierror.CodeInternal, "Failed to load stored matches"))
+		return
+	}
+	handler.storeAnalysis(request.Context(), summoner.PUUID, analysisResult)
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(analysisResult)
+}
+
+// ingestionSummoner returns the summoner of a stored-history request, defaulting to the PUUID in the path
+// A summoner in the body must not name a different PUUID
+func ingestionSummoner(summoner *models.Summoner, puuid string) (*models.Summoner, *apierror.Error) {
+	if summoner == nil {
+		return &models.Summoner{PUUID: puuid}, nil
+	}
+
+	if summoner.PUUID == "" {
+		summoner.PUUID = puuid
+	}
+
+	if summoner.PUUID != puuid {
+		return nil, apierror.Validation("Summoner does not match the path", apierror.FieldError{
+			Pointer: "/summoner/puuid",
+			Message: "summoner PUUID must match the PUUID in the path",
+		})
+	}
+
+	return summoner, nil
+}
+
+// ingestionDisabled is the error returned when no match ingestor is configured
+func ingestionDisabled() *apierror.Error {
+	return apierror.New(http.StatusNotImplemented, apierror.CodeIngestionDisabled, "Match ingestion is not enabled")
+}
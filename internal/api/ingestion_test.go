@@ -0,0 +1,168 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/services"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+)
+
+// sendPlayerRequest sends a request to a player route through the router and returns the recorder
+func sendPlayerRequest(t *testing.T, handler *Handler, method string, path string, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+	responseRecorder := httptest.NewRecorder()
+	SetupRouter(handler).ServeHTTP(responseRecorder, request)
+	return responseRecorder
+}
+
+// ingestionMatchesJSON builds a matches array of 30 minute games won by test-puuid, one per match ID
+func ingestionMatchesJSON(matchIDs ...string) string {
+	matches := make([]string, 0, len(matchIDs))
+	for index, matchID := range matchIDs {
+		matches = append(matches, fmt.Sprintf(`{"matchId": %q, "gameCreation": "2024-01-%02dT12:00:00Z", "gameDuration": 1800, "participants": [{"puuid": "test-puuid", "kills": %d, "win": true}]}`, matchID, index+1, index+1))
+	}
+	return "[" + strings.Join(matches, ",") + "]"
+}
+
+// newIngestionHandler creates a handler backed by a real ingestor and in-memory store
+func newIngestionHandler() *Handler {
+	store := storage.NewMemoryStore()
+	return NewHandler(
+		services.NewAnalysisService(),
+		WithAnalysisStore(store),
+		WithMatchIngestor(services.NewMatchIngestor(services.NewAnalysisService(), store)),
+	)
+}
+
+// TestIngestMatches tests pushing matches incrementally and reading all-time stats
+func TestIngestMatches(t *testing.T) {
+	handler := newIngestionHandler()
+
+	responseRecorder := sendPlayerRequest(t, handler, "POST", "/api/v1/players/test-puuid/matches", `{"matches": `+ingestionMatchesJSON("EUW1_1", "EUW1_2")+`}`)
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+	}
+
+	// EUW1_1 and EUW1_2 are re-sent alongside a new match
+	responseRecorder = sendPlayerRequest(t, handler, "POST", "/api/v1/players/test-puuid/matches", `{"matches": `+ingestionMatchesJSON("EUW1_1", "EUW1_2", "EUW1_3")+`}`)
+
+	var ingestResult models.IngestResult
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&ingestResult); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if ingestResult.Added != 1 || ingestResult.Duplicates != 2 || ingestResult.PlayerStats.TotalMatches != 3 {
+		t.Errorf("Expected 1 added, 2 duplicates and 3 matches in total, got %+v", ingestResult)
+	}
+
+	responseRecorder = sendPlayerRequest(t, handler, "GET", "/api/v1/players/test-puuid/stats", "")
+	var allTimeStats models.AllTimeStats
+	json.NewDecoder(responseRecorder.Body).Decode(&allTimeStats)
+	if playerStats := allTimeStats.PlayerStats; playerStats.PUUID != "test-puuid" || playerStats.TotalMatches != 3 || playerStats.AverageKills != 2 {
+		t.Errorf("Expected all-time stats over 3 matches, got %+v", allTimeStats)
+	}
+}
+
+// TestIngestMatches_Validation tests rejected ingestion requests
+func TestIngestMatches_Validation(t *testing.T) {
+	testCases := []struct {
+		name            string
+		body            string
+		expectedPointer string
+	}{
+		{"missing match ID", `{"matches": [{"gameCreation": "2024-01-01T12:00:00Z", "gameDuration": 1800, "participants": [{"puuid": "test-puuid"}]}]}`, "/matches/0/matchId"},
+		{"missing game creation", `{"matches": [{"matchId": "EUW1_1", "gameDuration": 1800, "participants": [{"puuid": "test-puuid"}]}]}`, "/matches/0/gameCreation"},
+		{"summoner mismatch", `{"summoner": {"puuid": "other-puuid"}, "matches": []}`, "/summoner/puuid"},
+		{"strict validation", `{"options": {"validationMode": "strict"}, "matches": [{"matchId": "EUW1_1", "gameCreation": "2024-01-01T12:00:00Z", "gameDuration": 0, "participants": [{"puuid": "test-puuid"}]}]}`, "/matches/0/gameDuration"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			responseRecorder := sendPlayerRequest(t, newIngestionHandler(), "POST", "/api/v1/players/test-puuid/matches", testCase.body)
+			if responseRecorder.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status code %d, got %d", http.StatusBadRequest, responseRecorder.Code)
+			}
+
+			apiError := decodeAPIError(t, responseRecorder)
+			if len(apiError.Details) == 0 || apiError.Details[0].Pointer != testCase.expectedPointer {
+				t.Errorf("Expected pointer %s, got %+v", testCase.expectedPointer, apiError.Details)
+			}
+		})
+	}
+}
+
+// TestIngestMatches_RiotMissingGameCreation tests that a Riot match without gameCreation is rejected rather than dated to 1970
+func TestIngestMatches_RiotMissingGameCreation(t *testing.T) {
+	body := `{"matches": [{
+		"metadata": {"matchId": "EUW1_1"},
+		"info": {"gameCreation": 0, "gameDuration": 1800, "gameEndTimestamp": 1700001800000, "participants": [{"puuid": "test-puuid"}]}
+	}]}`
+
+	responseRecorder := sendPlayerRequest(t, newIngestionHandler(), "POST", "/api/v1/players/test-puuid/matches?format=riot-v5", body)
+	if responseRecorder.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusBadRequest, responseRecorder.Code, responseRecorder.Body.String())
+	}
+
+	apiError := decodeAPIError(t, responseRecorder)
+	if len(apiError.Details) == 0 || apiError.Details[0].Pointer != "/matches/0/gameCreation" {
+		t.Errorf("Expected pointer /matches/0/gameCreation, got %+v", apiError.Details)
+	}
+}
+
+// TestAnalyzeStoredMatches tests analyzing the last N pushed matches and storing the result
+func TestAnalyzeStoredMatches(t *testing.T) {
+	handler := newIngestionHandler()
+	sendPlayerRequest(t, handler, "POST", "/api/v1/players/test-puuid/matches", `{"matches": `+ingestionMatchesJSON("EUW1_1", "EUW1_2", "EUW1_3")+`}`)
+
+	responseRecorder := sendPlayerRequest(t, handler, "POST", "/api/v1/players/test-puuid/analyze", `{"lastN": 2}`)
+	if responseRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, responseRecorder.Code, responseRecorder.Body.String())
+	}
+
+	var analysisResult models.AnalysisResult
+	json.NewDecoder(responseRecorder.Body).Decode(&analysisResult)
+	if analysisResult.PlayerStats.TotalMatches != 2 || analysisResult.PlayerStats.AverageKills != 2.5 {
+		t.Errorf("Expected the 2 most recent matches, got %+v", analysisResult.PlayerStats)
+	}
+
+	responseRecorder = getHistory(t, handler, "/api/v1/players/test-puuid/analyses")
+	var history models.AnalysisHistory
+	json.NewDecoder(responseRecorder.Body).Decode(&history)
+	if len(history.Analyses) != 1 {
+		t.Errorf("Expected the analysis to be stored in the history, got %d analyses", len(history.Analyses))
+	}
+
+	responseRecorder = sendPlayerRequest(t, handler, "POST", "/api/v1/players/test-puuid/analyze", `{"lastN": -1}`)
+	if apiError := decodeAPIError(t, responseRecorder); len(apiError.Details) == 0 || apiError.Details[0].Pointer != "/lastN" {
+		t.Errorf("Expected a negative lastN to be rejected at /lastN, got %+v", apiError)
+	}
+}
+
+// TestIngestion_Disabled tests the ingestion endpoints without an ingestor
+func TestIngestion_Disabled(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{})
+
+	for _, route := range []struct{ method, path string }{
+		{"POST", "/api/v1/players/test-puuid/matches"},
+		{"GET", "/api/v1/players/test-puuid/stats"},
+		{"POST", "/api/v1/players/test-puuid/analyze"},
+	} {
+		responseRecorder := sendPlayerRequest(t, handler, route.method, route.path, `{}`)
+		if responseRecorder.Code != http.StatusNotImplemented {
+			t.Errorf("Expected status code %d for %s, got %d", http.StatusNotImplemented, route.path, responseRecorder.Code)
+		}
+		if apiError := decodeAPIError(t, responseRecorder); apiError.Code != apierror.CodeIngestionDisabled {
+			t.Errorf("Expected code %s, got %s", apierror.CodeIngestionDisabled, apiError.Code)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package api
 
 import (
+	"net/http"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/auth"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/middleware"
 	"github.com/gorilla/mux"
 )
 
@@ -8,11 +13,59 @@ import (
 func SetupRouter(handler *Handler) *mux.Router {
 	router := mux.NewRouter()
 
+	// Unknown routes and methods answer with the standard error envelope
+	router.NotFoundHandler = apierror.NotFoundHandler()
+	router.MethodNotAllowedHandler = apierror.MethodNotAllowedHandler()
+
 	// Health check endpoint
 	router.HandleFunc("/health", handler.HealthCheck).Methods("POST")
 
+	// Prometheus metrics endpoint
+	if handler.serviceMetrics != nil {
+		router.Handle("/metrics", handler.serviceMetrics.Registry.Handler()).Methods("GET")
+	}
+
 	// Analysis endpoint
-	router.HandleFunc("/api/v1/analyze", handler.AnalyzePlayer).Methods("POST")
+	router.Handle("/api/v1/analyze", handler.guard(auth.ScopeAnalyze, handler.AnalyzePlayer)).Methods("POST")
+	router.Handle("/api/v1/analyze/batch", handler.guard(auth.ScopeBatch, handler.AnalyzeBatch)).Methods("POST")
+
+	// Comparison endpoint
+	router.Handle("/api/v1/compare", handler.guard(auth.ScopeAnalyze, handler.ComparePlayers)).Methods("POST")
+
+	// Analysis history endpoints
+	router.Handle("/api/v1/players/{puuid}/analyses", handler.guard(auth.ScopeAnalyze, handler.ListAnalyses)).Methods("GET")
+	router.Handle("/api/v1/analyses/diff", handler.guard(auth.ScopeAnalyze, handler.DiffAnalyses)).Methods("POST")
+
+	// Match ingestion endpoints
+	router.Handle("/api/v1/players/{puuid}/matches", handler.guard(auth.ScopeAnalyze, handler.IngestMatches)).Methods("POST")
+	router.Handle("/api/v1/players/{puuid}/stats", handler.guard(auth.ScopeAnalyze, handler.PlayerStats)).Methods("GET")
+	router.Handle("/api/v1/players/{puuid}/analyze", handler.guard(auth.ScopeAnalyze, handler.AnalyzeStoredMatches)).Methods("POST")
+
+	// Admin endpoints
+	router.Handle("/api/v1/admin/rules/reload", handler.guard(auth.ScopeAdmin, handler.ReloadRules)).Methods("POST")
 
 	return router
 }
+
+// guard wraps a route with the configured access controls for its scope: the API key scope check, then the
+// scope's rate limiter and the daily match quota, so authenticated requests are limited per client
+func (handler *Handler) guard(scope string, handlerFunc http.HandlerFunc) http.Handler {
+	var guarded http.Handler = handlerFunc
+
+	if scope != auth.ScopeAdmin && handler.matchQuota != nil {
+		guarded = middleware.MatchQuotaMiddleware(handler.matchQuota, guarded)
+	}
+
+	rateLimiter := handler.analyzeLimiter
+	if scope == auth.ScopeBatch {
+		rateLimiter = handler.batchLimiter
+	}
+	if scope != auth.ScopeAdmin && rateLimiter != nil {
+		guarded = middleware.RateLimitMiddleware(rateLimiter, guarded)
+	}
+
+	if handler.keyStore != nil {
+		guarded = middleware.RequireScope(handler.keyStore, scope, guarded)
+	}
+	return guarded
+}
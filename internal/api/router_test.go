@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/auth"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/metrics"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/middleware"
 	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
 )
 
@@ -115,6 +120,10 @@ func TestRouterAllEndpointsUsePOST(t *testing.T) {
 	endpoints := []string{
 		"/health",
 		"/api/v1/analyze",
+		"/api/v1/analyze/batch",
+		"/api/v1/compare",
+		"/api/v1/analyses/diff",
+		"/api/v1/admin/rules/reload",
 	}
 
 	for _, endpoint := range endpoints {
@@ -131,3 +140,151 @@ func TestRouterAllEndpointsUsePOST(t *testing.T) {
 		})
 	}
 }
+
+// TestRouterReloadRulesEndpoint tests that the rule reload endpoint is registered
+func TestRouterReloadRulesEndpoint(t *testing.T) {
+	reloaded := false
+	mockService := &MockAnalysisService{
+		ReloadRulesFunc: func() (string, error) {
+			reloaded = true
+			return "test", nil
+		},
+	}
+	handler := NewHandler(mockService)
+	router := SetupRouter(handler)
+
+	request, _ := http.NewRequest("POST", "/api/v1/admin/rules/reload", nil)
+	responseRecorder := httptest.NewRecorder()
+
+	router.ServeHTTP(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, responseRecorder.Code)
+	}
+
+	if !reloaded {
+		t.Error("Expected ReloadRules to be called")
+	}
+}
+
+// TestRouterErrorEnvelope tests that 404 and 405 responses use the JSON error envelope
+func TestRouterErrorEnvelope(t *testing.T) {
+	router := SetupRouter(NewHandler(&MockAnalysisService{}))
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		expectedCode string
+	}{
+		{"unknown route", "POST", "/api/v1/unknown", apierror.CodeNotFound},
+		{"wrong method", "GET", "/api/v1/analyze", apierror.CodeMethodNotAllowed},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, _ := http.NewRequest(testCase.method, testCase.path, nil)
+			responseRecorder := httptest.NewRecorder()
+
+			router.ServeHTTP(responseRecorder, request)
+
+			if apiError := decodeAPIError(t, responseRecorder); apiError.Code != testCase.expectedCode || apiError.RequestID == "" {
+				t.Errorf("Expected code '%s' with a request ID, got %+v", testCase.expectedCode, apiError)
+			}
+		})
+	}
+}
+
+// TestRouterMetricsEndpoint tests that /metrics is only registered when metrics are configured
+func TestRouterMetricsEndpoint(t *testing.T) {
+	request, _ := http.NewRequest("GET", "/metrics", nil)
+	responseRecorder := httptest.NewRecorder()
+	SetupRouter(NewHandler(&MockAnalysisService{})).ServeHTTP(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d without metrics, got %d", http.StatusNotFound, responseRecorder.Code)
+	}
+
+	responseRecorder = httptest.NewRecorder()
+	SetupRouter(NewHandler(&MockAnalysisService{}, WithMetrics(metrics.NewServiceMetrics()))).ServeHTTP(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusOK || !strings.Contains(responseRecorder.Body.String(), "# TYPE opgl_http_requests_total counter") {
+		t.Errorf("Expected the metrics exposition, got %d: %s", responseRecorder.Code, responseRecorder.Body.String())
+	}
+}
+
+// TestRouterAPIKeyScopes tests that configured API keys guard each route with its scope and leave /health open
+func TestRouterAPIKeyScopes(t *testing.T) {
+	keyStore, err := auth.NewKeyStore("", "partner-a:"+auth.HashKey("partner-key")+":analyze;ops:"+auth.HashKey("ops-key")+":admin")
+	if err != nil {
+		t.Fatalf("Failed to create key store: %v", err)
+	}
+
+	mockService := &MockAnalysisService{
+		ReloadRulesFunc: func() (string, error) { return "test", nil },
+	}
+	router := SetupRouter(NewHandler(mockService, WithAPIKeys(keyStore)))
+
+	testCases := []struct {
+		name           string
+		path           string
+		key            string
+		expectedStatus int
+	}{
+		{"health stays open", "/health", "", http.StatusOK},
+		{"analyze without key", "/api/v1/analyze", "", http.StatusUnauthorized},
+		{"analyze with analyze scope", "/api/v1/analyze", "partner-key", http.StatusOK},
+		{"batch without batch scope", "/api/v1/analyze/batch", "partner-key", http.StatusForbidden},
+		{"reload without admin scope", "/api/v1/admin/rules/reload", "partner-key", http.StatusForbidden},
+		{"reload with admin scope", "/api/v1/admin/rules/reload", "ops-key", http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			body := `{"summoner": {"puuid": "test-puuid"}, "matches": [{"matchId": "M1", "gameCreation": "2024-01-01T00:00:00Z", "participants": [{"puuid": "test-puuid", "championName": "Ahri", "win": true}]}]}`
+			request, _ := http.NewRequest("POST", testCase.path, strings.NewReader(body))
+			if testCase.key != "" {
+				request.Header.Set("Authorization", "Bearer "+testCase.key)
+			}
+			responseRecorder := httptest.NewRecorder()
+
+			router.ServeHTTP(responseRecorder, request)
+
+			if responseRecorder.Code != testCase.expectedStatus {
+				t.Errorf("Expected status code %d, got %d: %s", testCase.expectedStatus, responseRecorder.Code, responseRecorder.Body.String())
+			}
+		})
+	}
+}
+
+// TestRouterRateLimits tests that analyze and batch routes draw from separate buckets and /health is unlimited
+func TestRouterRateLimits(t *testing.T) {
+	handler := NewHandler(&MockAnalysisService{}, WithRateLimits(middleware.NewRateLimiter(1, 1), middleware.NewRateLimiter(1, 1)))
+	router := SetupRouter(handler)
+
+	testCases := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"first analyze", "/api/v1/analyze", `{"summoner": {"puuid": "test-puuid"}, "matches": []}`, http.StatusOK},
+		{"second analyze", "/api/v1/analyze", `{"summoner": {"puuid": "test-puuid"}, "matches": []}`, http.StatusTooManyRequests},
+		{"compare shares the analyze bucket", "/api/v1/compare", `{"players": []}`, http.StatusTooManyRequests},
+		{"batch has its own bucket", "/api/v1/analyze/batch", `{"entries": [{"summoner": {"puuid": "test-puuid"}, "matches": []}]}`, http.StatusOK},
+		{"health is unlimited", "/health", "", http.StatusOK},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, _ := http.NewRequest("POST", testCase.path, strings.NewReader(testCase.body))
+			responseRecorder := httptest.NewRecorder()
+
+			router.ServeHTTP(responseRecorder, request)
+
+			if responseRecorder.Code != testCase.expectedStatus {
+				t.Errorf("Expected status code %d, got %d: %s", testCase.expectedStatus, responseRecorder.Code, responseRecorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+)
+
+// RequestIDHeader carries the request ID between clients, proxies and the service
+const RequestIDHeader = requestctx.Header
+
+// Error codes returned in the code field of every error response
+const (
+	CodeInvalidBody       = "INVALID_BODY"
+	CodeValidationFailed  = "VALIDATION_FAILED"
+	CodePayloadTooLarge   = "PAYLOAD_TOO_LARGE"
+	CodeNotFound          = "NOT_FOUND"
+	CodeMethodNotAllowed  = "METHOD_NOT_ALLOWED"
+	CodeRuleReloadFailed  = "RULE_RELOAD_FAILED"
+	CodeHistoryDisabled   = "HISTORY_DISABLED"
+	CodeAnalysisNotFound  = "ANALYSIS_NOT_FOUND"
+	CodeIngestionDisabled = "INGESTION_DISABLED"
+	CodeUnauthenticated   = "UNAUTHENTICATED"
+	CodeForbidden         = "FORBIDDEN"
+	CodeRateLimited       = "RATE_LIMITED"
+	CodeQuotaExceeded     = "QUOTA_EXCEEDED"
+	CodeInternal          = "INTERNAL_ERROR"
+)
+
+// Error is the standard error envelope returned by every endpoint
+type Error struct {
+	// HTTP status code of the response
+	Status int `json:"-"`
+	// Stable machine-readable error code
+	Code string `json:"code"`
+	// Human-readable description of the error
+	Message string `json:"message"`
+	// Field-level failures, when the error concerns specific request fields
+	Details []FieldError `json:"details,omitempty"`
+	// ID of the request that failed, for correlating with server logs
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// FieldError describes a failure of a single request field or query parameter
+type FieldError struct {
+	// JSON pointer (RFC 6901) to the field within the request body (e.g., /summoner/tier)
+	Pointer string `json:"pointer,omitempty"`
+	// Name of the query parameter, for failures outside the request body
+	Parameter string `json:"parameter,omitempty"`
+	// Description of what is wrong with the field
+	Message string `json:"message"`
+}
+
+// New creates an Error with the given status, code and message
+func New(status int, code string, message string) *Error {
+	return &Error{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// Validation creates a 400 Error for failed field validation
+func Validation(message string, details ...FieldError) *Error {
+	validationError := New(http.StatusBadRequest, CodeValidationFailed, message)
+	validationError.Details = details
+	return validationError
+}
+
+// Error returns the error message
+func (apiError *Error) Error() string {
+	return apiError.Message
+}
+
+// Write sends the error as JSON, tagged with the request's ID
+func Write(writer http.ResponseWriter, request *http.Request, apiError *Error) {
+	response := *apiError
+	response.RequestID = RequestID(writer, request)
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(response.Status)
+	json.NewEncoder(writer).Encode(response)
+}
+
+// RequestID returns the request's ID, echoing it in the response headers
+// The ID assigned by the request ID middleware is preferred; without it the client's X-Request-ID
+// is reused when present, and otherwise a new ID is generated
+func RequestID(writer http.ResponseWriter, request *http.Request) string {
+	if requestID := requestctx.RequestID(request.Context()); requestID != "" {
+		writer.Header().Set(RequestIDHeader, requestID)
+		return requestID
+	}
+
+	if requestID := writer.Header().Get(RequestIDHeader); requestID != "" {
+		return requestID
+	}
+
+	requestID := request.Header.Get(RequestIDHeader)
+	if requestID == "" {
+		requestID = requestctx.NewID()
+	}
+
+	writer.Header().Set(RequestIDHeader, requestID)
+	return requestID
+}
+
+// NotFoundHandler answers requests for unknown routes with the error envelope
+func NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		Write(writer, request, New(http.StatusNotFound, CodeNotFound, "No route matches "+request.URL.Path))
+	})
+}
+
+// MethodNotAllowedHandler answers requests with an unsupported method with the error envelope
+func MethodNotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		Write(writer, request, New(http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method "+request.Method+" is not allowed on "+request.URL.Path))
+	})
+}
@@ -0,0 +1,111 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+)
+
+// decodeError decodes an error envelope from a recorded response
+func decodeError(t *testing.T, responseRecorder *httptest.ResponseRecorder) Error {
+	t.Helper()
+
+	var apiError Error
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&apiError); err != nil {
+		t.Fatalf("Failed to decode error envelope: %v", err)
+	}
+	return apiError
+}
+
+// TestWrite tests the envelope, status code and echoed request ID
+func TestWrite(t *testing.T) {
+	request := httptest.NewRequest("POST", "/api/v1/analyze", nil)
+	request.Header.Set(RequestIDHeader, "client-request-1")
+	responseRecorder := httptest.NewRecorder()
+
+	Write(responseRecorder, request, Validation("Invalid filters", FieldError{Pointer: "/options/filters/roles/0", Message: "unknown role"}))
+
+	if responseRecorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, responseRecorder.Code)
+	}
+
+	if contentType := responseRecorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Expected Content-Type 'application/json', got '%s'", contentType)
+	}
+
+	if responseRecorder.Header().Get(RequestIDHeader) != "client-request-1" {
+		t.Errorf("Expected the client's request ID to be echoed, got '%s'", responseRecorder.Header().Get(RequestIDHeader))
+	}
+
+	apiError := decodeError(t, responseRecorder)
+	if apiError.Code != CodeValidationFailed || apiError.Message != "Invalid filters" || apiError.RequestID != "client-request-1" {
+		t.Errorf("Unexpected envelope %+v", apiError)
+	}
+
+	if len(apiError.Details) != 1 || apiError.Details[0].Pointer != "/options/filters/roles/0" {
+		t.Errorf("Expected one field error at /options/filters/roles/0, got %+v", apiError.Details)
+	}
+}
+
+// TestWrite_GeneratesRequestID tests that a request ID is generated when the client sends none
+func TestWrite_GeneratesRequestID(t *testing.T) {
+	request := httptest.NewRequest("POST", "/api/v1/analyze", nil)
+	responseRecorder := httptest.NewRecorder()
+
+	Write(responseRecorder, request, New(http.StatusInternalServerError, CodeInternal, "boom"))
+
+	apiError := decodeError(t, responseRecorder)
+	if len(apiError.RequestID) != 32 || apiError.RequestID != responseRecorder.Header().Get(RequestIDHeader) {
+		t.Errorf("Expected a generated request ID matching the response header, got '%s'", apiError.RequestID)
+	}
+
+	if apiError.Details != nil {
+		t.Errorf("Expected no details, got %+v", apiError.Details)
+	}
+}
+
+// TestWrite_PrefersContextRequestID tests that the ID assigned by the request ID middleware wins
+func TestWrite_PrefersContextRequestID(t *testing.T) {
+	request := httptest.NewRequest("POST", "/api/v1/analyze", nil)
+	request.Header.Set(RequestIDHeader, "invalid id")
+	request = request.WithContext(requestctx.WithRequestID(request.Context(), "middleware-request-1"))
+	responseRecorder := httptest.NewRecorder()
+
+	Write(responseRecorder, request, New(http.StatusInternalServerError, CodeInternal, "boom"))
+
+	apiError := decodeError(t, responseRecorder)
+	if apiError.RequestID != "middleware-request-1" || responseRecorder.Header().Get(RequestIDHeader) != "middleware-request-1" {
+		t.Errorf("Expected the context request ID in the body and header, got '%s'", apiError.RequestID)
+	}
+}
+
+// TestRouteHandlers tests the 404 and 405 handlers
+func TestRouteHandlers(t *testing.T) {
+	testCases := []struct {
+		name           string
+		handler        http.Handler
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"not found", NotFoundHandler(), http.StatusNotFound, CodeNotFound},
+		{"method not allowed", MethodNotAllowedHandler(), http.StatusMethodNotAllowed, CodeMethodNotAllowed},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			responseRecorder := httptest.NewRecorder()
+			testCase.handler.ServeHTTP(responseRecorder, httptest.NewRequest("GET", "/unknown", nil))
+
+			if responseRecorder.Code != testCase.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", testCase.expectedStatus, responseRecorder.Code)
+			}
+
+			if apiError := decodeError(t, responseRecorder); apiError.Code != testCase.expectedCode {
+				t.Errorf("Expected code '%s', got '%s'", testCase.expectedCode, apiError.Code)
+			}
+		})
+	}
+}
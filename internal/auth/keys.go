@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scopes granted to API keys
+const (
+	// ScopeAnalyze allows single-player analysis, comparison, history, diffs and match ingestion
+	ScopeAnalyze = "analyze"
+	// ScopeBatch allows batch analysis
+	ScopeBatch = "batch"
+	// ScopeAdmin allows administrative operations such as reloading rules
+	ScopeAdmin = "admin"
+)
+
+// APIKeyHeader is an alternative to "Authorization: Bearer <key>" for sending an API key
+const APIKeyHeader = "X-API-Key"
+
+// knownScopes lists every scope a key may be granted
+var knownScopes = map[string]bool{ScopeAnalyze: true, ScopeBatch: true, ScopeAdmin: true}
+
+// ErrInvalidKey is returned when an API key is unknown or revoked
+var ErrInvalidKey = errors.New("invalid API key")
+
+// Client is the identity behind an API key
+type Client struct {
+	// Name identifying the client in logs, traces and metrics
+	ID string
+	// Scopes granted to the client's key
+	Scopes []string
+}
+
+// HasScope reports whether the client was granted scope
+func (client *Client) HasScope(scope string) bool {
+	for _, grantedScope := range client.Scopes {
+		if grantedScope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyFile is the layout of an API key file
+type KeyFile struct {
+	Keys []KeyEntry `json:"keys" yaml:"keys"`
+}
+
+// KeyEntry is a single API key; only the SHA-256 hash of the key is stored
+type KeyEntry struct {
+	// Unique client name
+	Client string `json:"client" yaml:"client"`
+	// Hex SHA-256 hash of the key, optionally prefixed with "sha256:"
+	Hash string `json:"hash" yaml:"hash"`
+	// Scopes granted to the key
+	Scopes []string `json:"scopes" yaml:"scopes"`
+	// Revoked keys are rejected; keeping the entry documents the revocation
+	Revoked bool `json:"revoked,omitempty" yaml:"revoked,omitempty"`
+}
+
+// KeyStore authenticates API keys against hashed keys loaded from a file and/or an environment value
+// The file can be reloaded at runtime to add or revoke keys
+type KeyStore struct {
+	path     string
+	envValue string
+
+	mutex sync.RWMutex
+	// Clients keyed by the hex SHA-256 hash of their key
+	clients map[string]*Client
+}
+
+// NewKeyStore loads hashed keys from path (YAML, or JSON for .json files) and from envValue
+// Either source may be empty. envValue lists keys as "client:hash:scope,scope" separated by semicolons
+func NewKeyStore(path string, envValue string) (*KeyStore, error) {
+	keyStore := &KeyStore{path: path, envValue: envValue}
+	if err := keyStore.Reload(); err != nil {
+		return nil, err
+	}
+	return keyStore, nil
+}
+
+// Reload re-reads the key sources and swaps in the new keys
+// The active keys are kept when a source is missing or invalid
+func (keyStore *KeyStore) Reload() error {
+	var entries []KeyEntry
+
+	if keyStore.path != "" {
+		fileEntries, err := loadKeyFile(keyStore.path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	envEntries, err := parseKeyList(keyStore.envValue)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, envEntries...)
+
+	clients, err := buildClients(entries)
+	if err != nil {
+		return err
+	}
+
+	keyStore.mutex.Lock()
+	keyStore.clients = clients
+	keyStore.mutex.Unlock()
+	return nil
+}
+
+// Authenticate returns the client owning key, or ErrInvalidKey
+func (keyStore *KeyStore) Authenticate(key string) (*Client, error) {
+	if key == "" {
+		return nil, ErrInvalidKey
+	}
+
+	keyStore.mutex.RLock()
+	defer keyStore.mutex.RUnlock()
+
+	client, exists := keyStore.clients[HashKey(key)]
+	if !exists {
+		return nil, ErrInvalidKey
+	}
+	return client, nil
+}
+
+// HashKey returns the hex SHA-256 hash stored for a key
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyFromRequest returns the API key sent as "Authorization: Bearer <key>" or in the X-API-Key header
+func KeyFromRequest(request *http.Request) string {
+	if authorization := request.Header.Get("Authorization"); authorization != "" {
+		if scheme, key, found := strings.Cut(authorization, " "); found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(key)
+		}
+	}
+	return strings.TrimSpace(request.Header.Get(APIKeyHeader))
+}
+
+// contextKey is the type of the keys this package stores in a context
+type contextKey int
+
+// clientKey stores the authenticated client in a context
+const clientKey contextKey = 0
+
+// WithClient returns a copy of ctx carrying the authenticated client
+func WithClient(ctx context.Context, client *Client) context.Context {
+	return context.WithValue(ctx, clientKey, client)
+}
+
+// ClientFromContext returns the authenticated client of ctx, or nil for anonymous requests
+func ClientFromContext(ctx context.Context) *Client {
+	client, _ := ctx.Value(clientKey).(*Client)
+	return client
+}
+
+// loadKeyFile reads the key entries of a YAML or JSON key file, rejecting unknown fields
+func loadKeyFile(path string) ([]KeyEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API key file: %w", err)
+	}
+
+	var keyFile KeyFile
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&keyFile)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&keyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse API key file: %w", err)
+	}
+
+	return keyFile.Keys, nil
+}
+
+// parseKeyList parses "client:hash:scope,scope" entries separated by semicolons
+func parseKeyList(value string) ([]KeyEntry, error) {
+	var entries []KeyEntry
+
+	for index, item := range strings.Split(value, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		fields := strings.Split(item, ":")
+		// A "sha256:" hash prefix adds a field
+		if len(fields) == 4 && strings.EqualFold(fields[1], "sha256") {
+			fields = []string{fields[0], fields[1] + ":" + fields[2], fields[3]}
+		}
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("API key entry %d: expected client:hash:scopes", index)
+		}
+
+		entries = append(entries, KeyEntry{
+			Client: fields[0],
+			Hash:   fields[1],
+			Scopes: strings.Split(fields[2], ","),
+		})
+	}
+
+	return entries, nil
+}
+
+// buildClients validates key entries and indexes the active ones by hash
+// Every problem is reported at once
+func buildClients(entries []KeyEntry) (map[string]*Client, error) {
+	var problems []string
+	clients := make(map[string]*Client)
+	seenClients := make(map[string]bool)
+	seenHashes := make(map[string]bool)
+
+	for index, entry := range entries {
+		client := strings.TrimSpace(entry.Client)
+		prefix := fmt.Sprintf("keys[%d]", index)
+		if client != "" {
+			prefix = fmt.Sprintf("keys[%d] (%s)", index, client)
+		}
+
+		if client == "" {
+			problems = append(problems, prefix+": client is required")
+		} else if seenClients[client] {
+			problems = append(problems, prefix+": duplicate client")
+		}
+		seenClients[client] = true
+
+		hash := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(entry.Hash), "sha256:"))
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			problems = append(problems, prefix+": hash must be a hex SHA-256 digest")
+		} else if seenHashes[hash] {
+			problems = append(problems, prefix+": duplicate hash")
+		}
+		seenHashes[hash] = true
+
+		scopes := make([]string, 0, len(entry.Scopes))
+		for _, scope := range entry.Scopes {
+			scope = strings.ToLower(strings.TrimSpace(scope))
+			if !knownScopes[scope] {
+				problems = append(problems, fmt.Sprintf("%s: unknown scope %q", prefix, scope))
+				continue
+			}
+			scopes = append(scopes, scope)
+		}
+		if len(entry.Scopes) == 0 {
+			problems = append(problems, prefix+": at least one scope is required")
+		}
+
+		if !entry.Revoked {
+			clients[hash] = &Client{ID: client, Scopes: scopes}
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid API keys:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+
+	return clients, nil
+}
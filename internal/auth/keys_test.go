@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeKeyFile writes a key file into a temporary directory and returns its path
+func writeKeyFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	return path
+}
+
+// TestNewKeyStore_FileFormats tests that YAML and JSON key files authenticate their clients with their scopes
+func TestNewKeyStore_FileFormats(t *testing.T) {
+	testCases := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"yaml", "keys.yaml", "keys:\n  - client: partner-a\n    hash: " + HashKey("secret-a") + "\n    scopes: [analyze, batch]\n"},
+		{"json", "keys.json", `{"keys": [{"client": "partner-a", "hash": "sha256:` + HashKey("secret-a") + `", "scopes": ["analyze", "batch"]}]}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			keyStore, err := NewKeyStore(writeKeyFile(t, testCase.file, testCase.content), "")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			client, err := keyStore.Authenticate("secret-a")
+			if err != nil {
+				t.Fatalf("Expected the key to authenticate, got %v", err)
+			}
+			if client.ID != "partner-a" || !client.HasScope(ScopeBatch) || client.HasScope(ScopeAdmin) {
+				t.Errorf("Expected partner-a with analyze and batch scopes, got %+v", client)
+			}
+
+			if _, err := keyStore.Authenticate("secret-b"); err != ErrInvalidKey {
+				t.Errorf("Expected ErrInvalidKey for an unknown key, got %v", err)
+			}
+		})
+	}
+}
+
+// TestNewKeyStore_EnvKeys tests keys supplied through the API_KEYS format
+func TestNewKeyStore_EnvKeys(t *testing.T) {
+	keyStore, err := NewKeyStore("", "ops:"+HashKey("ops-key")+":admin; partner-b:sha256:"+HashKey("b-key")+":analyze")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if client, err := keyStore.Authenticate("ops-key"); err != nil || client.ID != "ops" || !client.HasScope(ScopeAdmin) {
+		t.Errorf("Expected the ops client with the admin scope, got %+v (%v)", client, err)
+	}
+	if client, err := keyStore.Authenticate("b-key"); err != nil || client.ID != "partner-b" {
+		t.Errorf("Expected the partner-b client, got %+v (%v)", client, err)
+	}
+}
+
+// TestNewKeyStore_InvalidKeys tests that every problem of the key sources is reported at once
+func TestNewKeyStore_InvalidKeys(t *testing.T) {
+	content := "keys:\n" +
+		"  - client: partner-a\n    hash: " + HashKey("a") + "\n    scopes: [analyze]\n" +
+		"  - client: partner-a\n    hash: not-a-hash\n    scopes: [superuser]\n" +
+		"  - client: partner-c\n    hash: " + HashKey("a") + "\n    scopes: []\n"
+
+	_, err := NewKeyStore(writeKeyFile(t, "keys.yaml", content), "")
+	if err == nil {
+		t.Fatal("Expected an error for invalid keys")
+	}
+
+	for _, expected := range []string{"duplicate client", "hex SHA-256", `unknown scope "superuser"`, "duplicate hash", "at least one scope"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected the error to mention %q, got %v", expected, err)
+		}
+	}
+
+	if _, err := NewKeyStore(writeKeyFile(t, "keys.yaml", "keys: []\nunknown: true\n"), ""); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+	if _, err := NewKeyStore("", "partner-a:"+HashKey("a")); err == nil {
+		t.Error("Expected an error for an API_KEYS entry without scopes")
+	}
+}
+
+// TestKeyStore_ReloadRevokesKeys tests that reloading drops revoked keys and keeps the old keys on a bad file
+func TestKeyStore_ReloadRevokesKeys(t *testing.T) {
+	path := writeKeyFile(t, "keys.yaml", "keys:\n  - client: partner-a\n    hash: "+HashKey("secret-a")+"\n    scopes: [analyze]\n")
+	keyStore, err := NewKeyStore(path, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("keys: [not valid"), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	if err := keyStore.Reload(); err == nil {
+		t.Error("Expected an error reloading an invalid file")
+	}
+	if _, err := keyStore.Authenticate("secret-a"); err != nil {
+		t.Errorf("Expected the previous keys to stay active, got %v", err)
+	}
+
+	revoked := "keys:\n  - client: partner-a\n    hash: " + HashKey("secret-a") + "\n    scopes: [analyze]\n    revoked: true\n"
+	if err := os.WriteFile(path, []byte(revoked), 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	if err := keyStore.Reload(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := keyStore.Authenticate("secret-a"); err != ErrInvalidKey {
+		t.Errorf("Expected the revoked key to be rejected, got %v", err)
+	}
+}
+
+// TestKeyFromRequest tests reading the key from the Authorization and X-API-Key headers
+func TestKeyFromRequest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		value    string
+		expected string
+	}{
+		{"bearer", "Authorization", "Bearer secret-a", "secret-a"},
+		{"lowercase bearer", "Authorization", "bearer secret-a", "secret-a"},
+		{"api key header", APIKeyHeader, "secret-a", "secret-a"},
+		{"basic auth ignored", "Authorization", "Basic c2VjcmV0", ""},
+		{"no key", "", "", ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request, _ := http.NewRequest("POST", "/api/v1/analyze", nil)
+			if testCase.header != "" {
+				request.Header.Set(testCase.header, testCase.value)
+			}
+
+			if key := KeyFromRequest(request); key != testCase.expected {
+				t.Errorf("Expected key %q, got %q", testCase.expected, key)
+			}
+		})
+	}
+}
+
+// TestClientFromContext tests storing the authenticated client in a context
+func TestClientFromContext(t *testing.T) {
+	if client := ClientFromContext(context.Background()); client != nil {
+		t.Errorf("Expected no client in an empty context, got %+v", client)
+	}
+
+	client := &Client{ID: "partner-a"}
+	if stored := ClientFromContext(WithClient(context.Background(), client)); stored != client {
+		t.Errorf("Expected the stored client, got %+v", stored)
+	}
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sync"
+)
+
+// Counter is a monotonically increasing metric, with one series per combination of label values
+type Counter struct {
+	family
+	mutex  sync.Mutex
+	series map[string]float64
+}
+
+// NewCounter creates a Counter and registers it in the registry
+func (registry *Registry) NewCounter(name string, help string, labelNames ...string) *Counter {
+	counter := &Counter{
+		family: family{name: name, help: help, kind: "counter", labelNames: labelNames},
+		series: make(map[string]float64),
+	}
+	registry.register(counter)
+	return counter
+}
+
+// Inc adds one to the series with the given label values
+func (counter *Counter) Inc(labelValues ...string) {
+	counter.Add(1, labelValues...)
+}
+
+// Add adds a non-negative value to the series with the given label values
+func (counter *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		panic(fmt.Sprintf("counter %s cannot decrease", counter.name))
+	}
+
+	key := counter.seriesKey(labelValues)
+	counter.mutex.Lock()
+	counter.series[key] += value
+	counter.mutex.Unlock()
+}
+
+// Value returns the current value of the series with the given label values
+func (counter *Counter) Value(labelValues ...string) float64 {
+	key := counter.seriesKey(labelValues)
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+	return counter.series[key]
+}
+
+// write writes every series of the counter
+func (counter *Counter) write(writer *bufio.Writer) {
+	counter.mutex.Lock()
+	defer counter.mutex.Unlock()
+
+	counter.writeHeader(writer)
+	for _, key := range sortedKeys(counter.series) {
+		labelValues := splitKey(key, len(counter.labelNames))
+		fmt.Fprintf(writer, "%s%s %s\n", counter.name, counter.labels(labelValues), formatValue(counter.series[key]))
+	}
+}
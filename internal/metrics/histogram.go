@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultDurationBuckets are upper bounds in seconds suited to request and analysis latencies
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// histogramSeries holds the observations of one combination of label values
+type histogramSeries struct {
+	// Observations per bucket (not cumulative), in bucket order
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+// Histogram counts observations into buckets, with one series per combination of label values
+type Histogram struct {
+	family
+	// Sorted upper bounds of the buckets, excluding +Inf
+	buckets []float64
+	mutex   sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+// NewHistogram creates a Histogram with the given bucket upper bounds and registers it in the registry
+func (registry *Registry) NewHistogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	sortedBuckets := append([]float64(nil), buckets...)
+	sort.Float64s(sortedBuckets)
+
+	histogram := &Histogram{
+		family:  family{name: name, help: help, kind: "histogram", labelNames: labelNames},
+		buckets: sortedBuckets,
+		series:  make(map[string]*histogramSeries),
+	}
+	registry.register(histogram)
+	return histogram
+}
+
+// Observe records a value in the series with the given label values
+func (histogram *Histogram) Observe(value float64, labelValues ...string) {
+	key := histogram.seriesKey(labelValues)
+
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+
+	series, exists := histogram.series[key]
+	if !exists {
+		series = &histogramSeries{bucketCounts: make([]uint64, len(histogram.buckets))}
+		histogram.series[key] = series
+	}
+
+	series.count++
+	series.sum += value
+	if bucketIndex := sort.SearchFloat64s(histogram.buckets, value); bucketIndex < len(histogram.buckets) {
+		series.bucketCounts[bucketIndex]++
+	}
+}
+
+// Count returns the number of observations in the series with the given label values
+func (histogram *Histogram) Count(labelValues ...string) uint64 {
+	key := histogram.seriesKey(labelValues)
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+
+	if series, exists := histogram.series[key]; exists {
+		return series.count
+	}
+	return 0
+}
+
+// write writes the cumulative buckets, sum and count of every series of the histogram
+func (histogram *Histogram) write(writer *bufio.Writer) {
+	histogram.mutex.Lock()
+	defer histogram.mutex.Unlock()
+
+	histogram.writeHeader(writer)
+	for _, key := range sortedKeys(histogram.series) {
+		series := histogram.series[key]
+		labelValues := splitKey(key, len(histogram.labelNames))
+
+		var cumulative uint64
+		for bucketIndex, upperBound := range histogram.buckets {
+			cumulative += series.bucketCounts[bucketIndex]
+			fmt.Fprintf(writer, "%s_bucket%s %d\n", histogram.name, histogram.labels(labelValues, "le", formatValue(upperBound)), cumulative)
+		}
+		fmt.Fprintf(writer, "%s_bucket%s %d\n", histogram.name, histogram.labels(labelValues, "le", "+Inf"), series.count)
+		fmt.Fprintf(writer, "%s_sum%s %s\n", histogram.name, histogram.labels(labelValues), formatValue(series.sum))
+		fmt.Fprintf(writer, "%s_count%s %d\n", histogram.name, histogram.labels(labelValues), series.count)
+	}
+}
@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the Content-Type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelSeparator joins label values into a series key; it cannot appear in valid UTF-8 text
+const labelSeparator = "\xff"
+
+// collector is a metric family that can write itself in the text exposition format
+type collector interface {
+	write(writer *bufio.Writer)
+}
+
+// Registry holds metric families and exposes them in the Prometheus text exposition format
+type Registry struct {
+	mutex      sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// register adds a metric family, written in registration order
+func (registry *Registry) register(metricCollector collector) {
+	registry.mutex.Lock()
+	registry.collectors = append(registry.collectors, metricCollector)
+	registry.mutex.Unlock()
+}
+
+// Write writes every registered metric family in the text exposition format
+func (registry *Registry) Write(writer io.Writer) error {
+	registry.mutex.Lock()
+	collectors := append([]collector(nil), registry.collectors...)
+	registry.mutex.Unlock()
+
+	bufferedWriter := bufio.NewWriter(writer)
+	for _, metricCollector := range collectors {
+		metricCollector.write(bufferedWriter)
+	}
+	return bufferedWriter.Flush()
+}
+
+// Handler serves the registry's metrics for Prometheus to scrape
+func (registry *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", ContentType)
+		registry.Write(writer)
+	})
+}
+
+// family holds the name, help text and label names shared by every series of a metric
+type family struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+}
+
+// seriesKey joins label values into the key of a series, panicking when the label count is wrong
+func (metricFamily *family) seriesKey(labelValues []string) string {
+	if len(labelValues) != len(metricFamily.labelNames) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", metricFamily.name, len(metricFamily.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, labelSeparator)
+}
+
+// writeHeader writes the HELP and TYPE lines of the family
+func (metricFamily *family) writeHeader(writer *bufio.Writer) {
+	fmt.Fprintf(writer, "# HELP %s %s\n", metricFamily.name, escapeHelp(metricFamily.help))
+	fmt.Fprintf(writer, "# TYPE %s %s\n", metricFamily.name, metricFamily.kind)
+}
+
+// labels formats the label set of a series, with optional extra name/value pairs appended
+func (metricFamily *family) labels(labelValues []string, extra ...string) string {
+	pairs := make([]string, 0, len(labelValues)+len(extra)/2)
+	for index, labelName := range metricFamily.labelNames {
+		pairs = append(pairs, labelName+`="`+escapeLabelValue(labelValues[index])+`"`)
+	}
+	for index := 0; index+1 < len(extra); index += 2 {
+		pairs = append(pairs, extra[index]+`="`+escapeLabelValue(extra[index+1])+`"`)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sortedKeys returns the keys of a series map in a stable order
+func sortedKeys[Series any](series map[string]Series) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitKey splits a series key back into label values
+func splitKey(key string, labelCount int) []string {
+	if labelCount == 0 {
+		return nil
+	}
+	return strings.Split(key, labelSeparator)
+}
+
+// formatValue formats a sample value as Prometheus expects
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// escapeLabelValue escapes backslashes, double quotes and newlines in a label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeHelp escapes backslashes and newlines in a help text
+func escapeHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// TestRegistryWrite tests the text exposition of counters and histograms
+func TestRegistryWrite(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounter("test_requests_total", "Total requests.", "route", "status")
+	histogram := registry.NewHistogram("test_duration_seconds", "Request latency.", []float64{1, 0.1})
+
+	counter.Inc("/b", "200")
+	counter.Add(2, "/a", "404")
+	counter.Inc("/b", "200")
+	histogram.Observe(0.05)
+	histogram.Observe(0.5)
+	histogram.Observe(3)
+
+	var output bytes.Buffer
+	if err := registry.Write(&output); err != nil {
+		t.Fatalf("Failed to write metrics: %v", err)
+	}
+
+	expected := `# HELP test_requests_total Total requests.
+# TYPE test_requests_total counter
+test_requests_total{route="/a",status="404"} 2
+test_requests_total{route="/b",status="200"} 2
+# HELP test_duration_seconds Request latency.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{le="0.1"} 1
+test_duration_seconds_bucket{le="1"} 2
+test_duration_seconds_bucket{le="+Inf"} 3
+test_duration_seconds_sum 3.55
+test_duration_seconds_count 3
+`
+	if output.String() != expected {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", output.String(), expected)
+	}
+}
+
+// TestRegistryWrite_EscapesLabelValues tests escaping of quotes, backslashes and newlines
+func TestRegistryWrite_EscapesLabelValues(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("test_total", "Test.", "category").Inc("a \"b\"\\\nc")
+
+	var output bytes.Buffer
+	registry.Write(&output)
+
+	if !strings.Contains(output.String(), `test_total{category="a \"b\"\\\nc"} 1`) {
+		t.Errorf("Expected the label value to be escaped, got:\n%s", output.String())
+	}
+}
+
+// TestCounter_WrongLabelCount tests that a mismatched number of label values panics
+func TestCounter_WrongLabelCount(t *testing.T) {
+	counter := NewRegistry().NewCounter("test_total", "Test.", "route")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a panic for a missing label value")
+		}
+	}()
+	counter.Inc()
+}
+
+// TestServiceMetrics tests recording requests and analyses and serving them
+func TestServiceMetrics(t *testing.T) {
+	serviceMetrics := NewServiceMetrics()
+
+	serviceMetrics.ObserveRequest("/api/v1/analyze", "POST", http.StatusOK, 20*time.Millisecond)
+	serviceMetrics.ObserveAnalysis(20, 5*time.Millisecond, []models.ImprovementArea{
+		{Category: "Vision", Priority: "HIGH"},
+		{Category: "Vision", Priority: "HIGH"},
+		{Category: "Deaths", Priority: "LOW"},
+	})
+
+	if value := serviceMetrics.ImprovementAreas.Value("Vision", "HIGH"); value != 2 {
+		t.Errorf("Expected 2 HIGH Vision areas, got %f", value)
+	}
+
+	responseRecorder := httptest.NewRecorder()
+	serviceMetrics.Registry.Handler().ServeHTTP(responseRecorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	if contentType := responseRecorder.Header().Get("Content-Type"); contentType != ContentType {
+		t.Errorf("Expected Content-Type %q, got %q", ContentType, contentType)
+	}
+
+	body := responseRecorder.Body.String()
+	for _, expectedLine := range []string{
+		`opgl_http_requests_total{route="/api/v1/analyze",method="POST",status="200"} 1`,
+		`opgl_analysis_matches_bucket{le="20"} 1`,
+		`opgl_improvement_areas_total{category="Deaths",priority="LOW"} 1`,
+	} {
+		if !strings.Contains(body, expectedLine) {
+			t.Errorf("Expected line %q in:\n%s", expectedLine, body)
+		}
+	}
+
+	// A nil ServiceMetrics records nothing and does not panic
+	var disabled *ServiceMetrics
+	disabled.ObserveRequest("/health", "POST", http.StatusOK, time.Millisecond)
+	disabled.ObserveAnalysis(1, time.Millisecond, nil)
+}
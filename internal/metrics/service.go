@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// MatchCountBuckets are upper bounds for the number of matches sent with an analysis
+var MatchCountBuckets = []float64{1, 5, 10, 20, 50, 100, 200, 500}
+
+// ServiceMetrics holds the metrics recorded by the cortex engine
+// Methods are safe to call on a nil ServiceMetrics, which records nothing
+type ServiceMetrics struct {
+	Registry *Registry
+	// HTTP requests by route template, method and status code
+	HTTPRequests *Counter
+	// HTTP request latency by route template, method and status code
+	HTTPRequestDuration *Histogram
+	// Time spent producing a single player analysis
+	AnalysisDuration *Histogram
+	// Number of matches received per analysis, before filtering and screening
+	AnalysisMatches *Histogram
+	// Improvement areas emitted by category and priority
+	ImprovementAreas *Counter
+}
+
+// NewServiceMetrics creates the cortex engine metrics in a new registry
+func NewServiceMetrics() *ServiceMetrics {
+	registry := NewRegistry()
+
+	return &ServiceMetrics{
+		Registry: registry,
+		HTTPRequests: registry.NewCounter(
+			"opgl_http_requests_total", "Total HTTP requests by route, method and status.",
+			"route", "method", "status"),
+		HTTPRequestDuration: registry.NewHistogram(
+			"opgl_http_request_duration_seconds", "HTTP request latency in seconds by route, method and status.",
+			DefaultDurationBuckets, "route", "method", "status"),
+		AnalysisDuration: registry.NewHistogram(
+			"opgl_analysis_duration_seconds", "Time spent analyzing a single player in seconds.",
+			DefaultDurationBuckets),
+		AnalysisMatches: registry.NewHistogram(
+			"opgl_analysis_matches", "Number of matches received per player analysis.",
+			MatchCountBuckets),
+		ImprovementAreas: registry.NewCounter(
+			"opgl_improvement_areas_total", "Improvement areas emitted by category and priority.",
+			"category", "priority"),
+	}
+}
+
+// ObserveRequest records a completed HTTP request
+func (serviceMetrics *ServiceMetrics) ObserveRequest(route string, method string, status int, duration time.Duration) {
+	if serviceMetrics == nil {
+		return
+	}
+
+	statusCode := strconv.Itoa(status)
+	serviceMetrics.HTTPRequests.Inc(route, method, statusCode)
+	serviceMetrics.HTTPRequestDuration.Observe(duration.Seconds(), route, method, statusCode)
+}
+
+// ObserveAnalysis records a completed player analysis and the improvement areas it emitted
+func (serviceMetrics *ServiceMetrics) ObserveAnalysis(matchCount int, duration time.Duration, improvementAreas []models.ImprovementArea) {
+	if serviceMetrics == nil {
+		return
+	}
+
+	serviceMetrics.AnalysisDuration.Observe(duration.Seconds())
+	serviceMetrics.AnalysisMatches.Observe(float64(matchCount))
+	for _, improvementArea := range improvementAreas {
+		serviceMetrics.ImprovementAreas.Inc(improvementArea.Category, improvementArea.Priority)
+	}
+}
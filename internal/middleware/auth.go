@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/auth"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
+)
+
+// AuthenticationMiddleware identifies the client behind the request's API key
+// A valid key stores the client in the request context and tags the request-scoped logger and span with its ID.
+// Requests without a valid key continue anonymously so open routes such as /health keep working even when a
+// client sends a stale key; RequireScope rejects them on the routes that need a client. It runs outside
+// LoggingMiddleware and MetricsMiddleware so their lines carry the client, and those rejections happen inside
+// them so they are logged and counted like any other response
+func AuthenticationMiddleware(keyStore *auth.KeyStore, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		key := auth.KeyFromRequest(request)
+		if key == "" {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		client, err := keyStore.Authenticate(key)
+		if err != nil {
+			next.ServeHTTP(writer, request)
+			return
+		}
+
+		next.ServeHTTP(writer, request.WithContext(withClient(request, client)))
+	})
+}
+
+// RequireScope rejects requests whose client lacks scope: 401 without a valid API key, 403 without the scope
+// It authenticates the request itself when AuthenticationMiddleware has not already done so
+func RequireScope(keyStore *auth.KeyStore, scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		client := auth.ClientFromContext(request.Context())
+		if client == nil {
+			key := auth.KeyFromRequest(request)
+			if key == "" {
+				writeUnauthenticated(writer, request, "An API key is required")
+				return
+			}
+
+			authenticatedClient, err := keyStore.Authenticate(key)
+			if err != nil {
+				requestctx.Logger(request.Context()).Warn().
+					Str("method", request.Method).
+					Str("path", request.URL.Path).
+					Msg("Request rejected: invalid or revoked API key")
+				writeUnauthenticated(writer, request, "Invalid or revoked API key")
+				return
+			}
+			client = authenticatedClient
+			request = request.WithContext(withClient(request, client))
+		}
+
+		if !client.HasScope(scope) {
+			apierror.Write(writer, request, apierror.New(http.StatusForbidden, apierror.CodeForbidden,
+				"API key of client "+client.ID+" lacks the "+scope+" scope"))
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// withClient returns the request context carrying client, with the client ID on the logger and span
+func withClient(request *http.Request, client *auth.Client) context.Context {
+	ctx := auth.WithClient(request.Context(), client)
+	tracing.SpanFromContext(ctx).SetAttribute("client.id", client.ID)
+
+	logger := requestctx.Logger(ctx).With().Str("client", client.ID).Logger()
+	return requestctx.WithLogger(ctx, logger)
+}
+
+// writeUnauthenticated answers 401 with a Bearer challenge
+func writeUnauthenticated(writer http.ResponseWriter, request *http.Request, message string) {
+	writer.Header().Set("WWW-Authenticate", `Bearer realm="opgl-cortex-engine"`)
+	apierror.Write(writer, request, apierror.New(http.StatusUnauthorized, apierror.CodeUnauthenticated, message))
+}
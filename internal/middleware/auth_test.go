@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/auth"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/metrics"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// newTestKeyStore creates a key store holding a single analyze-only partner key
+func newTestKeyStore(t *testing.T) *auth.KeyStore {
+	t.Helper()
+	keyStore, err := auth.NewKeyStore("", "partner-a:"+auth.HashKey("partner-key")+":analyze")
+	if err != nil {
+		t.Fatalf("Failed to create key store: %v", err)
+	}
+	return keyStore
+}
+
+// TestRequireScope tests the 401 and 403 responses and that authorized requests reach the handler
+func TestRequireScope(t *testing.T) {
+	keyStore := newTestKeyStore(t)
+
+	testCases := []struct {
+		name           string
+		key            string
+		scope          string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"granted scope", "partner-key", auth.ScopeAnalyze, http.StatusOK, ""},
+		{"missing key", "", auth.ScopeAnalyze, http.StatusUnauthorized, apierror.CodeUnauthenticated},
+		{"unknown key", "other-key", auth.ScopeAnalyze, http.StatusUnauthorized, apierror.CodeUnauthenticated},
+		{"missing scope", "partner-key", auth.ScopeBatch, http.StatusForbidden, apierror.CodeForbidden},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var clientID string
+			handler := RequireScope(keyStore, testCase.scope, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				clientID = auth.ClientFromContext(request.Context()).ID
+			}))
+
+			request, _ := http.NewRequest("POST", "/api/v1/analyze", nil)
+			if testCase.key != "" {
+				request.Header.Set("Authorization", "Bearer "+testCase.key)
+			}
+			responseRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(responseRecorder, request)
+
+			if responseRecorder.Code != testCase.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", testCase.expectedStatus, responseRecorder.Code)
+			}
+
+			if testCase.expectedCode == "" {
+				if clientID != "partner-a" {
+					t.Errorf("Expected the handler to see client partner-a, got %q", clientID)
+				}
+				return
+			}
+
+			var apiError apierror.Error
+			if err := json.NewDecoder(responseRecorder.Body).Decode(&apiError); err != nil || apiError.Code != testCase.expectedCode {
+				t.Errorf("Expected error code '%s', got %+v (%v)", testCase.expectedCode, apiError, err)
+			}
+			if unauthorized := testCase.expectedStatus == http.StatusUnauthorized; unauthorized != (responseRecorder.Header().Get("WWW-Authenticate") != "") {
+				t.Errorf("Expected a WWW-Authenticate challenge only on 401, got %q", responseRecorder.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+// TestAuthenticationMiddleware tests that anonymous requests and invalid keys pass through on open routes, invalid
+// keys are rejected, logged and counted on guarded routes, and the client ID is added to the request log lines
+func TestAuthenticationMiddleware(t *testing.T) {
+	var output bytes.Buffer
+	previousLogger := log.Logger
+	log.Logger = zerolog.New(&output)
+	defer func() { log.Logger = previousLogger }()
+
+	keyStore := newTestKeyStore(t)
+	router := mux.NewRouter()
+	router.HandleFunc("/health", func(writer http.ResponseWriter, request *http.Request) {}).Methods("POST")
+	router.Handle("/api/v1/analyze", RequireScope(keyStore, auth.ScopeAnalyze, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))).Methods("POST")
+	serviceMetrics := metrics.NewServiceMetrics()
+	handler := AuthenticationMiddleware(keyStore, LoggingMiddleware(MetricsMiddleware(serviceMetrics, router)))
+
+	request, _ := http.NewRequest("POST", "/health", nil)
+	responseRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+	if responseRecorder.Code != http.StatusOK {
+		t.Errorf("Expected anonymous requests to pass through, got %d", responseRecorder.Code)
+	}
+
+	request.Header.Set(auth.APIKeyHeader, "revoked-key")
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+	if responseRecorder.Code != http.StatusOK {
+		t.Errorf("Expected an invalid key to be ignored on an open route, got %d", responseRecorder.Code)
+	}
+
+	output.Reset()
+	request, _ = http.NewRequest("POST", "/api/v1/analyze", nil)
+	request.Header.Set(auth.APIKeyHeader, "revoked-key")
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+	if responseRecorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d for an invalid key, got %d", http.StatusUnauthorized, responseRecorder.Code)
+	}
+	if !strings.Contains(output.String(), `"status":401`) {
+		t.Errorf("Expected the rejection to be logged, got %s", output.String())
+	}
+	if value := serviceMetrics.HTTPRequests.Value("/api/v1/analyze", "POST", "401"); value != 1 {
+		t.Errorf("Expected the rejection to be counted once, got %v", value)
+	}
+
+	output.Reset()
+	request.Header.Set(auth.APIKeyHeader, "partner-key")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	for _, line := range lines {
+		if !strings.Contains(line, `"client":"partner-a"`) {
+			t.Errorf("Expected every line to carry the client ID, got %s", line)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+)
+
+// ForwardedForHeader lists the client and proxy addresses a request passed through
+const ForwardedForHeader = "X-Forwarded-For"
+
+// ParseTrustedProxies parses a comma-separated list of proxy IPs and CIDR ranges (e.g., "10.0.0.0/8, 192.0.2.10")
+func ParseTrustedProxies(value string) ([]*net.IPNet, error) {
+	var trustedProxies []*net.IPNet
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			trustedProxies = append(trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", item)
+		}
+		trustedProxies = append(trustedProxies, network)
+	}
+
+	return trustedProxies, nil
+}
+
+// ClientIPMiddleware resolves the originating client IP and stores it in the request context
+// X-Forwarded-For is only honoured when the connection comes from a trusted proxy: the header is walked from
+// the right and the first address that is not a trusted proxy is the client, so clients cannot spoof their IP
+// by sending the header themselves. Without trusted proxies the connection's remote address is used
+func ClientIPMiddleware(trustedProxies []*net.IPNet, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		clientIP := resolveClientIP(request, trustedProxies)
+		next.ServeHTTP(writer, request.WithContext(requestctx.WithClientIP(request.Context(), clientIP)))
+	})
+}
+
+// resolveClientIP returns the client IP of a request given the trusted proxies in front of the service
+func resolveClientIP(request *http.Request, trustedProxies []*net.IPNet) string {
+	clientIP := remoteHost(request)
+	if !isTrustedProxy(clientIP, trustedProxies) {
+		return clientIP
+	}
+
+	forwardedFor := strings.Split(strings.Join(request.Header.Values(ForwardedForHeader), ","), ",")
+	for index := len(forwardedFor) - 1; index >= 0; index-- {
+		address := strings.TrimSpace(forwardedFor[index])
+		if net.ParseIP(address) == nil {
+			// A malformed hop cannot be trusted; keep the last proxy-reported address
+			break
+		}
+
+		clientIP = address
+		if !isTrustedProxy(address, trustedProxies) {
+			break
+		}
+	}
+
+	return clientIP
+}
+
+// remoteHost returns the IP of the connection's remote address
+func remoteHost(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
+
+// isTrustedProxy reports whether address belongs to one of the trusted proxies
+func isTrustedProxy(address string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+)
+
+// TestParseTrustedProxies tests parsing IPs and CIDR ranges and rejecting invalid entries
+func TestParseTrustedProxies(t *testing.T) {
+	trustedProxies, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.10,2001:db8::1")
+	if err != nil || len(trustedProxies) != 3 {
+		t.Fatalf("Expected 3 trusted proxies, got %v (%v)", trustedProxies, err)
+	}
+
+	if !isTrustedProxy("10.1.2.3", trustedProxies) || !isTrustedProxy("192.0.2.10", trustedProxies) || isTrustedProxy("192.0.2.11", trustedProxies) {
+		t.Error("Expected only addresses inside the trusted ranges to be trusted")
+	}
+
+	if _, err := ParseTrustedProxies("10.0.0.0/8,gateway"); err == nil {
+		t.Error("Expected an error for an invalid entry")
+	}
+}
+
+// TestClientIPMiddleware tests that X-Forwarded-For is only honoured from trusted proxies
+func TestClientIPMiddleware(t *testing.T) {
+	trustedProxies, _ := ParseTrustedProxies("10.0.0.0/8")
+
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		expectedIP   string
+	}{
+		{"direct client", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"spoofed header from untrusted client", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"client behind gateway", "10.0.0.5:5000", "198.51.100.1", "198.51.100.1"},
+		{"spoofed hop before gateway", "10.0.0.5:5000", "1.2.3.4, 198.51.100.1, 10.0.0.9", "198.51.100.1"},
+		{"malformed hop", "10.0.0.5:5000", "not-an-ip", "10.0.0.5"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var clientIP string
+			handler := ClientIPMiddleware(trustedProxies, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				clientIP = requestctx.ClientIP(request.Context())
+			}))
+
+			request, _ := http.NewRequest("POST", "/api/v1/analyze", nil)
+			request.RemoteAddr = testCase.remoteAddr
+			if testCase.forwardedFor != "" {
+				request.Header.Set(ForwardedForHeader, testCase.forwardedFor)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			if clientIP != testCase.expectedIP {
+				t.Errorf("Expected client IP %s, got %s", testCase.expectedIP, clientIP)
+			}
+		})
+	}
+}
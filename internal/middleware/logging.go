@@ -4,8 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 )
 
 // responseWriter is a wrapper around http.ResponseWriter that captures the status code
@@ -29,15 +29,17 @@ func (rw *responseWriter) WriteHeader(statusCode int) {
 }
 
 // LoggingMiddleware logs HTTP requests with detailed information
+// Both lines use the request-scoped logger, so behind RequestIDMiddleware they share the request ID
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		startTime := time.Now()
+		logger := requestctx.Logger(request.Context())
 
 		// Wrap the response writer to capture status code
 		wrappedWriter := newResponseWriter(writer)
 
 		// Log incoming request
-		log.Info().
+		logger.Info().
 			Str("method", request.Method).
 			Str("path", request.URL.Path).
 			Str("remote_addr", request.RemoteAddr).
@@ -56,11 +58,11 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 
 		switch {
 		case statusCode >= 500:
-			logEvent = log.Error()
+			logEvent = logger.Error()
 		case statusCode >= 400:
-			logEvent = log.Warn()
+			logEvent = logger.Warn()
 		default:
-			logEvent = log.Info()
+			logEvent = logger.Info()
 		}
 
 		// Log request completion with details
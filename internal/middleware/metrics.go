@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/metrics"
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute labels requests that match no route, keeping arbitrary paths out of metric labels
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records request counts and latency by route template, method and status code
+func MetricsMiddleware(serviceMetrics *metrics.ServiceMetrics, router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		startTime := time.Now()
+
+		// Wrap the response writer to capture status code
+		wrappedWriter := newResponseWriter(writer)
+		router.ServeHTTP(wrappedWriter, request)
+
+		serviceMetrics.ObserveRequest(routeTemplate(router, request), request.Method, wrappedWriter.statusCode, time.Since(startTime))
+	})
+}
+
+// routeTemplate returns the path template of the route matching the request (e.g., /api/v1/players/{puuid}/stats)
+func routeTemplate(router *mux.Router, request *http.Request) string {
+	var routeMatch mux.RouteMatch
+	if !router.Match(request, &routeMatch) || routeMatch.Route == nil {
+		return unmatchedRoute
+	}
+
+	template, err := routeMatch.Route.GetPathTemplate()
+	if err != nil {
+		return unmatchedRoute
+	}
+	return template
+}
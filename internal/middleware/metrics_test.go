@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/metrics"
+	"github.com/gorilla/mux"
+)
+
+// TestMetricsMiddleware tests that requests are counted by route template and status
+func TestMetricsMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/players/{puuid}/stats", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+
+	serviceMetrics := metrics.NewServiceMetrics()
+	handler := MetricsMiddleware(serviceMetrics, router)
+
+	for _, path := range []string{"/api/v1/players/first/stats", "/api/v1/players/second/stats", "/unknown"} {
+		request, _ := http.NewRequest("GET", path, nil)
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	if value := serviceMetrics.HTTPRequests.Value("/api/v1/players/{puuid}/stats", "GET", "200"); value != 2 {
+		t.Errorf("Expected 2 requests on the stats route, got %f", value)
+	}
+
+	if value := serviceMetrics.HTTPRequests.Value(unmatchedRoute, "GET", "404"); value != 1 {
+		t.Errorf("Expected 1 unmatched request, got %f", value)
+	}
+
+	if count := serviceMetrics.HTTPRequestDuration.Count("/api/v1/players/{puuid}/stats", "GET", "200"); count != 2 {
+		t.Errorf("Expected 2 latency observations, got %d", count)
+	}
+}
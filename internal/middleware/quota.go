@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+)
+
+// DefaultMaxBodyBytes bounds the request bodies MatchQuotaMiddleware reads to count matches
+const DefaultMaxBodyBytes = 32 << 20
+
+// MatchQuota caps the number of matches each client may submit per UTC day
+type MatchQuota struct {
+	dailyMatches int
+	// Largest request body accepted on quota-limited routes
+	maxBodyBytes int64
+
+	mutex sync.Mutex
+	// Day the usage counts belong to, as the UTC midnight starting it
+	day time.Time
+	// Matches submitted today per client key
+	usage map[string]int
+	// Clock, replaced in tests
+	now func() time.Time
+}
+
+// QuotaDecision is the outcome of reserving matches against a client's quota
+type QuotaDecision struct {
+	Allowed bool
+	// Daily match limit
+	Limit int
+	// Matches the client may still submit today
+	Remaining int
+	// Time until the quota resets at UTC midnight
+	Reset time.Duration
+	// Day the matches were reserved on, so a refund after midnight does not touch the new day
+	day time.Time
+}
+
+// NewMatchQuota creates a quota of dailyMatches submitted matches per client per UTC day
+// Request bodies larger than maxBodyBytes are rejected; zero or less uses DefaultMaxBodyBytes
+func NewMatchQuota(dailyMatches int, maxBodyBytes int64) *MatchQuota {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+
+	return &MatchQuota{
+		dailyMatches: dailyMatches,
+		maxBodyBytes: maxBodyBytes,
+		usage:        make(map[string]int),
+		now:          time.Now,
+	}
+}
+
+// Reserve counts matches against the client's quota for today
+// Requests that would exceed the quota are rejected without using any of it
+func (matchQuota *MatchQuota) Reserve(clientKey string, matches int) QuotaDecision {
+	matchQuota.mutex.Lock()
+	defer matchQuota.mutex.Unlock()
+
+	now := matchQuota.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !today.Equal(matchQuota.day) {
+		// A new day resets every client
+		matchQuota.day = today
+		matchQuota.usage = make(map[string]int)
+	}
+
+	decision := QuotaDecision{Limit: matchQuota.dailyMatches, Reset: today.AddDate(0, 0, 1).Sub(now), day: today}
+
+	used := matchQuota.usage[clientKey]
+	if used+matches <= matchQuota.dailyMatches {
+		used += matches
+		matchQuota.usage[clientKey] = used
+		decision.Allowed = true
+	}
+
+	decision.Remaining = matchQuota.dailyMatches - used
+	return decision
+}
+
+// Refund returns the matches of an allowed decision to the client's quota, unless the day has changed since
+func (matchQuota *MatchQuota) Refund(clientKey string, decision QuotaDecision, matches int) {
+	if !decision.Allowed || matches == 0 {
+		return
+	}
+
+	matchQuota.mutex.Lock()
+	defer matchQuota.mutex.Unlock()
+
+	if !decision.day.Equal(matchQuota.day) {
+		return
+	}
+
+	if used := matchQuota.usage[clientKey] - matches; used > 0 {
+		matchQuota.usage[clientKey] = used
+	} else {
+		delete(matchQuota.usage, clientKey)
+	}
+}
+
+// MatchQuotaMiddleware counts the matches submitted in a request body against the client's daily quota
+// Matches are counted in "matches", "entries[].matches" and "players[].matches"; the body is restored for the
+// handler. Bodies over the quota's size limit get 413 PAYLOAD_TOO_LARGE. Responses carry X-RateLimit-Quota-Limit,
+// X-RateLimit-Quota-Remaining and X-RateLimit-Quota-Reset; a request that would exceed the quota gets
+// 429 QUOTA_EXCEEDED with Retry-After set to the next UTC midnight. Requests the handler rejects with a 4xx
+// status are refunded, so invalid payloads do not use up the quota
+func MatchQuotaMiddleware(matchQuota *MatchQuota, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var matches int
+		if request.Body != nil {
+			body, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, matchQuota.maxBodyBytes))
+			if err != nil {
+				var maxBytesError *http.MaxBytesError
+				if errors.As(err, &maxBytesError) {
+					message := fmt.Sprintf("Request body exceeds the maximum of %d bytes", maxBytesError.Limit)
+					apierror.Write(writer, request, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, message))
+					return
+				}
+				apierror.Write(writer, request, apierror.New(http.StatusBadRequest, apierror.CodeInvalidBody, "Invalid request body"))
+				return
+			}
+			request.Body = io.NopCloser(bytes.NewReader(body))
+			matches = countSubmittedMatches(body)
+		}
+
+		clientKey := ClientKey(request)
+		decision := matchQuota.Reserve(clientKey, matches)
+
+		writer.Header().Set("X-RateLimit-Quota-Limit", strconv.Itoa(decision.Limit))
+		writer.Header().Set("X-RateLimit-Quota-Remaining", strconv.Itoa(decision.Remaining))
+		writer.Header().Set("X-RateLimit-Quota-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+
+		if !decision.Allowed {
+			writer.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.Reset)))
+			message := fmt.Sprintf("Request submits %d matches but only %d of the daily quota of %d remain", matches, decision.Remaining, decision.Limit)
+			apierror.Write(writer, request, apierror.New(http.StatusTooManyRequests, apierror.CodeQuotaExceeded, message))
+			return
+		}
+
+		// Wrap the response writer to capture status code
+		wrappedWriter := newResponseWriter(writer)
+		next.ServeHTTP(wrappedWriter, request)
+
+		if wrappedWriter.statusCode >= http.StatusBadRequest && wrappedWriter.statusCode < http.StatusInternalServerError {
+			matchQuota.Refund(clientKey, decision, matches)
+		}
+	})
+}
+
+// countSubmittedMatches counts the matches of an analyze, ingestion, batch or compare body
+// Malformed bodies count as zero; the handler reports them as INVALID_BODY
+func countSubmittedMatches(body []byte) int {
+	type matchList struct {
+		Matches []json.RawMessage `json:"matches"`
+	}
+	var payload struct {
+		matchList
+		Entries []matchList `json:"entries"`
+		Players []matchList `json:"players"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return 0
+	}
+
+	matches := len(payload.Matches)
+	for _, entry := range payload.Entries {
+		matches += len(entry.Matches)
+	}
+	for _, player := range payload.Players {
+		matches += len(player.Matches)
+	}
+	return matches
+}
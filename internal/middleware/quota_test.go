@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+)
+
+// TestMatchQuota_Reserve tests that matches count against the daily quota and reset at UTC midnight
+func TestMatchQuota_Reserve(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)}
+	matchQuota := NewMatchQuota(100, 0)
+	matchQuota.now = clock.now
+
+	if decision := matchQuota.Reserve("client:a", 80); !decision.Allowed || decision.Remaining != 20 || decision.Reset != 6*time.Hour {
+		t.Fatalf("Expected 80 matches to be allowed with 20 left until midnight, got %+v", decision)
+	}
+
+	if decision := matchQuota.Reserve("client:a", 30); decision.Allowed || decision.Remaining != 20 {
+		t.Errorf("Expected an over-quota request to be rejected without using quota, got %+v", decision)
+	}
+
+	if decision := matchQuota.Reserve("client:b", 100); !decision.Allowed {
+		t.Error("Expected another client to have its own quota")
+	}
+
+	clock.current = time.Date(2024, 1, 2, 0, 0, 1, 0, time.UTC)
+	if decision := matchQuota.Reserve("client:a", 100); !decision.Allowed || decision.Remaining != 0 {
+		t.Errorf("Expected the quota to reset on the next day, got %+v", decision)
+	}
+}
+
+// TestCountSubmittedMatches tests counting matches across request shapes
+func TestCountSubmittedMatches(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"analyze", `{"summoner": {}, "matches": [{}, {}, {}]}`, 3},
+		{"batch", `{"entries": [{"matches": [{}, {}]}, {"matches": [{}]}]}`, 3},
+		{"compare", `{"players": [{"matches": [{}]}, {"matches": [{}, {}]}]}`, 3},
+		{"no matches", `{"lastN": 20}`, 0},
+		{"malformed", `{"matches": [`, 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if matches := countSubmittedMatches([]byte(testCase.body)); matches != testCase.expected {
+				t.Errorf("Expected %d matches, got %d", testCase.expected, matches)
+			}
+		})
+	}
+}
+
+// TestMatchQuotaMiddleware tests that the body reaches the handler and over-quota requests get 429
+func TestMatchQuotaMiddleware(t *testing.T) {
+	var handlerBody string
+	handler := MatchQuotaMiddleware(NewMatchQuota(3, 0), http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		handlerBody = string(body)
+	}))
+
+	body := `{"matches": [{}, {}]}`
+	request, _ := http.NewRequest("POST", "/api/v1/analyze", strings.NewReader(body))
+	responseRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusOK || handlerBody != body || responseRecorder.Header().Get("X-RateLimit-Quota-Remaining") != "1" {
+		t.Fatalf("Expected the body to reach the handler with 1 match left, got %d %q %v", responseRecorder.Code, handlerBody, responseRecorder.Header())
+	}
+
+	request, _ = http.NewRequest("POST", "/api/v1/analyze", strings.NewReader(body))
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusTooManyRequests || responseRecorder.Header().Get("Retry-After") == "" {
+		t.Fatalf("Expected 429 with Retry-After, got %d %v", responseRecorder.Code, responseRecorder.Header())
+	}
+	if !strings.Contains(responseRecorder.Body.String(), apierror.CodeQuotaExceeded) {
+		t.Errorf("Expected error code '%s', got %s", apierror.CodeQuotaExceeded, responseRecorder.Body.String())
+	}
+}
+
+// TestMatchQuotaMiddleware_BodyLimit tests that oversized bodies are rejected with 413 without using quota
+func TestMatchQuotaMiddleware_BodyLimit(t *testing.T) {
+	matchQuota := NewMatchQuota(10, 16)
+	handler := MatchQuotaMiddleware(matchQuota, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		t.Error("Expected the handler not to be called")
+	}))
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyze", strings.NewReader(`{"matches": [{}, {}, {}, {}]}`))
+	responseRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+
+	if responseRecorder.Code != http.StatusRequestEntityTooLarge || !strings.Contains(responseRecorder.Body.String(), apierror.CodePayloadTooLarge) {
+		t.Errorf("Expected 413 %s, got %d: %s", apierror.CodePayloadTooLarge, responseRecorder.Code, responseRecorder.Body.String())
+	}
+	if decision := matchQuota.Reserve(ClientKey(request), 0); decision.Remaining != 10 {
+		t.Errorf("Expected no quota to be used, got %d remaining", decision.Remaining)
+	}
+}
+
+// TestMatchQuotaMiddleware_RefundsRejectedRequests tests that requests failing with a 4xx status are refunded
+func TestMatchQuotaMiddleware_RefundsRejectedRequests(t *testing.T) {
+	matchQuota := NewMatchQuota(10, 0)
+	handler := MatchQuotaMiddleware(matchQuota, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusBadRequest)
+	}))
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyze", strings.NewReader(`{"matches": [{}, {}, {}]}`))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	if decision := matchQuota.Reserve(ClientKey(request), 0); decision.Remaining != 10 {
+		t.Errorf("Expected the rejected request to be refunded, got %d remaining", decision.Remaining)
+	}
+}
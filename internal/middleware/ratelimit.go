@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/auth"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+)
+
+// bucketSweepInterval is how often idle, fully refilled buckets are dropped so one-off IPs do not accumulate
+const bucketSweepInterval = time.Minute
+
+// RateLimiter is a token-bucket limiter keyed by client: each client may burst up to its capacity,
+// after which requests are admitted at the refill rate
+type RateLimiter struct {
+	// Bucket capacity, reported as X-RateLimit-Limit
+	capacity float64
+	// Tokens added per second
+	refillRate float64
+
+	mutex     sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	// Clock, replaced in tests
+	now func() time.Time
+}
+
+// tokenBucket is the state of a single client's bucket
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// RateLimitDecision is the outcome of taking a token for a request
+type RateLimitDecision struct {
+	Allowed bool
+	// Bucket capacity
+	Limit int
+	// Whole tokens left after this request
+	Remaining int
+	// Time until the bucket is full again
+	Reset time.Duration
+	// Time until the next token is available when the request was rejected
+	RetryAfter time.Duration
+}
+
+// NewRateLimiter creates a limiter admitting requestsPerMinute per client with bursts of up to burst requests
+// A burst of zero or less allows a full minute's worth of requests at once
+func NewRateLimiter(requestsPerMinute int, burst int) *RateLimiter {
+	if burst <= 0 {
+		burst = requestsPerMinute
+	}
+
+	return &RateLimiter{
+		capacity:   float64(burst),
+		refillRate: float64(requestsPerMinute) / 60,
+		buckets:    make(map[string]*tokenBucket),
+		now:        time.Now,
+	}
+}
+
+// Take removes a token from the client's bucket if one is available
+func (rateLimiter *RateLimiter) Take(clientKey string) RateLimitDecision {
+	rateLimiter.mutex.Lock()
+	defer rateLimiter.mutex.Unlock()
+
+	now := rateLimiter.now()
+	rateLimiter.sweep(now)
+
+	bucket, exists := rateLimiter.buckets[clientKey]
+	if !exists {
+		bucket = &tokenBucket{tokens: rateLimiter.capacity, updatedAt: now}
+		rateLimiter.buckets[clientKey] = bucket
+	}
+
+	// Refill for the time elapsed since the bucket was last touched
+	bucket.tokens = math.Min(rateLimiter.capacity, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rateLimiter.refillRate)
+	bucket.updatedAt = now
+
+	decision := RateLimitDecision{Limit: int(rateLimiter.capacity)}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = rateLimiter.refillTime(1 - bucket.tokens)
+	}
+
+	decision.Remaining = int(bucket.tokens)
+	decision.Reset = rateLimiter.refillTime(rateLimiter.capacity - bucket.tokens)
+	return decision
+}
+
+// refillTime returns how long the bucket takes to gain tokens
+func (rateLimiter *RateLimiter) refillTime(tokens float64) time.Duration {
+	return time.Duration(tokens / rateLimiter.refillRate * float64(time.Second))
+}
+
+// sweep drops buckets that have refilled completely since they were last used
+// Such buckets are indistinguishable from new ones, so dropping them loses no state
+func (rateLimiter *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rateLimiter.lastSweep) < bucketSweepInterval {
+		return
+	}
+	rateLimiter.lastSweep = now
+
+	for clientKey, bucket := range rateLimiter.buckets {
+		if bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rateLimiter.refillRate >= rateLimiter.capacity {
+			delete(rateLimiter.buckets, clientKey)
+		}
+	}
+}
+
+// RateLimitMiddleware limits requests per API client, or per remote IP for anonymous requests
+// Every response carries X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset (seconds until the
+// bucket is full); rejected requests get 429 RATE_LIMITED with Retry-After
+func RateLimitMiddleware(rateLimiter *RateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		decision := rateLimiter.Take(ClientKey(request))
+
+		writer.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+		writer.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		writer.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+
+		if !decision.Allowed {
+			writer.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+			apierror.Write(writer, request, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited,
+				"Rate limit exceeded, retry in "+strconv.Itoa(ceilSeconds(decision.RetryAfter))+"s"))
+			return
+		}
+
+		next.ServeHTTP(writer, request)
+	})
+}
+
+// ClientKey identifies who a request is limited as: the authenticated client, otherwise the client IP
+// resolved by ClientIPMiddleware, falling back to the connection's remote address
+func ClientKey(request *http.Request) string {
+	if client := auth.ClientFromContext(request.Context()); client != nil {
+		return "client:" + client.ID
+	}
+
+	if clientIP := requestctx.ClientIP(request.Context()); clientIP != "" {
+		return "ip:" + clientIP
+	}
+	return "ip:" + remoteHost(request)
+}
+
+// ceilSeconds rounds a duration up to whole seconds, with a minimum of one so clients never retry immediately
+func ceilSeconds(duration time.Duration) int {
+	seconds := int(math.Ceil(duration.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/auth"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+)
+
+// fakeClock is a settable clock for limiters and quotas
+type fakeClock struct {
+	current time.Time
+}
+
+// now returns the current fake time
+func (clock *fakeClock) now() time.Time {
+	return clock.current
+}
+
+// TestRateLimiter_Take tests bursting up to capacity, rejection with a retry time, and refilling
+func TestRateLimiter_Take(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	rateLimiter := NewRateLimiter(60, 3)
+	rateLimiter.now = clock.now
+
+	for request := 0; request < 3; request++ {
+		if decision := rateLimiter.Take("client:a"); !decision.Allowed || decision.Remaining != 2-request {
+			t.Fatalf("Expected burst request %d to be allowed with %d remaining, got %+v", request, 2-request, decision)
+		}
+	}
+
+	decision := rateLimiter.Take("client:a")
+	if decision.Allowed || decision.RetryAfter != time.Second || decision.Reset != 3*time.Second {
+		t.Errorf("Expected a rejection retrying in 1s and full in 3s, got %+v", decision)
+	}
+
+	if decision := rateLimiter.Take("client:b"); !decision.Allowed {
+		t.Error("Expected another client to have its own bucket")
+	}
+
+	clock.current = clock.current.Add(time.Second)
+	if decision := rateLimiter.Take("client:a"); !decision.Allowed || decision.Remaining != 0 {
+		t.Errorf("Expected one refilled token after a second, got %+v", decision)
+	}
+}
+
+// TestRateLimiter_SweepsIdleBuckets tests that fully refilled buckets are dropped
+func TestRateLimiter_SweepsIdleBuckets(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	rateLimiter := NewRateLimiter(60, 0)
+	rateLimiter.now = clock.now
+
+	rateLimiter.Take("ip:192.0.2.1")
+	clock.current = clock.current.Add(2 * bucketSweepInterval)
+	rateLimiter.Take("ip:192.0.2.2")
+
+	if _, exists := rateLimiter.buckets["ip:192.0.2.1"]; exists || len(rateLimiter.buckets) != 1 {
+		t.Errorf("Expected only the active bucket to remain, got %d buckets", len(rateLimiter.buckets))
+	}
+}
+
+// TestRateLimitMiddleware tests the rate limit headers and the 429 envelope
+func TestRateLimitMiddleware(t *testing.T) {
+	rateLimiter := NewRateLimiter(1, 1)
+	handler := RateLimitMiddleware(rateLimiter, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+
+	request, _ := http.NewRequest("POST", "/api/v1/analyze", nil)
+	request.RemoteAddr = "192.0.2.1:5000"
+
+	responseRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+	if responseRecorder.Code != http.StatusOK || responseRecorder.Header().Get("X-RateLimit-Limit") != "1" || responseRecorder.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("Expected an allowed request with rate limit headers, got %d %v", responseRecorder.Code, responseRecorder.Header())
+	}
+
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+	if responseRecorder.Code != http.StatusTooManyRequests || responseRecorder.Header().Get("Retry-After") != "60" {
+		t.Fatalf("Expected 429 retrying in 60s, got %d with Retry-After %q", responseRecorder.Code, responseRecorder.Header().Get("Retry-After"))
+	}
+
+	var apiError apierror.Error
+	if err := json.NewDecoder(responseRecorder.Body).Decode(&apiError); err != nil || apiError.Code != apierror.CodeRateLimited {
+		t.Errorf("Expected error code '%s', got %+v (%v)", apierror.CodeRateLimited, apiError, err)
+	}
+
+	// The same IP authenticated as a client is limited separately
+	authenticatedRequest := request.WithContext(auth.WithClient(request.Context(), &auth.Client{ID: "partner-a"}))
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, authenticatedRequest)
+	if responseRecorder.Code != http.StatusOK {
+		t.Errorf("Expected the client's own bucket to allow the request, got %d", responseRecorder.Code)
+	}
+}
+
+// TestClientKey tests keying requests by client ID, resolved client IP or remote IP
+func TestClientKey(t *testing.T) {
+	request, _ := http.NewRequest("POST", "/api/v1/analyze", nil)
+	request.RemoteAddr = "[2001:db8::1]:443"
+	if key := ClientKey(request); key != "ip:2001:db8::1" {
+		t.Errorf("Expected the remote IP without port, got %q", key)
+	}
+
+	request = request.WithContext(requestctx.WithClientIP(request.Context(), "198.51.100.1"))
+	if key := ClientKey(request); key != "ip:198.51.100.1" {
+		t.Errorf("Expected the resolved client IP, got %q", key)
+	}
+
+	request = request.WithContext(auth.WithClient(request.Context(), &auth.Client{ID: "partner-a"}))
+	if key := ClientKey(request); key != "client:partner-a" {
+		t.Errorf("Expected the client ID, got %q", key)
+	}
+}
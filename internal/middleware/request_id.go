@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+	"github.com/rs/zerolog/log"
+)
+
+// maxRequestIDLength bounds client-supplied request IDs so they stay readable in logs
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware honours the client's X-Request-ID or generates one, returns it in the response headers
+// and stores it in the request context along with a logger that tags every line with it
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requestID := request.Header.Get(requestctx.Header)
+		if !validRequestID(requestID) {
+			requestID = requestctx.NewID()
+		}
+
+		writer.Header().Set(requestctx.Header, requestID)
+
+		logger := log.Logger.With().Str("request_id", requestID).Logger()
+		ctx := requestctx.WithLogger(requestctx.WithRequestID(request.Context(), requestID), logger)
+
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	})
+}
+
+// validRequestID reports whether a client-supplied request ID is safe to reuse
+// Only printable ASCII without spaces is accepted, so IDs cannot forge log lines or headers
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for index := 0; index < len(requestID); index++ {
+		if requestID[index] <= ' ' || requestID[index] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// TestRequestIDMiddleware tests that client IDs are honoured, invalid ones replaced, and the ID stored in the context
+func TestRequestIDMiddleware(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clientID    string
+		expectReuse bool
+	}{
+		{"client ID honoured", "gateway-7f3a", true},
+		{"missing ID generated", "", false},
+		{"ID with spaces replaced", "forged id\nlevel=error", false},
+		{"overlong ID replaced", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var contextID string
+			handler := RequestIDMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				contextID = requestctx.RequestID(request.Context())
+			}))
+
+			request, _ := http.NewRequest("POST", "/health", nil)
+			if testCase.clientID != "" {
+				request.Header.Set(requestctx.Header, testCase.clientID)
+			}
+			responseRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(responseRecorder, request)
+
+			headerID := responseRecorder.Header().Get(requestctx.Header)
+			if headerID == "" || headerID != contextID {
+				t.Fatalf("Expected the response header and context to share an ID, got %q and %q", headerID, contextID)
+			}
+
+			if reused := headerID == testCase.clientID; reused != testCase.expectReuse {
+				t.Errorf("Expected reuse of the client ID to be %v, got ID %q", testCase.expectReuse, headerID)
+			}
+		})
+	}
+}
+
+// TestRequestIDMiddleware_CorrelatesLogLines tests that both request log lines carry the request ID
+func TestRequestIDMiddleware_CorrelatesLogLines(t *testing.T) {
+	var output bytes.Buffer
+	previousLogger := log.Logger
+	log.Logger = zerolog.New(&output)
+	defer func() { log.Logger = previousLogger }()
+
+	handler := RequestIDMiddleware(LoggingMiddleware(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requestctx.Logger(request.Context()).Info().Msg("Handler line")
+	})))
+
+	request, _ := http.NewRequest("POST", "/health", nil)
+	request.Header.Set(requestctx.Header, "correlate-me")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 log lines, got %d: %s", len(lines), output.String())
+	}
+
+	for _, line := range lines {
+		if !strings.Contains(line, `"request_id":"correlate-me"`) {
+			t.Errorf("Expected every line to carry the request ID, got %s", line)
+		}
+	}
+}
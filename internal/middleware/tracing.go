@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
+	"github.com/gorilla/mux"
+)
+
+// TracingMiddleware wraps each request in a server span named after its route template
+// An incoming W3C traceparent header makes the span a child of the caller's span, so the gateway's
+// trace continues through the service. The trace ID is added to the request-scoped logger
+func TracingMiddleware(tracer *tracing.Tracer, router *mux.Router, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx := request.Context()
+		if parent, ok := tracing.ParseTraceparent(request.Header.Get(tracing.TraceparentHeader)); ok {
+			ctx = tracing.ContextWithRemoteParent(ctx, parent)
+		}
+
+		route := routeTemplate(router, request)
+		ctx, span := tracer.Start(ctx, request.Method+" "+route, tracing.SpanKindServer)
+		defer span.End()
+
+		span.SetAttribute("http.method", request.Method)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.target", request.URL.Path)
+		if requestID := requestctx.RequestID(ctx); requestID != "" {
+			span.SetAttribute("request.id", requestID)
+		}
+
+		logger := requestctx.Logger(ctx).With().Str("trace_id", span.SpanContext().TraceID.String()).Logger()
+		ctx = requestctx.WithLogger(ctx, logger)
+
+		// Wrap the response writer to capture status code
+		wrappedWriter := newResponseWriter(writer)
+		next.ServeHTTP(wrappedWriter, request.WithContext(ctx))
+
+		span.SetAttribute("http.status_code", wrappedWriter.statusCode)
+		if wrappedWriter.statusCode >= http.StatusInternalServerError {
+			span.SetError(errorStatus(wrappedWriter.statusCode))
+		}
+	})
+}
+
+// errorStatus describes a server error status as an error for span status
+type errorStatus int
+
+// Error returns the status text
+func (status errorStatus) Error() string {
+	return http.StatusText(int(status))
+}
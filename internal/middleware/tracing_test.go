@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
+	"github.com/gorilla/mux"
+)
+
+// recordingExporter keeps exported spans in memory for tests
+type recordingExporter struct {
+	spans []*tracing.SpanData
+}
+
+func (recorder *recordingExporter) ExportSpan(spanData *tracing.SpanData) error {
+	recorder.spans = append(recorder.spans, spanData)
+	return nil
+}
+
+func (recorder *recordingExporter) Shutdown() error {
+	return nil
+}
+
+// TestTracingMiddleware tests that requests continue the caller's trace in a server span named after the route
+func TestTracingMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/players/{puuid}/stats", func(writer http.ResponseWriter, request *http.Request) {
+		_, span := tracing.Start(request.Context(), "storage.RecentMatches")
+		span.End()
+		writer.WriteHeader(http.StatusInternalServerError)
+	}).Methods("GET")
+
+	recorder := &recordingExporter{}
+	handler := TracingMiddleware(tracing.NewTracer(recorder), router, router)
+
+	request, _ := http.NewRequest("GET", "/api/v1/players/test-puuid/stats", nil)
+	request.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	if len(recorder.spans) != 2 {
+		t.Fatalf("Expected a handler span and a server span, got %d", len(recorder.spans))
+	}
+
+	storageSpan, serverSpan := recorder.spans[0], recorder.spans[1]
+	if serverSpan.Name != "GET /api/v1/players/{puuid}/stats" || serverSpan.Kind != tracing.SpanKindServer {
+		t.Errorf("Unexpected server span %+v", serverSpan)
+	}
+
+	if serverSpan.SpanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || serverSpan.ParentSpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected the server span to continue the caller's trace, got %+v", serverSpan.SpanContext)
+	}
+
+	if storageSpan.ParentSpanID != serverSpan.SpanContext.SpanID {
+		t.Errorf("Expected the storage span to be a child of the server span")
+	}
+
+	if serverSpan.Attributes["http.status_code"] != http.StatusInternalServerError || serverSpan.StatusCode != tracing.StatusError {
+		t.Errorf("Expected a failed server span with status 500, got %+v", serverSpan)
+	}
+
+	// Spans started without the middleware are no-ops
+	if _, span := tracing.Start(context.Background(), "untraced"); span != nil {
+		t.Error("Expected no span outside a traced request")
+	}
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/apierror"
+)
 
 // Summoner represents a League of Legends player account
 type Summoner struct {
@@ -16,6 +20,10 @@ type Summoner struct {
 	ProfileIconID int `json:"profileIconId"`
 	// Summoner level (non-ranked progression)
 	SummonerLevel int64 `json:"summonerLevel"`
+	// Ranked tier (IRON through CHALLENGER), empty when unranked
+	Tier string `json:"tier,omitempty"`
+	// Ranked division within the tier (IV, III, II, I), empty for apex tiers
+	Division string `json:"division,omitempty"`
 }
 
 // Match represents a single League of Legends match
@@ -30,8 +38,58 @@ type Match struct {
 	GameMode string `json:"gameMode"`
 	// Game type (e.g., MATCHED_GAME)
 	GameType string `json:"gameType"`
+	// Queue identifier (e.g., 420 for ranked solo/duo), 0 when unknown
+	QueueID int `json:"queueId,omitempty"`
+	// Game client version the match was played on (e.g., 14.23.636.4981)
+	GameVersion string `json:"gameVersion,omitempty"`
 	// List of all participants in the match
 	Participants []Participant `json:"participants"`
+	// Per-team objectives and bans (optional)
+	Teams []Team `json:"teams,omitempty"`
+}
+
+// Team represents one side of a match
+type Team struct {
+	// Team identifier (100 for blue side, 200 for red side)
+	TeamID int `json:"teamId"`
+	// Whether this team won the match
+	Win bool `json:"win"`
+	// Champions banned by this team
+	Bans []Ban `json:"bans"`
+	// Objectives taken by this team
+	Objectives Objectives `json:"objectives"`
+}
+
+// Ban represents a single champion ban
+type Ban struct {
+	// Banned champion ID (-1 when the ban was skipped)
+	ChampionID int `json:"championId"`
+	// Pick turn the ban was made in
+	PickTurn int `json:"pickTurn"`
+}
+
+// Objectives holds the objectives a team took during a match
+type Objectives struct {
+	// Baron Nashor kills
+	Baron Objective `json:"baron"`
+	// Champion kills (First marks first blood)
+	Champion Objective `json:"champion"`
+	// Dragon kills
+	Dragon Objective `json:"dragon"`
+	// Inhibitors destroyed
+	Inhibitor Objective `json:"inhibitor"`
+	// Rift Herald kills
+	RiftHerald Objective `json:"riftHerald"`
+	// Towers destroyed
+	Tower Objective `json:"tower"`
+}
+
+// Objective records whether a team took an objective first and how many times
+type Objective struct {
+	// Whether this team took the objective first
+	First bool `json:"first"`
+	// Number of times this team took the objective
+	Kills int `json:"kills"`
 }
 
 // Participant represents a player's performance in a specific match
@@ -40,6 +98,10 @@ type Participant struct {
 	PUUID string `json:"puuid"`
 	// Summoner name at the time of the match
 	SummonerName string `json:"summonerName"`
+	// Riot ID game name (the part before the #)
+	RiotIDGameName string `json:"riotIdGameName,omitempty"`
+	// Riot ID tagline (the part after the #)
+	RiotIDTagline string `json:"riotIdTagline,omitempty"`
 	// Champion ID played in this match
 	ChampionID int `json:"championId"`
 	// Champion name for easier reference
@@ -60,10 +122,18 @@ type Participant struct {
 	VisionScore int `json:"visionScore"`
 	// Creep score (minions and monsters killed)
 	TotalMinionsKilled int `json:"totalMinionsKilled"`
+	// Jungle monsters killed (already included in TotalMinionsKilled)
+	NeutralMinionsKilled int `json:"neutralMinionsKilled,omitempty"`
 	// Whether the player's team won the match
 	Win bool `json:"win"`
-	// Player's role in the match (TOP, JUNGLE, MID, BOT, SUPPORT)
+	// Player's role in the match (TOP, JUNGLE, MIDDLE, BOTTOM, UTILITY)
 	TeamPosition string `json:"teamPosition"`
+	// Team the player was on (100 for blue side, 200 for red side), 0 when unknown
+	TeamID int `json:"teamId,omitempty"`
+	// Position the player actually played as detected by the game, which may differ from TeamPosition
+	IndividualPosition string `json:"individualPosition,omitempty"`
+	// Whether the game ended in an early surrender (remake)
+	GameEndedInEarlySurrender bool `json:"gameEndedInEarlySurrender,omitempty"`
 }
 
 // PlayerStats represents aggregated statistics for a player
@@ -94,19 +164,140 @@ type PlayerStats struct {
 	AverageDamage float64 `json:"averageDamage"`
 	// Average gold earned per game
 	AverageGold float64 `json:"averageGold"`
+	// Share of team kills the player took part in ((kills + assists) / team kills) as a percentage
+	KillParticipation float64 `json:"killParticipation"`
+	// Share of the team's champion damage dealt by the player as a percentage
+	DamageShare float64 `json:"damageShare"`
+	// Share of the team's gold earned by the player as a percentage
+	GoldShare float64 `json:"goldShare"`
+	// Champion damage dealt per gold earned
+	DamagePerGold float64 `json:"damagePerGold"`
 	// Most played champions with count
 	ChampionPool map[string]int `json:"championPool"`
+	// Per-champion statistics breakdown keyed by champion name
+	ChampionStats map[string]ChampionStats `json:"championStats"`
 	// Role distribution (percentage of games in each role)
 	RoleDistribution map[string]float64 `json:"roleDistribution"`
+	// Per-role statistics breakdown keyed by normalized team position
+	RoleStats map[string]RoleStats `json:"roleStats"`
+	// Games from roles with too few games to be judged on their own and games without a known team position,
+	// judged against the default benchmarks (omitted when every game is judged under its own role)
+	PooledRoleStats *RoleStats `json:"pooledRoleStats,omitempty"`
+	// Win rate per map side keyed by BLUE or RED (games without a team ID are not counted)
+	SideStats map[string]SideStats `json:"sideStats"`
+	// Objective control of the player's team (omitted when matches carry no team data)
+	ObjectiveControl *ObjectiveControl `json:"objectiveControl,omitempty"`
+}
+
+// SideStats represents the player's results on one side of the map
+type SideStats struct {
+	// Map side (BLUE or RED)
+	Side string `json:"side"`
+	// Number of games played on this side
+	GamesPlayed int `json:"gamesPlayed"`
+	// Number of games won on this side
+	Wins int `json:"wins"`
+	// Win rate on this side as a percentage
+	WinRate float64 `json:"winRate"`
+}
+
+// ObjectiveControl summarizes the objectives taken by the player's team
+type ObjectiveControl struct {
+	// Number of games with team objective data
+	GamesWithData int `json:"gamesWithData"`
+	// Average dragons taken per game
+	AverageDragons float64 `json:"averageDragons"`
+	// Average barons taken per game
+	AverageBarons float64 `json:"averageBarons"`
+	// Average towers destroyed per game
+	AverageTowers float64 `json:"averageTowers"`
+	// Average Rift Heralds taken per game
+	AverageHeralds float64 `json:"averageHeralds"`
+	// Share of all dragons in the player's games taken by the player's team as a percentage
+	DragonControl float64 `json:"dragonControl"`
+	// Percentage of games where the player's team drew first blood
+	FirstBloodRate float64 `json:"firstBloodRate"`
+	// Percentage of games where the player's team took the first dragon
+	FirstDragonRate float64 `json:"firstDragonRate"`
+	// Percentage of games where the player's team destroyed the first tower
+	FirstTowerRate float64 `json:"firstTowerRate"`
+}
+
+// ChampionStats represents aggregated statistics for a single champion played by the player
+type ChampionStats struct {
+	// Champion name
+	ChampionName string `json:"championName"`
+	// Number of games played on this champion
+	GamesPlayed int `json:"gamesPlayed"`
+	// Number of games won on this champion
+	Wins int `json:"wins"`
+	// Win rate on this champion as a percentage
+	WinRate float64 `json:"winRate"`
+	// Average kills per game on this champion
+	AverageKills float64 `json:"averageKills"`
+	// Average deaths per game on this champion
+	AverageDeaths float64 `json:"averageDeaths"`
+	// Average assists per game on this champion
+	AverageAssists float64 `json:"averageAssists"`
+	// Kill/Death/Assist ratio on this champion
+	KDA float64 `json:"kda"`
+	// Average CS per minute on this champion
+	CSPerMinute float64 `json:"csPerMinute"`
+	// Average vision score per game on this champion
+	AverageVisionScore float64 `json:"averageVisionScore"`
+	// Average damage dealt to champions per game on this champion
+	AverageDamage float64 `json:"averageDamage"`
+	// Average gold earned per game on this champion
+	AverageGold float64 `json:"averageGold"`
+	// Role this champion was played in most often
+	PrimaryRole string `json:"primaryRole,omitempty"`
+}
+
+// RoleStats represents aggregated statistics for all games played in a single role
+type RoleStats struct {
+	// Normalized team position (TOP, JUNGLE, MIDDLE, BOTTOM, UTILITY)
+	Role string `json:"role"`
+	// Number of games played in this role
+	GamesPlayed int `json:"gamesPlayed"`
+	// Number of games won in this role
+	Wins int `json:"wins"`
+	// Win rate in this role as a percentage
+	WinRate float64 `json:"winRate"`
+	// Average kills per game in this role
+	AverageKills float64 `json:"averageKills"`
+	// Average deaths per game in this role
+	AverageDeaths float64 `json:"averageDeaths"`
+	// Average assists per game in this role
+	AverageAssists float64 `json:"averageAssists"`
+	// Kill/Death/Assist ratio in this role
+	KDA float64 `json:"kda"`
+	// Average CS per minute in this role
+	CSPerMinute float64 `json:"csPerMinute"`
+	// Average vision score per game in this role
+	AverageVisionScore float64 `json:"averageVisionScore"`
+	// Average damage dealt to champions per game in this role
+	AverageDamage float64 `json:"averageDamage"`
+	// Average gold earned per game in this role
+	AverageGold float64 `json:"averageGold"`
+	// Kill participation in this role as a percentage
+	KillParticipation float64 `json:"killParticipation"`
+	// Share of team champion damage in this role as a percentage
+	DamageShare float64 `json:"damageShare"`
+	// Share of team gold in this role as a percentage
+	GoldShare float64 `json:"goldShare"`
+	// Champion damage dealt per gold earned in this role
+	DamagePerGold float64 `json:"damagePerGold"`
 }
 
 // ImprovementArea represents a specific area where the player can improve
 type ImprovementArea struct {
 	// Category of improvement (e.g., "CS", "Vision", "Deaths", "Damage")
 	Category string `json:"category"`
+	// Metric or lane differential the area was judged on (e.g., csPerMinute, or cs for a lane matchup)
+	Metric string `json:"metric,omitempty"`
 	// Current performance metric value
 	CurrentValue float64 `json:"currentValue"`
-	// Average value for players at similar rank
+	// Benchmark value for the player's role and rank tier
 	ExpectedValue float64 `json:"expectedValue"`
 	// Difference between current and expected (negative means underperforming)
 	Gap float64 `json:"gap"`
@@ -114,14 +305,374 @@ type ImprovementArea struct {
 	Priority string `json:"priority"`
 	// Specific recommendation text for the player
 	Recommendation string `json:"recommendation"`
+	// Champion this improvement area applies to (empty when it covers all games)
+	Champion string `json:"champion,omitempty"`
+	// Role benchmark profile the current value was compared against
+	Role string `json:"role,omitempty"`
+	// Lane opponent champion this improvement area applies to
+	Opponent string `json:"opponent,omitempty"`
+	// Game mode of the matches this improvement area was derived from (ALL when modes are blended)
+	Mode string `json:"mode,omitempty"`
 }
 
 // AnalysisResult contains the complete analysis for a player
 type AnalysisResult struct {
 	// Player statistics summary
 	PlayerStats PlayerStats `json:"playerStats"`
+	// Game mode PlayerStats and the improvement areas are based on (ALL when modes are blended)
+	StatsMode string `json:"statsMode,omitempty"`
+	// Separate statistics per game mode (only when the analyzed matches span several modes)
+	ModeStats map[string]PlayerStats `json:"modeStats,omitempty"`
+	// Modes left out of the improvement areas, lane matchup, synergy and trends (only covered by ModeStats)
+	OtherModes []string `json:"otherModes,omitempty"`
 	// List of identified improvement areas
 	ImprovementAreas []ImprovementArea `json:"improvementAreas"`
+	// Benchmark table the improvement areas were compared against
+	Benchmark BenchmarkInfo `json:"benchmark"`
+	// Head-to-head comparison against the direct lane opponent (omitted when no opponent was found)
+	LaneMatchup *LaneMatchup `json:"laneMatchup,omitempty"`
+	// Recurring teammates and opponents (omitted when no player appears in more than one game)
+	Synergy *SynergyAnalysis `json:"synergy,omitempty"`
+	// Recent-versus-older performance trends (omitted when there are too few games)
+	Trends *TrendAnalysis `json:"trends,omitempty"`
+	// Matches left out of the analysis as remakes or abnormal games
+	ExcludedMatches []ExcludedMatch `json:"excludedMatches,omitempty"`
+	// Invalid input dropped by lenient request validation
+	Warnings []ValidationWarning `json:"warnings,omitempty"`
 	// Timestamp of when the analysis was performed
 	AnalyzedAt time.Time `json:"analyzedAt"`
 }
+
+// ValidationWarning describes invalid input that was dropped instead of rejected
+type ValidationWarning struct {
+	// JSON pointer to the dropped input within the request body (e.g., /matches/3)
+	Pointer string `json:"pointer"`
+	// Description of what was wrong and what was dropped
+	Message string `json:"message"`
+}
+
+// ExcludedMatch records a match that was screened out before analysis
+type ExcludedMatch struct {
+	// Match identifier
+	MatchID string `json:"matchId"`
+	// Reason the match was excluded (e.g., remake, player_afk)
+	Reason string `json:"reason"`
+}
+
+// LaneMatchup compares the player with the enemy playing the same position
+// Differentials are the player's value minus the opponent's (positive means ahead)
+type LaneMatchup struct {
+	// Game mode of the compared games (ALL when modes are blended)
+	Mode string `json:"mode"`
+	// Number of games where a lane opponent was found
+	GamesCompared int `json:"gamesCompared"`
+	// Average creep score differential at the end of the game
+	AverageCSDifferential float64 `json:"averageCsDifferential"`
+	// Average gold differential at the end of the game
+	AverageGoldDifferential float64 `json:"averageGoldDifferential"`
+	// Average champion damage differential
+	AverageDamageDifferential float64 `json:"averageDamageDifferential"`
+	// Average vision score differential
+	AverageVisionDifferential float64 `json:"averageVisionDifferential"`
+	// Per-opponent-champion breakdown ordered by games played
+	Opponents []OpponentStats `json:"opponents"`
+}
+
+// OpponentStats summarizes the games against a single lane opponent champion
+type OpponentStats struct {
+	// Opponent champion name
+	ChampionName string `json:"championName"`
+	// Number of games against this champion
+	GamesPlayed int `json:"gamesPlayed"`
+	// Number of games won against this champion
+	Wins int `json:"wins"`
+	// Win rate against this champion as a percentage
+	WinRate float64 `json:"winRate"`
+	// Average creep score differential against this champion
+	AverageCSDifferential float64 `json:"averageCsDifferential"`
+	// Average gold differential against this champion
+	AverageGoldDifferential float64 `json:"averageGoldDifferential"`
+	// Average champion damage differential against this champion
+	AverageDamageDifferential float64 `json:"averageDamageDifferential"`
+	// Average vision score differential against this champion
+	AverageVisionDifferential float64 `json:"averageVisionDifferential"`
+}
+
+// SynergyAnalysis lists the players who appear with or against the summoner in several games
+type SynergyAnalysis struct {
+	// Game mode of the games considered (ALL when modes are blended)
+	Mode string `json:"mode"`
+	// Recurring teammates ordered by games together
+	Teammates []PlayerSynergy `json:"teammates"`
+	// Recurring opponents ordered by games against
+	Opponents []PlayerSynergy `json:"opponents"`
+}
+
+// PlayerSynergy summarizes the games shared with another player
+type PlayerSynergy struct {
+	// Other player's PUUID
+	PUUID string `json:"puuid"`
+	// Other player's most recent summoner name
+	SummonerName string `json:"summonerName"`
+	// Number of games shared with this player
+	GamesPlayed int `json:"gamesPlayed"`
+	// Number of those games the summoner won
+	Wins int `json:"wins"`
+	// Summoner's win rate in those games as a percentage
+	WinRate float64 `json:"winRate"`
+	// WinRate minus the summoner's overall win rate
+	WinRateDelta float64 `json:"winRateDelta"`
+	// Summoner's KDA in those games
+	PlayerKDA float64 `json:"playerKda"`
+	// Other player's KDA in those games
+	OtherKDA float64 `json:"otherKda"`
+	// Combined KDA of the summoner and a teammate (omitted for opponents)
+	CombinedKDA float64 `json:"combinedKda,omitempty"`
+	// Average champion damage of the summoner and a teammate together (omitted for opponents)
+	AverageCombinedDamage float64 `json:"averageCombinedDamage,omitempty"`
+	// Champions the other player used, with counts
+	Champions map[string]int `json:"champions"`
+}
+
+// TrendAnalysis compares the player's most recent games with the games before them
+type TrendAnalysis struct {
+	// Game mode of the games in both windows (ALL when modes are blended)
+	Mode string `json:"mode"`
+	// Number of games in each comparison window
+	WindowSize int `json:"windowSize"`
+	// Start of the older window
+	OlderWindowStart time.Time `json:"olderWindowStart"`
+	// Start of the recent window
+	RecentWindowStart time.Time `json:"recentWindowStart"`
+	// Per-metric trends (win rate, KDA, CS/min, vision, deaths)
+	Metrics []MetricTrend `json:"metrics"`
+}
+
+// MetricTrend describes how a single metric moved between the older and recent windows
+type MetricTrend struct {
+	// Metric identifier (e.g., csPerMinute, deaths)
+	Metric string `json:"metric"`
+	// Average over the older window
+	OlderValue float64 `json:"olderValue"`
+	// Average over the recent window
+	RecentValue float64 `json:"recentValue"`
+	// RecentValue minus OlderValue
+	Change float64 `json:"change"`
+	// improving, declining or stable
+	Trend string `json:"trend"`
+}
+
+// BatchAnalysisResult contains the analyses of a batch request keyed by PUUID
+type BatchAnalysisResult struct {
+	// Successful analyses keyed by PUUID
+	Results map[string]*AnalysisResult `json:"results"`
+	// Failed entries keyed by PUUID (or entries[index] when the PUUID is missing or duplicated)
+	// Detail pointers are relative to the batch request body (e.g., /entries/1/summoner/tier)
+	Errors map[string]*apierror.Error `json:"errors"`
+}
+
+// StoredAnalysis is an analysis result persisted in the analysis history
+type StoredAnalysis struct {
+	// Identifier of the stored analysis, ordered by analysis time
+	ID string `json:"id"`
+	// PUUID of the analyzed player
+	PUUID string `json:"puuid"`
+	// Timestamp of when the analysis was performed
+	AnalyzedAt time.Time `json:"analyzedAt"`
+	// The full analysis result
+	Result *AnalysisResult `json:"result"`
+}
+
+// AnalysisHistory is a page of a player's stored analyses, newest first
+type AnalysisHistory struct {
+	// PUUID of the player
+	PUUID string `json:"puuid"`
+	// Stored analyses on this page
+	Analyses []StoredAnalysis `json:"analyses"`
+	// Cursor for the next (older) page; empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// IngestResult reports the outcome of pushing matches into a player's stored match history
+type IngestResult struct {
+	// PUUID of the player
+	PUUID string `json:"puuid"`
+	// Number of matches accepted after validation
+	Received int `json:"received"`
+	// Number of matches that were new and appended to the history
+	Added int `json:"added"`
+	// Number of matches already in the history, which were ignored
+	Duplicates int `json:"duplicates"`
+	// New matches left out of the running aggregates (remakes, AFK games, ...)
+	ExcludedMatches []ExcludedMatch `json:"excludedMatches,omitempty"`
+	// Invalid matches or participants dropped by lenient validation
+	Warnings []ValidationWarning `json:"warnings,omitempty"`
+	// All-time statistics over every stored match, including the new ones
+	AllTimeStats
+}
+
+// AllTimeStats is a player's statistics over the whole stored match history, separated by game mode
+type AllTimeStats struct {
+	// Statistics of the most played mode, as in an analysis with modes separated
+	PlayerStats PlayerStats `json:"playerStats"`
+	// Game mode PlayerStats covers
+	StatsMode string `json:"statsMode,omitempty"`
+	// Separate statistics per game mode (only when the history spans several modes)
+	ModeStats map[string]PlayerStats `json:"modeStats,omitempty"`
+}
+
+// AnalysisDiff describes what changed between two analyses of a player
+type AnalysisDiff struct {
+	// Timestamp of the earlier analysis
+	BaseAnalyzedAt time.Time `json:"baseAnalyzedAt"`
+	// Timestamp of the later analysis
+	TargetAnalyzedAt time.Time `json:"targetAnalyzedAt"`
+	// Per-metric changes in PlayerStats
+	Metrics []MetricDelta `json:"metrics"`
+	// Improvement areas present in the base analysis but not in the target
+	ResolvedAreas []ImprovementArea `json:"resolvedAreas"`
+	// Improvement areas present in the target analysis but not in the base
+	NewAreas []ImprovementArea `json:"newAreas"`
+	// Improvement areas whose priority rose between the analyses
+	EscalatedAreas []ImprovementAreaChange `json:"escalatedAreas"`
+	// Improvement areas whose priority fell between the analyses
+	DeescalatedAreas []ImprovementAreaChange `json:"deescalatedAreas"`
+	// Changes in the share of games per champion (percentage points)
+	ChampionShifts []PoolShift `json:"championShifts"`
+	// Changes in the share of games per role (percentage points)
+	RoleShifts []PoolShift `json:"roleShifts"`
+}
+
+// MetricDelta is the change of a single metric between two analyses
+type MetricDelta struct {
+	// Metric identifier (e.g., csPerMinute)
+	Metric string `json:"metric"`
+	// Display category of the metric
+	Category string `json:"category"`
+	// Value in the base analysis
+	BaseValue float64 `json:"baseValue"`
+	// Value in the target analysis
+	TargetValue float64 `json:"targetValue"`
+	// TargetValue minus BaseValue
+	Change float64 `json:"change"`
+	// improved, declined or unchanged, taking the metric's direction into account
+	Trend string `json:"trend"`
+}
+
+// ImprovementAreaChange is an improvement area whose priority changed
+type ImprovementAreaChange struct {
+	// The improvement area as it appears in the target analysis
+	Area ImprovementArea `json:"area"`
+	// Priority in the base analysis
+	PreviousPriority string `json:"previousPriority"`
+}
+
+// PoolShift is the change of a champion's or role's share of games
+type PoolShift struct {
+	// Champion or role name
+	Name string `json:"name"`
+	// Share of games in the base analysis (percentage)
+	BaseShare float64 `json:"baseShare"`
+	// Share of games in the target analysis (percentage)
+	TargetShare float64 `json:"targetShare"`
+	// TargetShare minus BaseShare
+	Change float64 `json:"change"`
+	// added, dropped or changed
+	Status string `json:"status"`
+}
+
+// ComparisonEntry is one player's summoner data and match history in a comparison
+type ComparisonEntry struct {
+	// Summoner being compared
+	Summoner *Summoner `json:"summoner"`
+	// Match history for the summoner
+	Matches []Match `json:"matches"`
+	// Filters and mode blending applied to this player's matches
+	Options AnalysisOptions `json:"options"`
+}
+
+// ComparisonResult puts two or more players side by side
+type ComparisonResult struct {
+	// Player statistics in request order
+	Players []PlayerStats `json:"players"`
+	// Game mode each player's statistics cover by PUUID (ALL when the player's modes are blended)
+	StatsModes map[string]string `json:"statsModes,omitempty"`
+	// Per-metric comparison in rule configuration order
+	Metrics []MetricComparison `json:"metrics"`
+	// Matches that appear in more than one player's history
+	SharedGames []SharedGame `json:"sharedGames,omitempty"`
+	// One-line summary of who leads the most categories
+	Summary string `json:"summary"`
+	// Invalid input dropped by lenient request validation
+	Warnings []ValidationWarning `json:"warnings,omitempty"`
+	// Timestamp of when the comparison was performed
+	ComparedAt time.Time `json:"comparedAt"`
+}
+
+// MetricComparison compares a single metric across players
+type MetricComparison struct {
+	// Metric identifier (e.g., csPerMinute, deaths)
+	Metric string `json:"metric"`
+	// Category name of the metric
+	Category string `json:"category"`
+	// Whether a lower value is better (e.g., deaths)
+	LowerIsBetter bool `json:"lowerIsBetter"`
+	// Metric value per PUUID
+	Values map[string]float64 `json:"values"`
+	// Difference from the first player's value per PUUID
+	Deltas map[string]float64 `json:"deltas"`
+	// PUUID of the player leading this metric (empty on a tie)
+	Leader string `json:"leader"`
+}
+
+// SharedGame is a match found in more than one compared player's history
+type SharedGame struct {
+	// Match identifier
+	MatchID string `json:"matchId"`
+	// PUUIDs of the compared players who played in the match
+	PUUIDs []string `json:"puuids"`
+	// Whether all of those players were on the same team
+	SameTeam bool `json:"sameTeam"`
+}
+
+// BenchmarkInfo describes which benchmark table was used for an analysis
+type BenchmarkInfo struct {
+	// Version of the tier benchmark table
+	Version string `json:"version"`
+	// Player's current ranked tier (empty when unranked)
+	Tier string `json:"tier,omitempty"`
+	// Player's current ranked division (empty for apex tiers)
+	Division string `json:"division,omitempty"`
+	// Tier whose benchmarks were applied (the next tier up for the climb goal)
+	TargetTier string `json:"targetTier"`
+	// Analysis goal that influenced the benchmark selection
+	Goal string `json:"goal,omitempty"`
+}
+
+// AnalysisOptions contains request-level settings that tune an analysis
+type AnalysisOptions struct {
+	// Analysis goal; "climb" compares the player against the next tier up
+	Goal string `json:"goal,omitempty"`
+	// Restricts which matches are analyzed
+	Filters AnalysisFilters `json:"filters,omitempty"`
+	// Analyze every game mode together instead of only the most played mode
+	BlendModes bool `json:"blendModes,omitempty"`
+	// How invalid matches are handled: "strict" rejects the request, "lenient" drops them with a warning
+	ValidationMode string `json:"validationMode,omitempty"`
+}
+
+// AnalysisFilters restricts the matches included in an analysis; empty fields do not filter
+type AnalysisFilters struct {
+	// Game modes to include (e.g., CLASSIC, ARAM)
+	GameModes []string `json:"gameModes,omitempty"`
+	// Queue IDs to include (e.g., 420 for ranked solo/duo)
+	QueueIDs []int `json:"queueIds,omitempty"`
+	// Earliest game creation time to include
+	From *time.Time `json:"from,omitempty"`
+	// Latest game creation time to include
+	To *time.Time `json:"to,omitempty"`
+	// Champions the player must have played
+	Champions []string `json:"champions,omitempty"`
+	// Roles the player must have played (TOP, JUNGLE, MIDDLE, BOTTOM, UTILITY)
+	Roles []string `json:"roles,omitempty"`
+}
@@ -0,0 +1,58 @@
+package requestctx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// TestRequestID tests storing and reading a request ID
+func TestRequestID(t *testing.T) {
+	if requestID := RequestID(context.Background()); requestID != "" {
+		t.Errorf("Expected no request ID in an empty context, got %q", requestID)
+	}
+
+	ctx := WithRequestID(context.Background(), "abc123")
+	if requestID := RequestID(ctx); requestID != "abc123" {
+		t.Errorf("Expected request ID abc123, got %q", requestID)
+	}
+}
+
+// TestClientIP tests storing and reading the client IP
+func TestClientIP(t *testing.T) {
+	if clientIP := ClientIP(context.Background()); clientIP != "" {
+		t.Errorf("Expected no client IP in an empty context, got %q", clientIP)
+	}
+
+	ctx := WithClientIP(context.Background(), "203.0.113.7")
+	if clientIP := ClientIP(ctx); clientIP != "203.0.113.7" {
+		t.Errorf("Expected client IP 203.0.113.7, got %q", clientIP)
+	}
+}
+
+// TestLogger tests that the request-scoped logger is returned, with the global logger as fallback
+func TestLogger(t *testing.T) {
+	if Logger(context.Background()) == nil {
+		t.Fatal("Expected the global logger as fallback")
+	}
+
+	var output bytes.Buffer
+	ctx := WithLogger(context.Background(), zerolog.New(&output).With().Str("request_id", "abc123").Logger())
+	Logger(ctx).Info().Msg("hello")
+
+	if !strings.Contains(output.String(), `"request_id":"abc123"`) {
+		t.Errorf("Expected the request-scoped logger to be used, got %q", output.String())
+	}
+}
+
+// TestNewID tests that generated IDs are 32 hex characters and distinct
+func TestNewID(t *testing.T) {
+	first, second := NewID(), NewID()
+
+	if len(first) != 32 || first == second {
+		t.Errorf("Expected two distinct 32-character IDs, got %q and %q", first, second)
+	}
+}
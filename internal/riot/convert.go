@@ -0,0 +1,161 @@
+package riot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// FormatRiotV5 identifies raw Riot match-v5 payloads in the analyze request
+const FormatRiotV5 = "riot-v5"
+
+// invalidPosition is the position Riot reports when it could not determine one
+const invalidPosition = "Invalid"
+
+// ConvertMatches converts raw match-v5 DTOs into analysis matches
+// The error identifies the first match that could not be converted
+func ConvertMatches(matchDTOs []MatchDTO) ([]models.Match, error) {
+	matches := make([]models.Match, 0, len(matchDTOs))
+
+	for index, matchDTO := range matchDTOs {
+		match, err := ConvertMatch(matchDTO)
+		if err != nil {
+			return nil, fmt.Errorf("matches[%d]: %w", index, err)
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+// ConvertMatch converts a single raw match-v5 DTO into an analysis match
+func ConvertMatch(matchDTO MatchDTO) (models.Match, error) {
+	if len(matchDTO.Info.Participants) == 0 {
+		return models.Match{}, fmt.Errorf("match %q has no participants in info", matchDTO.Metadata.MatchID)
+	}
+
+	if matchDTO.Info.GameDuration < 0 {
+		return models.Match{}, fmt.Errorf("match %q has a negative gameDuration", matchDTO.Metadata.MatchID)
+	}
+
+	participants := make([]models.Participant, 0, len(matchDTO.Info.Participants))
+	for _, participantDTO := range matchDTO.Info.Participants {
+		participants = append(participants, convertParticipant(participantDTO))
+	}
+
+	teams := make([]models.Team, 0, len(matchDTO.Info.Teams))
+	for _, teamDTO := range matchDTO.Info.Teams {
+		teams = append(teams, convertTeam(teamDTO))
+	}
+
+	return models.Match{
+		MatchID:      matchDTO.Metadata.MatchID,
+		GameCreation: gameCreationTime(matchDTO.Info.GameCreation),
+		GameDuration: gameDurationSeconds(matchDTO.Info),
+		GameMode:     matchDTO.Info.GameMode,
+		GameType:     matchDTO.Info.GameType,
+		QueueID:      matchDTO.Info.QueueID,
+		GameVersion:  matchDTO.Info.GameVersion,
+		Participants: participants,
+		Teams:        teams,
+	}, nil
+}
+
+// gameCreationTime converts gameCreation from Unix milliseconds
+// A missing (0) gameCreation becomes the zero time so validation reports it instead of dating the game to 1970
+func gameCreationTime(gameCreation int64) time.Time {
+	if gameCreation <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(gameCreation).UTC()
+}
+
+// gameDurationSeconds returns the game length in seconds
+// Before patch 11.20 gameDuration was reported in milliseconds and gameEndTimestamp was absent
+func gameDurationSeconds(info InfoDTO) int {
+	if info.GameEndTimestamp == 0 {
+		return int(info.GameDuration / 1000)
+	}
+	return int(info.GameDuration)
+}
+
+// convertParticipant converts a match-v5 participant
+func convertParticipant(participantDTO ParticipantDTO) models.Participant {
+	return models.Participant{
+		PUUID:                       participantDTO.PUUID,
+		SummonerName:                displayName(participantDTO),
+		RiotIDGameName:              participantDTO.RiotIDGameName,
+		RiotIDTagline:               participantDTO.RiotIDTagline,
+		ChampionID:                  participantDTO.ChampionID,
+		ChampionName:                participantDTO.ChampionName,
+		Kills:                       participantDTO.Kills,
+		Deaths:                      participantDTO.Deaths,
+		Assists:                     participantDTO.Assists,
+		GoldEarned:                  participantDTO.GoldEarned,
+		TotalDamageDealtToChampions: participantDTO.TotalDamageDealtToChampions,
+		TotalDamageTaken:            participantDTO.TotalDamageTaken,
+		VisionScore:                 participantDTO.VisionScore,
+		// Riot counts lane minions and jungle monsters separately; creep score includes both
+		TotalMinionsKilled:        participantDTO.TotalMinionsKilled + participantDTO.NeutralMinionsKilled,
+		NeutralMinionsKilled:      participantDTO.NeutralMinionsKilled,
+		Win:                       participantDTO.Win,
+		TeamPosition:              position(participantDTO),
+		TeamID:                    participantDTO.TeamID,
+		IndividualPosition:        validPosition(participantDTO.IndividualPosition),
+		GameEndedInEarlySurrender: participantDTO.GameEndedInEarlySurrender,
+	}
+}
+
+// displayName prefers the Riot ID game name, since summoner names are empty for newer accounts
+func displayName(participantDTO ParticipantDTO) string {
+	if participantDTO.RiotIDGameName != "" {
+		return participantDTO.RiotIDGameName
+	}
+	return participantDTO.SummonerName
+}
+
+// position returns the team position, falling back to the detected individual position
+// Riot leaves teamPosition empty in some queues and reports "Invalid" when it cannot tell
+func position(participantDTO ParticipantDTO) string {
+	if teamPosition := validPosition(participantDTO.TeamPosition); teamPosition != "" {
+		return teamPosition
+	}
+	return validPosition(participantDTO.IndividualPosition)
+}
+
+// validPosition returns the position unless it is empty or Riot's "Invalid" placeholder
+func validPosition(position string) string {
+	if strings.EqualFold(position, invalidPosition) {
+		return ""
+	}
+	return position
+}
+
+// convertTeam converts a match-v5 team
+func convertTeam(teamDTO TeamDTO) models.Team {
+	bans := make([]models.Ban, 0, len(teamDTO.Bans))
+	for _, banDTO := range teamDTO.Bans {
+		bans = append(bans, models.Ban{ChampionID: banDTO.ChampionID, PickTurn: banDTO.PickTurn})
+	}
+
+	return models.Team{
+		TeamID: teamDTO.TeamID,
+		Win:    teamDTO.Win,
+		Bans:   bans,
+		Objectives: models.Objectives{
+			Baron:      convertObjective(teamDTO.Objectives.Baron),
+			Champion:   convertObjective(teamDTO.Objectives.Champion),
+			Dragon:     convertObjective(teamDTO.Objectives.Dragon),
+			Inhibitor:  convertObjective(teamDTO.Objectives.Inhibitor),
+			RiftHerald: convertObjective(teamDTO.Objectives.RiftHerald),
+			Tower:      convertObjective(teamDTO.Objectives.Tower),
+		},
+	}
+}
+
+// convertObjective converts a match-v5 objective
+func convertObjective(objectiveDTO ObjectiveDTO) models.Objective {
+	return models.Objective{First: objectiveDTO.First, Kills: objectiveDTO.Kills}
+}
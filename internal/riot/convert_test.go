@@ -0,0 +1,167 @@
+package riot
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// riotMatchJSON is a trimmed match-v5 payload from a patch after 11.20
+const riotMatchJSON = `{
+  "metadata": {"dataVersion": "2", "matchId": "EUW1_7000000001", "participants": ["puuid-1", "puuid-2"]},
+  "info": {
+    "gameCreation": 1700000000000,
+    "gameDuration": 1845,
+    "gameEndTimestamp": 1700001900000,
+    "gameMode": "CLASSIC",
+    "gameType": "MATCHED_GAME",
+    "gameVersion": "13.22.541.9804",
+    "queueId": 420,
+    "participants": [
+      {
+        "puuid": "puuid-1", "summonerName": "", "riotIdGameName": "Faker", "riotIdTagline": "KR1",
+        "championId": 103, "championName": "Ahri", "kills": 7, "deaths": 2, "assists": 9,
+        "goldEarned": 12500, "totalDamageDealtToChampions": 24000, "totalDamageTaken": 15000,
+        "visionScore": 28, "totalMinionsKilled": 210, "neutralMinionsKilled": 12, "win": true,
+        "teamPosition": "MIDDLE", "individualPosition": "MIDDLE", "teamId": 100, "gameEndedInEarlySurrender": false
+      },
+      {
+        "puuid": "puuid-2", "summonerName": "OldName", "championId": 64, "championName": "LeeSin",
+        "kills": 3, "deaths": 6, "assists": 4, "goldEarned": 9000, "totalMinionsKilled": 30,
+        "neutralMinionsKilled": 140, "win": false, "teamPosition": "", "individualPosition": "JUNGLE", "teamId": 200
+      }
+    ],
+    "teams": [
+      {"teamId": 100, "win": true, "bans": [{"championId": 157, "pickTurn": 1}],
+       "objectives": {"baron": {"first": true, "kills": 1}, "champion": {"first": true, "kills": 30}, "dragon": {"first": false, "kills": 2},
+                      "inhibitor": {"first": true, "kills": 1}, "riftHerald": {"first": true, "kills": 1}, "tower": {"first": true, "kills": 8}}},
+      {"teamId": 200, "win": false, "bans": [{"championId": -1, "pickTurn": 6}],
+       "objectives": {"dragon": {"first": true, "kills": 1}, "tower": {"first": false, "kills": 3}}}
+    ]
+  }
+}`
+
+// decodeMatchDTO decodes a match-v5 payload or fails the test
+func decodeMatchDTO(t *testing.T, payload string) MatchDTO {
+	t.Helper()
+
+	var matchDTO MatchDTO
+	if err := json.Unmarshal([]byte(payload), &matchDTO); err != nil {
+		t.Fatalf("Failed to decode match-v5 payload: %v", err)
+	}
+	return matchDTO
+}
+
+// TestConvertMatch tests converting a current match-v5 payload
+func TestConvertMatch(t *testing.T) {
+	match, err := ConvertMatch(decodeMatchDTO(t, riotMatchJSON))
+	if err != nil {
+		t.Fatalf("Expected conversion to succeed, got %v", err)
+	}
+
+	if match.MatchID != "EUW1_7000000001" {
+		t.Errorf("Expected match ID 'EUW1_7000000001', got '%s'", match.MatchID)
+	}
+
+	if !match.GameCreation.Equal(time.UnixMilli(1700000000000)) {
+		t.Errorf("Expected gameCreation from epoch milliseconds, got %v", match.GameCreation)
+	}
+
+	if match.GameDuration != 1845 {
+		t.Errorf("Expected gameDuration 1845 seconds, got %d", match.GameDuration)
+	}
+
+	if match.QueueID != 420 || match.GameVersion != "13.22.541.9804" {
+		t.Errorf("Expected queue and version to be kept, got %d and '%s'", match.QueueID, match.GameVersion)
+	}
+
+	mid := match.Participants[0]
+	if mid.SummonerName != "Faker" || mid.RiotIDTagline != "KR1" {
+		t.Errorf("Expected Riot ID name to be used, got '%s#%s'", mid.SummonerName, mid.RiotIDTagline)
+	}
+
+	if mid.TotalMinionsKilled != 222 || mid.NeutralMinionsKilled != 12 {
+		t.Errorf("Expected creep score 222 including 12 monsters, got %d (%d)", mid.TotalMinionsKilled, mid.NeutralMinionsKilled)
+	}
+
+	if mid.TeamID != 100 || mid.TeamPosition != "MIDDLE" {
+		t.Errorf("Expected blue-side MIDDLE, got team %d position '%s'", mid.TeamID, mid.TeamPosition)
+	}
+
+	jungle := match.Participants[1]
+	if jungle.SummonerName != "OldName" {
+		t.Errorf("Expected summoner name fallback 'OldName', got '%s'", jungle.SummonerName)
+	}
+
+	if jungle.TeamPosition != "JUNGLE" {
+		t.Errorf("Expected individualPosition fallback 'JUNGLE', got '%s'", jungle.TeamPosition)
+	}
+
+	if len(match.Teams) != 2 || match.Teams[0].Objectives.Tower.Kills != 8 || !match.Teams[0].Objectives.Champion.First {
+		t.Errorf("Expected team objectives to be converted, got %+v", match.Teams)
+	}
+
+	if match.Teams[1].Bans[0].ChampionID != -1 {
+		t.Errorf("Expected skipped ban to be kept, got %+v", match.Teams[1].Bans)
+	}
+}
+
+// TestConvertMatch_MillisecondDuration tests gameDuration in milliseconds on patches before 11.20
+func TestConvertMatch_MillisecondDuration(t *testing.T) {
+	matchDTO := decodeMatchDTO(t, riotMatchJSON)
+	matchDTO.Info.GameEndTimestamp = 0
+	matchDTO.Info.GameDuration = 1845000
+
+	match, err := ConvertMatch(matchDTO)
+	if err != nil {
+		t.Fatalf("Expected conversion to succeed, got %v", err)
+	}
+
+	if match.GameDuration != 1845 {
+		t.Errorf("Expected gameDuration 1845 seconds, got %d", match.GameDuration)
+	}
+}
+
+// TestConvertMatch_MissingGameCreation tests that a missing gameCreation is left as the zero time
+func TestConvertMatch_MissingGameCreation(t *testing.T) {
+	matchDTO := decodeMatchDTO(t, riotMatchJSON)
+	matchDTO.Info.GameCreation = 0
+
+	match, err := ConvertMatch(matchDTO)
+	if err != nil {
+		t.Fatalf("Expected conversion to succeed, got %v", err)
+	}
+
+	if !match.GameCreation.IsZero() {
+		t.Errorf("Expected the zero gameCreation, got %v", match.GameCreation)
+	}
+}
+
+// TestConvertMatch_InvalidPosition tests that Riot's "Invalid" position placeholder is dropped
+func TestConvertMatch_InvalidPosition(t *testing.T) {
+	matchDTO := decodeMatchDTO(t, riotMatchJSON)
+	matchDTO.Info.Participants[1].IndividualPosition = "Invalid"
+
+	match, err := ConvertMatch(matchDTO)
+	if err != nil {
+		t.Fatalf("Expected conversion to succeed, got %v", err)
+	}
+
+	if match.Participants[1].TeamPosition != "" || match.Participants[1].IndividualPosition != "" {
+		t.Errorf("Expected no position, got '%s' / '%s'", match.Participants[1].TeamPosition, match.Participants[1].IndividualPosition)
+	}
+}
+
+// TestConvertMatches_NoParticipants tests that a match without participants is rejected with its index
+func TestConvertMatches_NoParticipants(t *testing.T) {
+	matchDTOs := []MatchDTO{decodeMatchDTO(t, riotMatchJSON), {Metadata: MetadataDTO{MatchID: "EUW1_2"}}}
+
+	_, err := ConvertMatches(matchDTOs)
+	if err == nil {
+		t.Fatal("Expected conversion to fail")
+	}
+
+	if err.Error() != `matches[1]: match "EUW1_2" has no participants in info` {
+		t.Errorf("Unexpected error message: %s", err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+package riot
+
+// MatchDTO is a match as returned by Riot's match-v5 API (/lol/match/v5/matches/{matchId})
+type MatchDTO struct {
+	// Match metadata (match ID and participant PUUIDs)
+	Metadata MetadataDTO `json:"metadata"`
+	// Match details
+	Info InfoDTO `json:"info"`
+}
+
+// MetadataDTO holds the match-v5 metadata block
+type MetadataDTO struct {
+	// Match data version
+	DataVersion string `json:"dataVersion"`
+	// Match identifier (e.g., EUW1_1234567890)
+	MatchID string `json:"matchId"`
+	// PUUIDs of the participants in participant order
+	Participants []string `json:"participants"`
+}
+
+// InfoDTO holds the match-v5 info block
+type InfoDTO struct {
+	// Epoch milliseconds when the game was created in the game server
+	GameCreation int64 `json:"gameCreation"`
+	// Game length; seconds when GameEndTimestamp is present, milliseconds on patches before 11.20
+	GameDuration int64 `json:"gameDuration"`
+	// Epoch milliseconds when the game ended (absent before patch 11.20)
+	GameEndTimestamp int64 `json:"gameEndTimestamp"`
+	// Game mode (e.g., CLASSIC, ARAM)
+	GameMode string `json:"gameMode"`
+	// Game type (e.g., MATCHED_GAME)
+	GameType string `json:"gameType"`
+	// Game client version
+	GameVersion string `json:"gameVersion"`
+	// Queue identifier
+	QueueID int `json:"queueId"`
+	// Participant details
+	Participants []ParticipantDTO `json:"participants"`
+	// Team objectives and bans
+	Teams []TeamDTO `json:"teams"`
+}
+
+// ParticipantDTO holds the match-v5 participant fields used by the analysis
+type ParticipantDTO struct {
+	PUUID                       string `json:"puuid"`
+	SummonerName                string `json:"summonerName"`
+	RiotIDGameName              string `json:"riotIdGameName"`
+	RiotIDTagline               string `json:"riotIdTagline"`
+	ChampionID                  int    `json:"championId"`
+	ChampionName                string `json:"championName"`
+	Kills                       int    `json:"kills"`
+	Deaths                      int    `json:"deaths"`
+	Assists                     int    `json:"assists"`
+	GoldEarned                  int    `json:"goldEarned"`
+	TotalDamageDealtToChampions int    `json:"totalDamageDealtToChampions"`
+	TotalDamageTaken            int    `json:"totalDamageTaken"`
+	VisionScore                 int    `json:"visionScore"`
+	TotalMinionsKilled          int    `json:"totalMinionsKilled"`
+	NeutralMinionsKilled        int    `json:"neutralMinionsKilled"`
+	Win                         bool   `json:"win"`
+	TeamPosition                string `json:"teamPosition"`
+	IndividualPosition          string `json:"individualPosition"`
+	TeamID                      int    `json:"teamId"`
+	GameEndedInEarlySurrender   bool   `json:"gameEndedInEarlySurrender"`
+}
+
+// TeamDTO holds a match-v5 team
+type TeamDTO struct {
+	TeamID     int           `json:"teamId"`
+	Win        bool          `json:"win"`
+	Bans       []BanDTO      `json:"bans"`
+	Objectives ObjectivesDTO `json:"objectives"`
+}
+
+// BanDTO holds a match-v5 ban
+type BanDTO struct {
+	ChampionID int `json:"championId"`
+	PickTurn   int `json:"pickTurn"`
+}
+
+// ObjectivesDTO holds the match-v5 team objectives
+type ObjectivesDTO struct {
+	Baron      ObjectiveDTO `json:"baron"`
+	Champion   ObjectiveDTO `json:"champion"`
+	Dragon     ObjectiveDTO `json:"dragon"`
+	Inhibitor  ObjectiveDTO `json:"inhibitor"`
+	RiftHerald ObjectiveDTO `json:"riftHerald"`
+	Tower      ObjectiveDTO `json:"tower"`
+}
+
+// ObjectiveDTO holds a single match-v5 objective
+type ObjectiveDTO struct {
+	First bool `json:"first"`
+	Kills int  `json:"kills"`
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// statsAccumulator sums a player's per-game totals for a subset of games (a champion, a role, ...)
+type statsAccumulator struct {
+	games             int
+	wins              int
+	kills             int
+	deaths            int
+	assists           int
+	creepScore        int
+	visionScore       int
+	damage            int
+	gold              int
+	gameDurationTotal int
+	roleCounts        map[string]int
+
+	// Team totals and the player's contribution, only for games where teammates were present
+	teamKills         int
+	teamDamage        int
+	teamGold          int
+	contributedKills  int
+	contributedDamage int
+	contributedGold   int
+}
+
+// newStatsAccumulator creates an empty statsAccumulator
+func newStatsAccumulator() *statsAccumulator {
+	return &statsAccumulator{
+		roleCounts: make(map[string]int),
+	}
+}
+
+// add records a single game
+func (accumulator *statsAccumulator) add(match *models.Match, participant *models.Participant) {
+	gameDuration := match.GameDuration
+
+	accumulator.games++
+	accumulator.kills += participant.Kills
+	accumulator.deaths += participant.Deaths
+	accumulator.assists += participant.Assists
+	accumulator.creepScore += participant.TotalMinionsKilled
+	accumulator.visionScore += participant.VisionScore
+	accumulator.damage += participant.TotalDamageDealtToChampions
+	accumulator.gold += participant.GoldEarned
+	accumulator.gameDurationTotal += gameDuration
+
+	if participant.Win {
+		accumulator.wins++
+	}
+
+	if role := normalizeRole(participant.TeamPosition); role != "" {
+		accumulator.roleCounts[role]++
+	}
+
+	if team, hasTeammates := teamTotalsFor(match, participant); hasTeammates {
+		accumulator.teamKills += team.kills
+		accumulator.teamDamage += team.damage
+		accumulator.teamGold += team.gold
+		accumulator.contributedKills += participant.Kills + participant.Assists
+		accumulator.contributedDamage += participant.TotalDamageDealtToChampions
+		accumulator.contributedGold += participant.GoldEarned
+	}
+}
+
+// merge adds another accumulator's totals to this one
+func (accumulator *statsAccumulator) merge(other *statsAccumulator) {
+	accumulator.games += other.games
+	accumulator.wins += other.wins
+	accumulator.kills += other.kills
+	accumulator.deaths += other.deaths
+	accumulator.assists += other.assists
+	accumulator.creepScore += other.creepScore
+	accumulator.visionScore += other.visionScore
+	accumulator.damage += other.damage
+	accumulator.gold += other.gold
+	accumulator.gameDurationTotal += other.gameDurationTotal
+	accumulator.teamKills += other.teamKills
+	accumulator.teamDamage += other.teamDamage
+	accumulator.teamGold += other.teamGold
+	accumulator.contributedKills += other.contributedKills
+	accumulator.contributedDamage += other.contributedDamage
+	accumulator.contributedGold += other.contributedGold
+
+	for role, count := range other.roleCounts {
+		accumulator.roleCounts[role] += count
+	}
+}
+
+// average divides a total by the number of games recorded
+func (accumulator *statsAccumulator) average(total int) float64 {
+	if accumulator.games == 0 {
+		return 0
+	}
+	return float64(total) / float64(accumulator.games)
+}
+
+// winRate returns the win rate as a percentage
+func (accumulator *statsAccumulator) winRate() float64 {
+	return accumulator.average(accumulator.wins) * 100.0
+}
+
+// kda returns the Kill/Death/Assist ratio over the recorded games
+func (accumulator *statsAccumulator) kda() float64 {
+	// Avoid division by zero
+	if accumulator.deaths == 0 {
+		return accumulator.average(accumulator.kills + accumulator.assists)
+	}
+	return float64(accumulator.kills+accumulator.assists) / float64(accumulator.deaths)
+}
+
+// killParticipation returns the share of team kills the player took part in as a percentage
+func (accumulator *statsAccumulator) killParticipation() float64 {
+	return percentageOf(accumulator.contributedKills, accumulator.teamKills)
+}
+
+// damageShare returns the player's share of team champion damage as a percentage
+func (accumulator *statsAccumulator) damageShare() float64 {
+	return percentageOf(accumulator.contributedDamage, accumulator.teamDamage)
+}
+
+// goldShare returns the player's share of team gold as a percentage
+func (accumulator *statsAccumulator) goldShare() float64 {
+	return percentageOf(accumulator.contributedGold, accumulator.teamGold)
+}
+
+// damagePerGold returns champion damage dealt per gold earned
+func (accumulator *statsAccumulator) damagePerGold() float64 {
+	if accumulator.gold <= 0 {
+		return 0
+	}
+	return float64(accumulator.damage) / float64(accumulator.gold)
+}
+
+// csPerMinute derives CS per minute from totals so longer games weigh proportionally
+func (accumulator *statsAccumulator) csPerMinute() float64 {
+	if accumulator.gameDurationTotal <= 0 {
+		return 0
+	}
+	return float64(accumulator.creepScore) / (float64(accumulator.gameDurationTotal) / 60.0)
+}
+
+// primaryRole returns the role recorded most often (ties broken alphabetically)
+func (accumulator *statsAccumulator) primaryRole() string {
+	roles := make([]string, 0, len(accumulator.roleCounts))
+	for role := range accumulator.roleCounts {
+		roles = append(roles, role)
+	}
+
+	sort.Slice(roles, func(left int, right int) bool {
+		if accumulator.roleCounts[roles[left]] != accumulator.roleCounts[roles[right]] {
+			return accumulator.roleCounts[roles[left]] > accumulator.roleCounts[roles[right]]
+		}
+		return roles[left] < roles[right]
+	})
+
+	if len(roles) == 0 {
+		return ""
+	}
+	return roles[0]
+}
+
+// percentageOf returns part as a percentage of total, or 0 when the total is empty
+func percentageOf(part int, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100.0
+}
+
+// playerStatsAccumulator holds the running totals behind PlayerStats so games can be added one at a time
+type playerStatsAccumulator struct {
+	puuid string
+	// Matches recorded, including any the player does not appear in
+	matchCount   int
+	overall      *statsAccumulator
+	championPool map[string]int
+	champions    map[string]*statsAccumulator
+	roles        map[string]*statsAccumulator
+	// Games without a known team position
+	unassigned       *statsAccumulator
+	roleDistribution map[string]int
+	sides            map[string]*statsAccumulator
+	objectives       *objectiveAccumulator
+}
+
+// newPlayerStatsAccumulator creates an empty playerStatsAccumulator for a player
+func newPlayerStatsAccumulator(puuid string) *playerStatsAccumulator {
+	return &playerStatsAccumulator{
+		puuid:            puuid,
+		overall:          newStatsAccumulator(),
+		championPool:     make(map[string]int),
+		champions:        make(map[string]*statsAccumulator),
+		roles:            make(map[string]*statsAccumulator),
+		unassigned:       newStatsAccumulator(),
+		roleDistribution: make(map[string]int),
+		sides:            make(map[string]*statsAccumulator),
+		objectives:       &objectiveAccumulator{},
+	}
+}
+
+// add records a single match
+func (accumulator *playerStatsAccumulator) add(match *models.Match) {
+	accumulator.matchCount++
+
+	participant := findParticipant(match, accumulator.puuid)
+	if participant == nil {
+		return
+	}
+
+	// Overall totals, including team-relative totals (kill participation, damage and gold share)
+	accumulator.overall.add(match, participant)
+
+	// Track champion pool and per-champion totals
+	accumulator.championPool[participant.ChampionName]++
+	subsetAccumulator(accumulator.champions, participant.ChampionName).add(match, participant)
+
+	// Track role distribution
+	if participant.TeamPosition != "" {
+		accumulator.roleDistribution[participant.TeamPosition]++
+	}
+
+	// Track per-role totals for role-aware benchmarks
+	if role := normalizeRole(participant.TeamPosition); role != "" {
+		subsetAccumulator(accumulator.roles, role).add(match, participant)
+	} else {
+		accumulator.unassigned.add(match, participant)
+	}
+
+	// Track side win rates and team objective control
+	if side := sideForTeam(participant.TeamID); side != "" {
+		subsetAccumulator(accumulator.sides, side).add(match, participant)
+	}
+	accumulator.objectives.add(match, participant)
+}
+
+// pooledRoles returns the totals of games not judged under their own role: games from roles
+// with too few games and games without a known team position
+func (accumulator *playerStatsAccumulator) pooledRoles() *statsAccumulator {
+	pooled := newStatsAccumulator()
+	pooled.merge(accumulator.unassigned)
+	for _, roleAccumulator := range accumulator.roles {
+		if !isJudgedRole(roleAccumulator.games, len(accumulator.roles)) {
+			pooled.merge(roleAccumulator)
+		}
+	}
+	return pooled
+}
+
+// subsetAccumulator returns the accumulator for a key, creating it when missing
+func subsetAccumulator(accumulators map[string]*statsAccumulator, key string) *statsAccumulator {
+	accumulator, exists := accumulators[key]
+	if !exists {
+		accumulator = newStatsAccumulator()
+		accumulators[key] = accumulator
+	}
+	return accumulator
+}
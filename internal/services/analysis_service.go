@@ -1,113 +1,257 @@
 package services
 
 import (
-	"math"
+	"context"
+	"errors"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/metrics"
 	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
 )
 
 // AnalysisService performs player performance analysis
-type AnalysisService struct{}
+type AnalysisService struct {
+	// Guards ruleConfig and rules so they can be reloaded or extended while analyses run
+	mutex sync.RWMutex
+	// Active benchmarks, gap bands and recommendations
+	ruleConfig *RuleConfig
+	// Rule file the configuration was loaded from (empty when using the built-in rules)
+	rulesPath string
+	// Rules evaluated for every analysis, in registration order
+	rules []Rule
+	// Analysis duration, match counts and emitted improvement areas; nil records nothing
+	serviceMetrics *metrics.ServiceMetrics
+}
 
-// NewAnalysisService creates a new AnalysisService instance
+// NewAnalysisService creates a new AnalysisService instance using the built-in rules
 func NewAnalysisService() *AnalysisService {
-	return &AnalysisService{}
+	return &AnalysisService{
+		ruleConfig: DefaultRuleConfig(),
+		rules:      builtInRules(),
+	}
+}
+
+// NewAnalysisServiceWithRules creates a new AnalysisService that loads its rules from a YAML or JSON file
+// An invalid file is rejected with the full list of validation problems
+func NewAnalysisServiceWithRules(rulesPath string) (*AnalysisService, error) {
+	ruleConfig, err := LoadRuleConfig(rulesPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnalysisService{
+		ruleConfig: ruleConfig,
+		rulesPath:  rulesPath,
+		rules:      builtInRules(),
+	}, nil
+}
+
+// ReloadRules re-reads the rule file and swaps in the new configuration
+// The active configuration is kept when the file is missing or invalid
+func (analysisService *AnalysisService) ReloadRules() (string, error) {
+	if analysisService.rulesPath == "" {
+		return "", errors.New("no rule file configured; the service is using the built-in rules")
+	}
+
+	ruleConfig, err := LoadRuleConfig(analysisService.rulesPath)
+	if err != nil {
+		return "", err
+	}
+
+	analysisService.mutex.Lock()
+	analysisService.ruleConfig = ruleConfig
+	analysisService.mutex.Unlock()
+
+	return ruleConfig.Version, nil
+}
+
+// currentRuleConfig returns the active rule configuration
+func (analysisService *AnalysisService) currentRuleConfig() *RuleConfig {
+	analysisService.mutex.RLock()
+	defer analysisService.mutex.RUnlock()
+	return analysisService.ruleConfig
+}
+
+// RegisterRule adds a rule that is evaluated after the built-in rules on every analysis
+func (analysisService *AnalysisService) RegisterRule(rule Rule) {
+	analysisService.mutex.Lock()
+	analysisService.rules = append(analysisService.rules, rule)
+	analysisService.mutex.Unlock()
+}
+
+// SetMetrics records analysis duration, match counts and emitted improvement areas in serviceMetrics
+func (analysisService *AnalysisService) SetMetrics(serviceMetrics *metrics.ServiceMetrics) {
+	analysisService.mutex.Lock()
+	analysisService.serviceMetrics = serviceMetrics
+	analysisService.mutex.Unlock()
+}
+
+// currentMetrics returns the metrics analyses are recorded in
+func (analysisService *AnalysisService) currentMetrics() *metrics.ServiceMetrics {
+	analysisService.mutex.RLock()
+	defer analysisService.mutex.RUnlock()
+	return analysisService.serviceMetrics
+}
+
+// registeredRules returns a snapshot of the registered rules
+func (analysisService *AnalysisService) registeredRules() []Rule {
+	analysisService.mutex.RLock()
+	defer analysisService.mutex.RUnlock()
+	return append([]Rule(nil), analysisService.rules...)
 }
 
 // AnalyzePlayer performs comprehensive analysis on a player's match history
 func (analysisService *AnalysisService) AnalyzePlayer(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult {
+	return analysisService.AnalyzePlayerWithOptions(context.Background(), summoner, matches, models.AnalysisOptions{})
+}
+
+// AnalyzePlayerWithOptions performs analysis using request-level options such as the climb goal,
+// logging and tracing through the request-scoped logger and span in ctx
+// Matches are validated leniently first; dropped input is reported in the result's warnings
+func (analysisService *AnalysisService) AnalyzePlayerWithOptions(ctx context.Context, summoner *models.Summoner, matches []models.Match, options models.AnalysisOptions) *models.AnalysisResult {
+	startTime := time.Now()
+	receivedMatches := len(matches)
+
+	matches, warnings := screenInvalidMatches(summoner, matches, false)
+	matches = filterMatches(summoner, matches, options.Filters)
+	matches, excludedMatches := screenMatches(summoner, matches)
+
+	statsMode, modeMatches, matches := selectStatsMode(matches, options.BlendModes)
+
+	_, statsSpan := tracing.Start(ctx, "calculatePlayerStats")
+	statsSpan.SetAttribute("matches", len(matches))
 	playerStats := analysisService.calculatePlayerStats(summoner, matches)
-	improvementAreas := analysisService.identifyImprovementAreas(&playerStats)
+	statsSpan.End()
+
+	ruleContext := &RuleContext{
+		Summoner:    summoner,
+		PlayerStats: &playerStats,
+		Matches:     matches,
+		LaneMatchup: analysisService.calculateLaneMatchup(summoner, matches),
+		Options:     options,
+		RuleConfig:  analysisService.currentRuleConfig(),
+	}
+	_, rulesSpan := tracing.Start(ctx, "identifyImprovementAreas")
+	improvementAreas := analysisService.identifyImprovementAreas(ruleContext)
+	rulesSpan.SetAttribute("improvement_areas", len(improvementAreas))
+	rulesSpan.End()
+	for index := range improvementAreas {
+		improvementAreas[index].Mode = statsMode
+	}
 
-	return &models.AnalysisResult{
+	analysisResult := &models.AnalysisResult{
 		PlayerStats:      playerStats,
+		StatsMode:        statsMode,
+		ModeStats:        analysisService.calculateModeStats(summoner, modeMatches),
+		OtherModes:       otherModes(statsMode, modeMatches),
 		ImprovementAreas: improvementAreas,
+		Benchmark:        ruleContext.benchmarkSelection().info(ruleContext.RuleConfig),
+		LaneMatchup:      ruleContext.LaneMatchup,
+		Synergy:          analysisService.calculateSynergy(summoner, matches, playerStats.WinRate),
+		Trends:           analysisService.calculateTrends(summoner, matches),
+		ExcludedMatches:  excludedMatches,
+		Warnings:         warnings,
 		AnalyzedAt:       time.Now(),
 	}
+	labelSectionModes(analysisResult, statsMode)
+
+	duration := time.Since(startTime)
+	analysisService.currentMetrics().ObserveAnalysis(receivedMatches, duration, improvementAreas)
+
+	requestctx.Logger(ctx).Debug().
+		Str("puuid", summoner.PUUID).
+		Int("received_matches", receivedMatches).
+		Int("analyzed_matches", len(matches)).
+		Int("excluded_matches", len(excludedMatches)).
+		Int("improvement_areas", len(improvementAreas)).
+		Dur("duration", duration).
+		Msg("Player analyzed")
+
+	return analysisResult
 }
 
 // calculatePlayerStats aggregates statistics from match history
 func (analysisService *AnalysisService) calculatePlayerStats(summoner *models.Summoner, matches []models.Match) models.PlayerStats {
-	if len(matches) == 0 {
+	accumulator := newPlayerStatsAccumulator(summoner.PUUID)
+	for matchIndex := range matches {
+		accumulator.add(&matches[matchIndex])
+	}
+	return analysisService.toPlayerStats(summoner, accumulator)
+}
+
+// toPlayerStats converts a player's accumulated totals into averages and breakdowns
+func (analysisService *AnalysisService) toPlayerStats(summoner *models.Summoner, accumulator *playerStatsAccumulator) models.PlayerStats {
+	if accumulator.matchCount == 0 {
 		return models.PlayerStats{
 			PUUID:        summoner.PUUID,
 			SummonerName: summoner.Name,
 		}
 	}
 
-	var totalKills int
-	var totalDeaths int
-	var totalAssists int
-	var totalCS int
-	var totalVisionScore int
-	var totalDamage int
-	var totalGold int
-	var totalGameDuration int
-	var wins int
-
-	championPool := make(map[string]int)
-	roleDistribution := make(map[string]int)
-
-	// Aggregate stats from all matches
-	for _, match := range matches {
-		// Find the player's participation in this match
-		for _, participant := range match.Participants {
-			if participant.PUUID == summoner.PUUID {
-				totalKills += participant.Kills
-				totalDeaths += participant.Deaths
-				totalAssists += participant.Assists
-				totalCS += participant.TotalMinionsKilled
-				totalVisionScore += participant.VisionScore
-				totalDamage += participant.TotalDamageDealtToChampions
-				totalGold += participant.GoldEarned
-				totalGameDuration += match.GameDuration
-
-				if participant.Win {
-					wins++
-				}
-
-				// Track champion pool
-				championPool[participant.ChampionName]++
-
-				// Track role distribution
-				if participant.TeamPosition != "" {
-					roleDistribution[participant.TeamPosition]++
-				}
-
-				break
-			}
-		}
-	}
-
-	matchCount := len(matches)
+	overall := accumulator.overall
+	matchCount := accumulator.matchCount
 	matchCountFloat := float64(matchCount)
 
 	// Calculate averages
-	averageKills := float64(totalKills) / matchCountFloat
-	averageDeaths := float64(totalDeaths) / matchCountFloat
-	averageAssists := float64(totalAssists) / matchCountFloat
-	averageCS := float64(totalCS) / matchCountFloat
-	averageVisionScore := float64(totalVisionScore) / matchCountFloat
-	averageDamage := float64(totalDamage) / matchCountFloat
-	averageGold := float64(totalGold) / matchCountFloat
+	averageKills := float64(overall.kills) / matchCountFloat
+	averageDeaths := float64(overall.deaths) / matchCountFloat
+	averageAssists := float64(overall.assists) / matchCountFloat
+	averageCS := float64(overall.creepScore) / matchCountFloat
+	averageVisionScore := float64(overall.visionScore) / matchCountFloat
+	averageDamage := float64(overall.damage) / matchCountFloat
+	averageGold := float64(overall.gold) / matchCountFloat
 
 	// Calculate KDA ratio
 	kda := analysisService.calculateKDA(averageKills, averageDeaths, averageAssists)
 
 	// Calculate CS per minute
-	averageGameDurationMinutes := float64(totalGameDuration) / matchCountFloat / 60.0
+	averageGameDurationMinutes := float64(overall.gameDurationTotal) / matchCountFloat / 60.0
 	csPerMinute := averageCS / averageGameDurationMinutes
 
 	// Calculate win rate
-	winRate := (float64(wins) / matchCountFloat) * 100.0
+	winRate := (float64(overall.wins) / matchCountFloat) * 100.0
 
 	// Convert role distribution to percentages
 	rolePercentages := make(map[string]float64)
-	for role, count := range roleDistribution {
+	for role, count := range accumulator.roleDistribution {
 		rolePercentages[role] = (float64(count) / matchCountFloat) * 100.0
 	}
 
+	// Copy the champion pool so later games do not change returned stats
+	championPool := make(map[string]int, len(accumulator.championPool))
+	for championName, games := range accumulator.championPool {
+		championPool[championName] = games
+	}
+
+	// Convert per-champion totals to averages
+	championStats := make(map[string]models.ChampionStats)
+	for championName, championAccumulator := range accumulator.champions {
+		championStats[championName] = analysisService.toChampionStats(championAccumulator, championName)
+	}
+
+	// Convert per-role totals to averages
+	roleStats := make(map[string]models.RoleStats)
+	for role, roleAccumulator := range accumulator.roles {
+		roleStats[role] = analysisService.toRoleStats(roleAccumulator, role)
+	}
+
+	// Pool the games that are not judged under their own role so they are judged against the default benchmarks
+	var pooledRoleStats *models.RoleStats
+	if pooled := accumulator.pooledRoles(); pooled.games > 0 && len(roleStats) > 0 {
+		stats := analysisService.toRoleStats(pooled, defaultProfileName)
+		pooledRoleStats = &stats
+	}
+
+	// Convert per-side totals to win rates
+	sideStats := make(map[string]models.SideStats)
+	for side, sideAccumulator := range accumulator.sides {
+		sideStats[side] = toSideStats(sideAccumulator, side)
+	}
+
 	return models.PlayerStats{
 		PUUID:              summoner.PUUID,
 		SummonerName:       summoner.Name,
@@ -122,8 +266,17 @@ func (analysisService *AnalysisService) calculatePlayerStats(summoner *models.Su
 		AverageVisionScore: averageVisionScore,
 		AverageDamage:      averageDamage,
 		AverageGold:        averageGold,
+		KillParticipation:  overall.killParticipation(),
+		DamageShare:        overall.damageShare(),
+		GoldShare:          overall.goldShare(),
+		DamagePerGold:      overall.damagePerGold(),
 		ChampionPool:       championPool,
+		ChampionStats:      championStats,
 		RoleDistribution:   rolePercentages,
+		RoleStats:          roleStats,
+		PooledRoleStats:    pooledRoleStats,
+		SideStats:          sideStats,
+		ObjectiveControl:   accumulator.objectives.objectiveControl(),
 	}
 }
 
@@ -136,111 +289,135 @@ func (analysisService *AnalysisService) calculateKDA(kills float64, deaths float
 	return (kills + assists) / deaths
 }
 
-// identifyImprovementAreas analyzes stats and identifies areas for improvement
-func (analysisService *AnalysisService) identifyImprovementAreas(playerStats *models.PlayerStats) []models.ImprovementArea {
+// identifyImprovementAreas runs every registered rule and collects the improvement areas they report
+// Benchmarks, gap bands and recommendations come from the active rule configuration
+func (analysisService *AnalysisService) identifyImprovementAreas(ruleContext *RuleContext) []models.ImprovementArea {
+	if ruleContext.RuleConfig == nil {
+		ruleContext.RuleConfig = analysisService.currentRuleConfig()
+	}
+
 	var improvementAreas []models.ImprovementArea
 
-	// Benchmark values for average players (these can be adjusted based on rank)
-	benchmarkCSPerMinute := 6.0
-	benchmarkVisionScore := 40.0
-	benchmarkKDA := 3.0
-	benchmarkDeaths := 5.0
-
-	// CS per minute analysis
-	csGap := playerStats.CSPerMinute - benchmarkCSPerMinute
-	if csGap < -1.0 {
-		priority := "HIGH"
-		if csGap > -2.0 {
-			priority = "MEDIUM"
-		}
+	for _, rule := range analysisService.registeredRules() {
+		improvementAreas = append(improvementAreas, rule.Evaluate(ruleContext)...)
+	}
 
+	// If no improvement areas found, add positive feedback
+	if len(improvementAreas) == 0 {
 		improvementAreas = append(improvementAreas, models.ImprovementArea{
-			Category:       "CS (Creep Score)",
-			CurrentValue:   math.Round(playerStats.CSPerMinute*10) / 10,
-			ExpectedValue:  benchmarkCSPerMinute,
-			Gap:            math.Round(csGap*10) / 10,
-			Priority:       priority,
-			Recommendation: "Focus on last-hitting minions more consistently. Practice farming in training mode and aim to maintain CS during mid-game teamfights.",
+			Category:       ruleContext.RuleConfig.PositiveFeedback.Category,
+			CurrentValue:   0,
+			ExpectedValue:  0,
+			Gap:            0,
+			Priority:       priorityLow,
+			Recommendation: ruleContext.RuleConfig.PositiveFeedback.Recommendation,
 		})
 	}
 
-	// Vision score analysis
-	visionGap := playerStats.AverageVisionScore - benchmarkVisionScore
-	if visionGap < -10.0 {
-		priority := "HIGH"
-		if visionGap > -20.0 {
-			priority = "MEDIUM"
-		}
+	return improvementAreas
+}
 
-		improvementAreas = append(improvementAreas, models.ImprovementArea{
-			Category:       "Vision Control",
-			CurrentValue:   math.Round(playerStats.AverageVisionScore*10) / 10,
-			ExpectedValue:  benchmarkVisionScore,
-			Gap:            math.Round(visionGap*10) / 10,
-			Priority:       priority,
-			Recommendation: "Purchase more control wards and place wards in key objectives (Dragon, Baron). Clear enemy wards when possible to increase vision score.",
-		})
+// metricValues builds the metric lookup used by metric rules
+// Damage is omitted when the match data carried no damage figures
+func metricValues(csPerMinute float64, visionScore float64, kda float64, deaths float64, damage float64, winRate float64) map[string]float64 {
+	values := map[string]float64{
+		metricCSPerMinute: csPerMinute,
+		metricVisionScore: visionScore,
+		metricKDA:         kda,
+		metricDeaths:      deaths,
+		metricWinRate:     winRate,
 	}
 
-	// KDA analysis
-	kdaGap := playerStats.KDA - benchmarkKDA
-	if kdaGap < -0.5 {
-		priority := "MEDIUM"
-		if kdaGap < -1.0 {
-			priority = "HIGH"
-		}
+	if damage > 0 {
+		values[metricDamage] = damage
+	}
 
-		improvementAreas = append(improvementAreas, models.ImprovementArea{
-			Category:       "KDA Ratio",
-			CurrentValue:   math.Round(playerStats.KDA*100) / 100,
-			ExpectedValue:  benchmarkKDA,
-			Gap:            math.Round(kdaGap*100) / 100,
-			Priority:       priority,
-			Recommendation: "Focus on safer positioning in teamfights. Prioritize assists over risky kills and avoid unnecessary deaths.",
-		})
+	return values
+}
+
+// addTeamMetricValues adds the team-relative metrics to a metric lookup
+// Metrics are omitted when the match data listed no teammates (or no gold) to compare against
+func addTeamMetricValues(values map[string]float64, killParticipation float64, damageShare float64, goldShare float64, damagePerGold float64) map[string]float64 {
+	teamValues := map[string]float64{
+		metricKillParticipation: killParticipation,
+		metricDamageShare:       damageShare,
+		metricGoldShare:         goldShare,
+		metricDamagePerGold:     damagePerGold,
 	}
 
-	// Deaths analysis
-	deathsGap := playerStats.AverageDeaths - benchmarkDeaths
-	if deathsGap > 1.0 {
-		priority := "MEDIUM"
-		if deathsGap > 2.0 {
-			priority = "HIGH"
+	for metric, value := range teamValues {
+		if value > 0 {
+			values[metric] = value
 		}
-
-		improvementAreas = append(improvementAreas, models.ImprovementArea{
-			Category:       "Deaths",
-			CurrentValue:   math.Round(playerStats.AverageDeaths*10) / 10,
-			ExpectedValue:  benchmarkDeaths,
-			Gap:            math.Round(deathsGap*10) / 10,
-			Priority:       priority,
-			Recommendation: "Review your deaths to identify patterns. Common causes: overextending without vision, poor positioning in fights, or staying too long with low HP.",
-		})
 	}
 
-	// Win rate analysis
-	if playerStats.WinRate < 45.0 {
-		improvementAreas = append(improvementAreas, models.ImprovementArea{
-			Category:       "Win Rate",
-			CurrentValue:   math.Round(playerStats.WinRate*10) / 10,
-			ExpectedValue:  50.0,
-			Gap:            math.Round((playerStats.WinRate-50.0)*10) / 10,
-			Priority:       "HIGH",
-			Recommendation: "Focus on macro gameplay: objective control, wave management, and better decision-making in mid-late game. Consider your champion pool and role effectiveness.",
-		})
+	return values
+}
+
+// playerMetricValues returns the metric values of the overall player stats
+func playerMetricValues(playerStats *models.PlayerStats) map[string]float64 {
+	values := metricValues(playerStats.CSPerMinute, playerStats.AverageVisionScore, playerStats.KDA, playerStats.AverageDeaths, playerStats.AverageDamage, playerStats.WinRate)
+	return addTeamMetricValues(values, playerStats.KillParticipation, playerStats.DamageShare, playerStats.GoldShare, playerStats.DamagePerGold)
+}
+
+// roleMetricValues returns the metric values of a single role
+func roleMetricValues(roleStats *models.RoleStats) map[string]float64 {
+	values := metricValues(roleStats.CSPerMinute, roleStats.AverageVisionScore, roleStats.KDA, roleStats.AverageDeaths, roleStats.AverageDamage, roleStats.WinRate)
+	return addTeamMetricValues(values, roleStats.KillParticipation, roleStats.DamageShare, roleStats.GoldShare, roleStats.DamagePerGold)
+}
+
+// championMetricValues returns the metric values of a single champion
+func championMetricValues(championStats *models.ChampionStats) map[string]float64 {
+	return metricValues(championStats.CSPerMinute, championStats.AverageVisionScore, championStats.KDA, championStats.AverageDeaths, championStats.AverageDamage, championStats.WinRate)
+}
+
+// toRoleStats converts accumulated totals into per-game averages for a role
+func (analysisService *AnalysisService) toRoleStats(accumulator *statsAccumulator, role string) models.RoleStats {
+	averageKills := accumulator.average(accumulator.kills)
+	averageDeaths := accumulator.average(accumulator.deaths)
+	averageAssists := accumulator.average(accumulator.assists)
+
+	return models.RoleStats{
+		Role:               role,
+		GamesPlayed:        accumulator.games,
+		Wins:               accumulator.wins,
+		WinRate:            accumulator.winRate(),
+		AverageKills:       averageKills,
+		AverageDeaths:      averageDeaths,
+		AverageAssists:     averageAssists,
+		KDA:                analysisService.calculateKDA(averageKills, averageDeaths, averageAssists),
+		CSPerMinute:        accumulator.csPerMinute(),
+		AverageVisionScore: accumulator.average(accumulator.visionScore),
+		AverageDamage:      accumulator.average(accumulator.damage),
+		AverageGold:        accumulator.average(accumulator.gold),
+		KillParticipation:  accumulator.killParticipation(),
+		DamageShare:        accumulator.damageShare(),
+		GoldShare:          accumulator.goldShare(),
+		DamagePerGold:      accumulator.damagePerGold(),
 	}
+}
 
-	// If no improvement areas found, add positive feedback
-	if len(improvementAreas) == 0 {
-		improvementAreas = append(improvementAreas, models.ImprovementArea{
-			Category:       "Overall Performance",
-			CurrentValue:   0,
-			ExpectedValue:  0,
-			Gap:            0,
-			Priority:       "LOW",
-			Recommendation: "Your performance is above average! Continue maintaining good CS, vision control, and KDA. Focus on consistency and adapting to different team compositions.",
-		})
+// isJudgedRole reports whether a role has enough games to be judged on its own
+// A player's only role is always judged, regardless of sample size
+func isJudgedRole(gamesPlayed int, roleCount int) bool {
+	return gamesPlayed >= minimumRoleGames || roleCount == 1
+}
+
+// judgedRoles returns the roles with enough games to be judged, ordered by games played
+func judgedRoles(roleStats map[string]models.RoleStats) []models.RoleStats {
+	var judged []models.RoleStats
+	for _, stats := range roleStats {
+		if isJudgedRole(stats.GamesPlayed, len(roleStats)) {
+			judged = append(judged, stats)
+		}
 	}
 
-	return improvementAreas
+	sort.Slice(judged, func(left int, right int) bool {
+		if judged[left].GamesPlayed != judged[right].GamesPlayed {
+			return judged[left].GamesPlayed > judged[right].GamesPlayed
+		}
+		return judged[left].Role < judged[right].Role
+	})
+
+	return judged
 }
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"context"
 	"testing"
 
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/metrics"
 	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
 )
 
 // TestNewAnalysisService tests the NewAnalysisService constructor
@@ -166,7 +169,7 @@ func TestIdentifyImprovementAreas_LowCS(t *testing.T) {
 		WinRate:            55.0,
 	}
 
-	areas := service.identifyImprovementAreas(playerStats)
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
 
 	foundCSImprovement := false
 	for _, area := range areas {
@@ -196,7 +199,7 @@ func TestIdentifyImprovementAreas_LowVision(t *testing.T) {
 		WinRate:            55.0,
 	}
 
-	areas := service.identifyImprovementAreas(playerStats)
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
 
 	foundVisionImprovement := false
 	for _, area := range areas {
@@ -226,7 +229,7 @@ func TestIdentifyImprovementAreas_HighDeaths(t *testing.T) {
 		WinRate:            55.0,
 	}
 
-	areas := service.identifyImprovementAreas(playerStats)
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
 
 	foundDeathsImprovement := false
 	for _, area := range areas {
@@ -256,7 +259,7 @@ func TestIdentifyImprovementAreas_LowWinRate(t *testing.T) {
 		WinRate:            40.0, // Below 45.0
 	}
 
-	areas := service.identifyImprovementAreas(playerStats)
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
 
 	foundWinRateImprovement := false
 	for _, area := range areas {
@@ -286,7 +289,7 @@ func TestIdentifyImprovementAreas_NoImprovements(t *testing.T) {
 		WinRate:            55.0, // Above 45.0
 	}
 
-	areas := service.identifyImprovementAreas(playerStats)
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
 
 	// Should have at least one area (the positive feedback)
 	if len(areas) == 0 {
@@ -427,3 +430,62 @@ func TestAnalyzePlayer_MixedRoles(t *testing.T) {
 		t.Errorf("Expected JUNGLE %.1f%%, got %.1f%%", expectedJungle, result.PlayerStats.RoleDistribution["JUNGLE"])
 	}
 }
+
+// TestAnalyzePlayer_RecordsMetrics tests that analyses record duration, match counts and improvement areas
+func TestAnalyzePlayer_RecordsMetrics(t *testing.T) {
+	service := NewAnalysisService()
+	serviceMetrics := metrics.NewServiceMetrics()
+	service.SetMetrics(serviceMetrics)
+
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+	matches := []models.Match{outlierMatch("first"), outlierMatch("second"), outlierMatch("third")}
+
+	analysisResult := service.AnalyzePlayer(summoner, matches)
+
+	if count := serviceMetrics.AnalysisDuration.Count(); count != 1 {
+		t.Errorf("Expected 1 analysis duration observation, got %d", count)
+	}
+
+	if count := serviceMetrics.AnalysisMatches.Count(); count != 1 {
+		t.Errorf("Expected 1 match count observation, got %d", count)
+	}
+
+	for _, improvementArea := range analysisResult.ImprovementAreas {
+		if serviceMetrics.ImprovementAreas.Value(improvementArea.Category, improvementArea.Priority) == 0 {
+			t.Errorf("Expected improvement area %s/%s to be counted", improvementArea.Category, improvementArea.Priority)
+		}
+	}
+}
+
+// spanRecorder keeps the names of exported spans for tests
+type spanRecorder struct {
+	names []string
+}
+
+func (recorder *spanRecorder) ExportSpan(spanData *tracing.SpanData) error {
+	recorder.names = append(recorder.names, spanData.Name)
+	return nil
+}
+
+func (recorder *spanRecorder) Shutdown() error {
+	return nil
+}
+
+// TestAnalyzePlayerWithOptions_Spans tests that stats and rule evaluation are traced within the request span
+func TestAnalyzePlayerWithOptions_Spans(t *testing.T) {
+	recorder := &spanRecorder{}
+	ctx, requestSpan := tracing.NewTracer(recorder).Start(context.Background(), "POST /api/v1/analyze", tracing.SpanKindServer)
+
+	NewAnalysisService().AnalyzePlayerWithOptions(ctx, &models.Summoner{PUUID: "test-puuid"}, []models.Match{outlierMatch("first")}, models.AnalysisOptions{})
+	requestSpan.End()
+
+	expected := []string{"calculatePlayerStats", "identifyImprovementAreas", "POST /api/v1/analyze"}
+	if len(recorder.names) != len(expected) {
+		t.Fatalf("Expected spans %v, got %v", expected, recorder.names)
+	}
+	for index, name := range expected {
+		if recorder.names[index] != name {
+			t.Errorf("Expected span %d to be %s, got %s", index, name, recorder.names[index])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package services
+
+import "strings"
+
+// Role identifiers matching Riot's teamPosition values
+const (
+	roleTop     = "TOP"
+	roleJungle  = "JUNGLE"
+	roleMiddle  = "MIDDLE"
+	roleBottom  = "BOTTOM"
+	roleUtility = "UTILITY"
+)
+
+// defaultProfileName labels the benchmark used when no role benchmark applies
+const defaultProfileName = "DEFAULT"
+
+// minimumRoleGames is the number of games required before a secondary role is judged on its own
+const minimumRoleGames = 2
+
+// knownRoles lists the role identifiers used for role-aware benchmarks
+var knownRoles = map[string]bool{
+	roleTop:     true,
+	roleJungle:  true,
+	roleMiddle:  true,
+	roleBottom:  true,
+	roleUtility: true,
+}
+
+// roleAliases maps legacy or informal position names to Riot's teamPosition values
+var roleAliases = map[string]string{
+	"MID":     roleMiddle,
+	"BOT":     roleBottom,
+	"ADC":     roleBottom,
+	"CARRY":   roleBottom,
+	"SUPPORT": roleUtility,
+	"SUP":     roleUtility,
+	"JG":      roleJungle,
+}
+
+// normalizeRole converts a team position to one of the known role identifiers
+// Unknown or empty positions return an empty string
+func normalizeRole(teamPosition string) string {
+	role := strings.ToUpper(strings.TrimSpace(teamPosition))
+
+	if alias, exists := roleAliases[role]; exists {
+		return alias
+	}
+
+	if knownRoles[role] {
+		return role
+	}
+
+	return ""
+}
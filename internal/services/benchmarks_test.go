@@ -0,0 +1,285 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// TestNormalizeRole tests team position normalization and aliases
+func TestNormalizeRole(t *testing.T) {
+	testCases := []struct {
+		teamPosition string
+		expected     string
+	}{
+		{"TOP", roleTop},
+		{"JUNGLE", roleJungle},
+		{"MIDDLE", roleMiddle},
+		{"MID", roleMiddle},
+		{"bottom", roleBottom},
+		{"ADC", roleBottom},
+		{"UTILITY", roleUtility},
+		{"SUPPORT", roleUtility},
+		{"", ""},
+		{"Invalid", ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.teamPosition, func(t *testing.T) {
+			result := normalizeRole(testCase.teamPosition)
+			if result != testCase.expected {
+				t.Errorf("Expected role '%s', got '%s'", testCase.expected, result)
+			}
+		})
+	}
+}
+
+// TestMetricRuleExpectedValue_RoleFallback tests role benchmarks and the fallback to the default benchmark
+func TestMetricRuleExpectedValue_RoleFallback(t *testing.T) {
+	ruleConfig := DefaultRuleConfig()
+	csRule := findMetricRule(t, ruleConfig, metricCSPerMinute)
+
+	expectedValue, profileName := csRule.expectedValue("", benchmarkSelection{}, ruleConfig.ReferenceTier)
+	if profileName != defaultProfileName {
+		t.Errorf("Expected profile '%s', got '%s'", defaultProfileName, profileName)
+	}
+	if expectedValue != csRule.Benchmark {
+		t.Errorf("Expected CS benchmark %.1f, got %.1f", csRule.Benchmark, expectedValue)
+	}
+
+	expectedValue, profileName = csRule.expectedValue(roleUtility, benchmarkSelection{}, ruleConfig.ReferenceTier)
+	if profileName != roleUtility {
+		t.Errorf("Expected profile '%s', got '%s'", roleUtility, profileName)
+	}
+	if expectedValue != csRule.RoleBenchmarks[roleUtility] {
+		t.Errorf("Expected UTILITY CS benchmark %.1f, got %.1f", csRule.RoleBenchmarks[roleUtility], expectedValue)
+	}
+}
+
+// TestIdentifyImprovementAreas_SupportNotFlaggedForCS tests that supports are judged against the UTILITY profile
+func TestIdentifyImprovementAreas_SupportNotFlaggedForCS(t *testing.T) {
+	service := NewAnalysisService()
+
+	playerStats := &models.PlayerStats{
+		CSPerMinute:        1.0,
+		AverageVisionScore: 70.0,
+		KDA:                3.5,
+		AverageDeaths:      4.0,
+		WinRate:            55.0,
+		RoleStats: map[string]models.RoleStats{
+			roleUtility: {Role: roleUtility, GamesPlayed: 10, CSPerMinute: 1.0, AverageVisionScore: 70.0, KDA: 3.5, AverageDeaths: 4.0},
+		},
+	}
+
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
+
+	for _, area := range areas {
+		if area.Category == "CS (Creep Score)" {
+			t.Errorf("Expected support not to be flagged for CS, got %+v", area)
+		}
+	}
+}
+
+// TestIdentifyImprovementAreas_RoleProfileUsed tests that improvement areas report the role profile used
+func TestIdentifyImprovementAreas_RoleProfileUsed(t *testing.T) {
+	service := NewAnalysisService()
+
+	playerStats := &models.PlayerStats{
+		WinRate: 55.0,
+		RoleStats: map[string]models.RoleStats{
+			roleBottom:  {Role: roleBottom, GamesPlayed: 6, CSPerMinute: 5.0, AverageVisionScore: 20.0, KDA: 3.5, AverageDeaths: 4.0},
+			roleUtility: {Role: roleUtility, GamesPlayed: 4, CSPerMinute: 1.0, AverageVisionScore: 30.0, KDA: 3.5, AverageDeaths: 4.0},
+		},
+	}
+
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
+	bottomBenchmark := findMetricRule(t, DefaultRuleConfig(), metricCSPerMinute).RoleBenchmarks[roleBottom]
+
+	foundBottomCS := false
+	foundUtilityVision := false
+	for _, area := range areas {
+		if area.Category == "CS (Creep Score)" && area.Role == roleBottom {
+			foundBottomCS = true
+			if area.ExpectedValue != bottomBenchmark {
+				t.Errorf("Expected BOTTOM CS benchmark %.1f, got %.1f", bottomBenchmark, area.ExpectedValue)
+			}
+		}
+		if area.Category == "Vision Control" && area.Role == roleUtility {
+			foundUtilityVision = true
+		}
+		if area.Category == "Vision Control" && area.Role == roleBottom {
+			t.Error("Expected BOTTOM not to be flagged for vision")
+		}
+	}
+
+	if !foundBottomCS {
+		t.Error("Expected BOTTOM CS improvement area to be identified")
+	}
+
+	if !foundUtilityVision {
+		t.Error("Expected UTILITY vision improvement area to be identified")
+	}
+}
+
+// TestJudgedRoles_SkipsSmallSecondaryRoles tests that one-off secondary roles are not judged
+func TestJudgedRoles_SkipsSmallSecondaryRoles(t *testing.T) {
+	roleStats := map[string]models.RoleStats{
+		roleMiddle: {Role: roleMiddle, GamesPlayed: 9},
+		roleTop:    {Role: roleTop, GamesPlayed: 1},
+	}
+
+	judged := judgedRoles(roleStats)
+
+	if len(judged) != 1 || judged[0].Role != roleMiddle {
+		t.Errorf("Expected only MIDDLE to be judged, got %+v", judged)
+	}
+}
+
+// TestAnalyzePlayer_ThinRolesJudgedAgainstDefault tests that a 1+1 split across roles is judged against the default benchmarks
+func TestAnalyzePlayer_ThinRolesJudgedAgainstDefault(t *testing.T) {
+	service := NewAnalysisService()
+
+	summoner := &models.Summoner{PUUID: "test-puuid", Name: "TestPlayer"}
+
+	matches := []models.Match{
+		{
+			MatchID:      "NA1_1",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Darius", Kills: 1, Deaths: 9, Assists: 2, TotalMinionsKilled: 60, VisionScore: 5, Win: false, TeamPosition: "TOP"},
+			},
+		},
+		{
+			MatchID:      "NA1_2",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 2, Deaths: 10, Assists: 1, TotalMinionsKilled: 75, VisionScore: 7, Win: false, TeamPosition: "MIDDLE"},
+			},
+		},
+	}
+
+	result := service.AnalyzePlayer(summoner, matches)
+
+	pooled := result.PlayerStats.PooledRoleStats
+	if pooled == nil || pooled.GamesPlayed != 2 {
+		t.Fatalf("Expected both games to be pooled, got %+v", pooled)
+	}
+
+	foundDefaultCS := false
+	for _, area := range result.ImprovementAreas {
+		if area.Category == DefaultRuleConfig().PositiveFeedback.Category {
+			t.Errorf("Expected no positive feedback for poor stats, got %+v", area)
+		}
+		if area.Metric == metricCSPerMinute {
+			if area.Role != defaultProfileName {
+				t.Errorf("Expected CS to be judged against the %s profile, got %q", defaultProfileName, area.Role)
+			}
+			foundDefaultCS = true
+		}
+	}
+
+	if !foundDefaultCS {
+		t.Errorf("Expected a CS improvement area, got %+v", result.ImprovementAreas)
+	}
+}
+
+// TestAnalyzePlayer_UnknownPositionsPooled tests that games without a known position are judged next to the judged roles
+func TestAnalyzePlayer_UnknownPositionsPooled(t *testing.T) {
+	service := NewAnalysisService()
+
+	summoner := &models.Summoner{PUUID: "test-puuid", Name: "TestPlayer"}
+
+	matches := []models.Match{
+		{
+			MatchID:      "NA1_1",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 6, Deaths: 2, Assists: 6, TotalMinionsKilled: 240, VisionScore: 30, Win: true, TeamPosition: "MIDDLE"},
+			},
+		},
+		{
+			MatchID:      "NA1_2",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 7, Deaths: 3, Assists: 5, TotalMinionsKilled: 250, VisionScore: 28, Win: true, TeamPosition: "MIDDLE"},
+			},
+		},
+		{
+			MatchID:      "NA1_3",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 1, Deaths: 8, Assists: 1, TotalMinionsKilled: 60, VisionScore: 4, Win: false, TeamPosition: ""},
+			},
+		},
+	}
+
+	result := service.AnalyzePlayer(summoner, matches)
+
+	pooled := result.PlayerStats.PooledRoleStats
+	if pooled == nil || pooled.GamesPlayed != 1 {
+		t.Fatalf("Expected the game without a position to be pooled, got %+v", pooled)
+	}
+
+	foundDefaultCS := false
+	for _, area := range result.ImprovementAreas {
+		if area.Metric == metricCSPerMinute && area.Role == defaultProfileName {
+			foundDefaultCS = true
+		}
+		if area.Metric == metricCSPerMinute && area.Role == roleMiddle {
+			t.Errorf("Expected MIDDLE not to be flagged for CS, got %+v", area)
+		}
+	}
+
+	if !foundDefaultCS {
+		t.Errorf("Expected a CS improvement area against the %s profile, got %+v", defaultProfileName, result.ImprovementAreas)
+	}
+}
+
+// TestAnalyzePlayer_RoleStats tests that role stats are built from normalized team positions
+func TestAnalyzePlayer_RoleStats(t *testing.T) {
+	service := NewAnalysisService()
+
+	summoner := &models.Summoner{PUUID: "test-puuid", Name: "TestPlayer"}
+
+	matches := []models.Match{
+		{
+			MatchID:      "NA1_1",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Thresh", TotalMinionsKilled: 30, VisionScore: 80, Win: true, TeamPosition: "UTILITY"},
+			},
+		},
+		{
+			MatchID:      "NA1_2",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Thresh", TotalMinionsKilled: 42, VisionScore: 60, Win: false, TeamPosition: "SUPPORT"},
+			},
+		},
+	}
+
+	result := service.AnalyzePlayer(summoner, matches)
+
+	utilityStats, exists := result.PlayerStats.RoleStats[roleUtility]
+	if !exists {
+		t.Fatal("Expected UTILITY role stats to exist")
+	}
+
+	if utilityStats.GamesPlayed != 2 {
+		t.Errorf("Expected 2 UTILITY games, got %d", utilityStats.GamesPlayed)
+	}
+
+	if utilityStats.AverageVisionScore != 70.0 {
+		t.Errorf("Expected UTILITY vision 70.0, got %.1f", utilityStats.AverageVisionScore)
+	}
+
+	if result.PlayerStats.ChampionStats["Thresh"].PrimaryRole != roleUtility {
+		t.Errorf("Expected Thresh primary role UTILITY, got '%s'", result.PlayerStats.ChampionStats["Thresh"].PrimaryRole)
+	}
+
+	for _, area := range result.ImprovementAreas {
+		if area.Category == "CS (Creep Score)" {
+			t.Errorf("Expected support not to be flagged for CS, got %+v", area)
+		}
+	}
+}
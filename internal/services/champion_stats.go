@@ -0,0 +1,113 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// toChampionStats converts accumulated totals into per-game averages for a champion
+func (analysisService *AnalysisService) toChampionStats(accumulator *statsAccumulator, championName string) models.ChampionStats {
+	averageKills := accumulator.average(accumulator.kills)
+	averageDeaths := accumulator.average(accumulator.deaths)
+	averageAssists := accumulator.average(accumulator.assists)
+
+	return models.ChampionStats{
+		ChampionName:       championName,
+		GamesPlayed:        accumulator.games,
+		Wins:               accumulator.wins,
+		WinRate:            accumulator.winRate(),
+		AverageKills:       averageKills,
+		AverageDeaths:      averageDeaths,
+		AverageAssists:     averageAssists,
+		KDA:                analysisService.calculateKDA(averageKills, averageDeaths, averageAssists),
+		CSPerMinute:        accumulator.csPerMinute(),
+		AverageVisionScore: accumulator.average(accumulator.visionScore),
+		AverageDamage:      accumulator.average(accumulator.damage),
+		AverageGold:        accumulator.average(accumulator.gold),
+		PrimaryRole:        accumulator.primaryRole(),
+	}
+}
+
+// sortedChampionStats returns champion stats ordered by games played (descending), then by name
+func sortedChampionStats(championStats map[string]models.ChampionStats) []models.ChampionStats {
+	sortedStats := make([]models.ChampionStats, 0, len(championStats))
+	for _, stats := range championStats {
+		sortedStats = append(sortedStats, stats)
+	}
+
+	sort.Slice(sortedStats, func(left int, right int) bool {
+		if sortedStats[left].GamesPlayed != sortedStats[right].GamesPlayed {
+			return sortedStats[left].GamesPlayed > sortedStats[right].GamesPlayed
+		}
+		return sortedStats[left].ChampionName < sortedStats[right].ChampionName
+	})
+
+	return sortedStats
+}
+
+// championBenchmarkRule flags champion-specific weaknesses for champions with enough games
+// Each champion is judged against the benchmarks of the role it is usually played in
+type championBenchmarkRule struct{}
+
+// Name identifies the rule
+func (rule *championBenchmarkRule) Name() string {
+	return "benchmark:champion"
+}
+
+// Evaluate applies every configured champion rule to each champion in the pool
+func (rule *championBenchmarkRule) Evaluate(ruleContext *RuleContext) []models.ImprovementArea {
+	ruleConfig := ruleContext.RuleConfig
+	selection := ruleContext.benchmarkSelection()
+
+	var improvementAreas []models.ImprovementArea
+
+	for _, championStats := range sortedChampionStats(ruleContext.PlayerStats.ChampionStats) {
+		values := championMetricValues(&championStats)
+
+		for index := range ruleConfig.Metrics {
+			metricRule := &ruleConfig.Metrics[index]
+			championRule := metricRule.Champion
+			if championRule == nil || championStats.GamesPlayed < championRule.MinimumGames {
+				continue
+			}
+
+			currentValue, exists := values[metricRule.Metric]
+			if !exists {
+				continue
+			}
+
+			expectedValue, profileName := metricRule.expectedValue(championStats.PrimaryRole, selection, ruleConfig.ReferenceTier)
+			priority := championRule.Bands.priorityFor(metricRule.shortfall(currentValue, expectedValue))
+			if priority == "" {
+				continue
+			}
+
+			recommendation := strings.NewReplacer(
+				"{champion}", championStats.ChampionName,
+				"{value}", strconv.FormatFloat(currentValue, 'f', metricRule.Precision, 64),
+				"{games}", strconv.Itoa(championStats.GamesPlayed),
+			).Replace(championRule.Recommendation)
+
+			improvementArea := models.ImprovementArea{
+				Category:       metricRule.Category,
+				Metric:         metricRule.Metric,
+				CurrentValue:   roundTo(currentValue, metricRule.Precision),
+				ExpectedValue:  expectedValue,
+				Gap:            roundTo(currentValue-expectedValue, metricRule.Precision),
+				Priority:       priority,
+				Recommendation: recommendation,
+				Champion:       championStats.ChampionName,
+			}
+			if metricRule.isRoleAware() {
+				improvementArea.Role = profileName
+			}
+
+			improvementAreas = append(improvementAreas, improvementArea)
+		}
+	}
+
+	return improvementAreas
+}
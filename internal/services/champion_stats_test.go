@@ -0,0 +1,160 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// TestAnalyzePlayer_ChampionStats tests the per-champion statistics breakdown
+func TestAnalyzePlayer_ChampionStats(t *testing.T) {
+	service := NewAnalysisService()
+
+	summoner := &models.Summoner{
+		PUUID: "test-puuid",
+		Name:  "TestPlayer",
+	}
+
+	matches := []models.Match{
+		{
+			MatchID:      "NA1_1",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 10, Deaths: 2, Assists: 6, TotalMinionsKilled: 240, VisionScore: 30, TotalDamageDealtToChampions: 30000, GoldEarned: 14000, Win: true},
+			},
+		},
+		{
+			MatchID:      "NA1_2",
+			GameDuration: 1200,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 4, Deaths: 6, Assists: 2, TotalMinionsKilled: 120, VisionScore: 10, TotalDamageDealtToChampions: 10000, GoldEarned: 8000, Win: false},
+			},
+		},
+		{
+			MatchID:      "NA1_3",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Zed", Kills: 8, Deaths: 4, Assists: 4, TotalMinionsKilled: 150, Win: true},
+			},
+		},
+	}
+
+	result := service.AnalyzePlayer(summoner, matches)
+
+	if len(result.PlayerStats.ChampionStats) != 2 {
+		t.Fatalf("Expected 2 champions in breakdown, got %d", len(result.PlayerStats.ChampionStats))
+	}
+
+	ahriStats := result.PlayerStats.ChampionStats["Ahri"]
+
+	if ahriStats.GamesPlayed != 2 {
+		t.Errorf("Expected Ahri GamesPlayed 2, got %d", ahriStats.GamesPlayed)
+	}
+
+	if ahriStats.WinRate != 50.0 {
+		t.Errorf("Expected Ahri WinRate 50.0, got %.1f", ahriStats.WinRate)
+	}
+
+	// KDA should be (7 + 4) / 4 = 2.75
+	if ahriStats.KDA != 2.75 {
+		t.Errorf("Expected Ahri KDA 2.75, got %.2f", ahriStats.KDA)
+	}
+
+	// CS per minute should be 360 CS over 50 minutes = 7.2
+	if math.Abs(ahriStats.CSPerMinute-7.2) > 0.0001 {
+		t.Errorf("Expected Ahri CSPerMinute 7.2, got %.2f", ahriStats.CSPerMinute)
+	}
+
+	if ahriStats.AverageVisionScore != 20.0 {
+		t.Errorf("Expected Ahri AverageVisionScore 20.0, got %.1f", ahriStats.AverageVisionScore)
+	}
+
+	if ahriStats.AverageDamage != 20000.0 {
+		t.Errorf("Expected Ahri AverageDamage 20000, got %.1f", ahriStats.AverageDamage)
+	}
+
+	if ahriStats.AverageGold != 11000.0 {
+		t.Errorf("Expected Ahri AverageGold 11000, got %.1f", ahriStats.AverageGold)
+	}
+
+	zedStats := result.PlayerStats.ChampionStats["Zed"]
+	if zedStats.GamesPlayed != 1 || zedStats.WinRate != 100.0 {
+		t.Errorf("Expected Zed to have 1 game at 100%% win rate, got %d games at %.1f%%", zedStats.GamesPlayed, zedStats.WinRate)
+	}
+}
+
+// TestChampionBenchmarkRule_LowChampionCS tests champion-specific CS improvement areas
+func TestChampionBenchmarkRule_LowChampionCS(t *testing.T) {
+	playerStats := &models.PlayerStats{
+		ChampionStats: map[string]models.ChampionStats{
+			"Zed":  {ChampionName: "Zed", GamesPlayed: 5, WinRate: 60.0, KDA: 3.5, CSPerMinute: 4.1},
+			"Ahri": {ChampionName: "Ahri", GamesPlayed: 5, WinRate: 60.0, KDA: 3.5, CSPerMinute: 7.5},
+		},
+	}
+
+	areas := (&championBenchmarkRule{}).Evaluate(&RuleContext{PlayerStats: playerStats, RuleConfig: DefaultRuleConfig()})
+
+	if len(areas) != 1 {
+		t.Fatalf("Expected 1 champion improvement area, got %d", len(areas))
+	}
+
+	if areas[0].Champion != "Zed" {
+		t.Errorf("Expected champion 'Zed', got '%s'", areas[0].Champion)
+	}
+
+	if areas[0].Category != "CS (Creep Score)" {
+		t.Errorf("Expected category 'CS (Creep Score)', got '%s'", areas[0].Category)
+	}
+
+	if areas[0].CurrentValue != 4.1 {
+		t.Errorf("Expected CurrentValue 4.1, got %.1f", areas[0].CurrentValue)
+	}
+}
+
+// TestChampionBenchmarkRule_SmallSample tests that champions with few games are skipped
+func TestChampionBenchmarkRule_SmallSample(t *testing.T) {
+	playerStats := &models.PlayerStats{
+		ChampionStats: map[string]models.ChampionStats{
+			"Zed": {ChampionName: "Zed", GamesPlayed: 2, WinRate: 0.0, KDA: 0.5, CSPerMinute: 2.0},
+		},
+	}
+
+	areas := (&championBenchmarkRule{}).Evaluate(&RuleContext{PlayerStats: playerStats, RuleConfig: DefaultRuleConfig()})
+
+	if len(areas) != 0 {
+		t.Errorf("Expected no champion improvement areas for small sample, got %d", len(areas))
+	}
+}
+
+// TestIdentifyImprovementAreas_IncludesChampionAreas tests that champion areas are part of the full analysis
+func TestIdentifyImprovementAreas_IncludesChampionAreas(t *testing.T) {
+	service := NewAnalysisService()
+
+	playerStats := &models.PlayerStats{
+		CSPerMinute:        7.0,
+		AverageVisionScore: 45.0,
+		KDA:                4.0,
+		AverageDeaths:      4.0,
+		WinRate:            55.0,
+		ChampionStats: map[string]models.ChampionStats{
+			"Yasuo": {ChampionName: "Yasuo", GamesPlayed: 4, WinRate: 25.0, KDA: 3.5, CSPerMinute: 7.0},
+		},
+	}
+
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
+
+	foundChampionWinRate := false
+	for _, area := range areas {
+		if area.Category == "Overall Performance" {
+			t.Error("Expected no positive feedback when a champion-specific area exists")
+		}
+		if area.Category == "Win Rate" && area.Champion == "Yasuo" {
+			foundChampionWinRate = true
+		}
+	}
+
+	if !foundChampionWinRate {
+		t.Error("Expected Yasuo win rate improvement area to be identified")
+	}
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// ComparePlayers puts two or more players side by side
+// Each player's filters and mode separation apply as in an analysis, so by default a player is compared on
+// their most played mode. Metrics come from the active rule configuration so their direction matches the
+// improvement rules; a metric is only compared when every player has a value for it
+// Each player's matches are validated leniently first; warnings point into /players/{index}
+func (analysisService *AnalysisService) ComparePlayers(entries []models.ComparisonEntry) *models.ComparisonResult {
+	ruleConfig := analysisService.currentRuleConfig()
+
+	players := make([]models.PlayerStats, 0, len(entries))
+	playerValues := make([]map[string]float64, 0, len(entries))
+	statsModes := make(map[string]string, len(entries))
+	// Each player's entry narrowed to the matches their stats were calculated from
+	comparedEntries := make([]models.ComparisonEntry, 0, len(entries))
+	var warnings []models.ValidationWarning
+	for index, entry := range entries {
+		matches, playerWarnings := screenInvalidMatches(entry.Summoner, entry.Matches, false)
+		for _, warning := range playerWarnings {
+			warning.Pointer = fmt.Sprintf("/players/%d%s", index, warning.Pointer)
+			warnings = append(warnings, warning)
+		}
+
+		matches = filterMatches(entry.Summoner, matches, entry.Options.Filters)
+		matches, _ = screenMatches(entry.Summoner, matches)
+		statsMode, _, matches := selectStatsMode(matches, entry.Options.BlendModes)
+
+		playerStats := analysisService.calculatePlayerStats(entry.Summoner, matches)
+		players = append(players, playerStats)
+		playerValues = append(playerValues, playerMetricValues(&playerStats))
+		statsModes[entry.Summoner.PUUID] = statsMode
+
+		entry.Matches = matches
+		comparedEntries = append(comparedEntries, entry)
+	}
+
+	var metricComparisons []models.MetricComparison
+	leadCounts := make(map[string]int)
+
+	for _, metricRule := range ruleConfig.Metrics {
+		metricComparison, compared := compareMetric(&metricRule, players, playerValues)
+		if !compared {
+			continue
+		}
+
+		if metricComparison.Leader != "" {
+			leadCounts[metricComparison.Leader]++
+		}
+		metricComparisons = append(metricComparisons, metricComparison)
+	}
+
+	return &models.ComparisonResult{
+		Players:     players,
+		StatsModes:  statsModes,
+		Metrics:     metricComparisons,
+		SharedGames: findSharedGames(comparedEntries),
+		Summary:     comparisonSummary(players, leadCounts, len(metricComparisons)),
+		Warnings:    warnings,
+		ComparedAt:  time.Now(),
+	}
+}
+
+// compareMetric compares a single metric across players
+// The second return value is false when any player lacks a value for the metric
+func compareMetric(metricRule *MetricRule, players []models.PlayerStats, playerValues []map[string]float64) (models.MetricComparison, bool) {
+	metricComparison := models.MetricComparison{
+		Metric:        metricRule.Metric,
+		Category:      metricRule.Category,
+		LowerIsBetter: metricRule.Direction == directionLower,
+		Values:        make(map[string]float64),
+		Deltas:        make(map[string]float64),
+	}
+
+	var bestValue float64
+	for index, values := range playerValues {
+		value, exists := values[metricRule.Metric]
+		if !exists {
+			return models.MetricComparison{}, false
+		}
+
+		puuid := players[index].PUUID
+		metricComparison.Values[puuid] = roundTo(value, metricRule.Precision)
+		metricComparison.Deltas[puuid] = roundTo(value-playerValues[0][metricRule.Metric], metricRule.Precision)
+
+		isBetter := value > bestValue
+		if metricComparison.LowerIsBetter {
+			isBetter = value < bestValue
+		}
+
+		switch {
+		case index == 0 || isBetter:
+			bestValue = value
+			metricComparison.Leader = puuid
+		case value == bestValue:
+			metricComparison.Leader = ""
+		}
+	}
+
+	return metricComparison, true
+}
+
+// findSharedGames returns the matches that appear in more than one player's history
+// Entries should only hold the matches each player's stats were calculated from
+func findSharedGames(entries []models.ComparisonEntry) []models.SharedGame {
+	type sharedMatch struct {
+		match        *models.Match
+		participants []*models.Participant
+	}
+
+	sharedMatches := make(map[string]*sharedMatch)
+	var matchOrder []string
+
+	for _, entry := range entries {
+		seenInEntry := make(map[string]bool)
+		for matchIndex := range entry.Matches {
+			match := &entry.Matches[matchIndex]
+			if match.MatchID == "" || seenInEntry[match.MatchID] {
+				continue
+			}
+			seenInEntry[match.MatchID] = true
+
+			participant := findParticipant(match, entry.Summoner.PUUID)
+			if participant == nil {
+				continue
+			}
+
+			shared, exists := sharedMatches[match.MatchID]
+			if !exists {
+				shared = &sharedMatch{match: match}
+				sharedMatches[match.MatchID] = shared
+				matchOrder = append(matchOrder, match.MatchID)
+			}
+			shared.participants = append(shared.participants, participant)
+		}
+	}
+
+	var sharedGames []models.SharedGame
+	for _, matchID := range matchOrder {
+		shared := sharedMatches[matchID]
+		if len(shared.participants) < 2 {
+			continue
+		}
+
+		sharedGame := models.SharedGame{MatchID: matchID, SameTeam: true}
+		for _, participant := range shared.participants {
+			sharedGame.PUUIDs = append(sharedGame.PUUIDs, participant.PUUID)
+			if !isTeammate(shared.participants[0], participant) {
+				sharedGame.SameTeam = false
+			}
+		}
+		sharedGames = append(sharedGames, sharedGame)
+	}
+
+	return sharedGames
+}
+
+// findParticipant returns the participant with the given PUUID, or nil when the player is not in the match
+func findParticipant(match *models.Match, puuid string) *models.Participant {
+	for index := range match.Participants {
+		if match.Participants[index].PUUID == puuid {
+			return &match.Participants[index]
+		}
+	}
+	return nil
+}
+
+// comparisonSummary describes how many categories each player leads
+func comparisonSummary(players []models.PlayerStats, leadCounts map[string]int, metricCount int) string {
+	if metricCount == 0 {
+		return "No metrics could be compared."
+	}
+
+	ranked := make([]models.PlayerStats, len(players))
+	copy(ranked, players)
+	sort.SliceStable(ranked, func(left int, right int) bool {
+		return leadCounts[ranked[left].PUUID] > leadCounts[ranked[right].PUUID]
+	})
+
+	parts := make([]string, 0, len(ranked))
+	for _, player := range ranked {
+		parts = append(parts, fmt.Sprintf("%s leads %d", displayName(&player), leadCounts[player.PUUID]))
+	}
+
+	return fmt.Sprintf("%s of %d categories.", strings.Join(parts, ", "), metricCount)
+}
+
+// displayName returns the summoner name, or the PUUID when the name is unknown
+func displayName(playerStats *models.PlayerStats) string {
+	if playerStats.SummonerName != "" {
+		return playerStats.SummonerName
+	}
+	return playerStats.PUUID
+}
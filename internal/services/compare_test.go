@@ -0,0 +1,205 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// findMetricComparison returns the comparison for a metric or fails the test
+func findMetricComparison(t *testing.T, comparisonResult *models.ComparisonResult, metric string) models.MetricComparison {
+	t.Helper()
+
+	for _, metricComparison := range comparisonResult.Metrics {
+		if metricComparison.Metric == metric {
+			return metricComparison
+		}
+	}
+
+	t.Fatalf("Expected comparison for metric '%s'", metric)
+	return models.MetricComparison{}
+}
+
+// TestComparePlayers tests deltas, leaders, summary and shared games
+func TestComparePlayers(t *testing.T) {
+	service := NewAnalysisService()
+
+	sharedMatch := models.Match{
+		MatchID:      "shared-1",
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{PUUID: "starter", ChampionName: "Ahri", Kills: 8, Deaths: 2, Assists: 6, TotalMinionsKilled: 240, VisionScore: 30, Win: true, TeamID: 100},
+			{PUUID: "sub", ChampionName: "Lux", Kills: 2, Deaths: 5, Assists: 10, TotalMinionsKilled: 60, VisionScore: 70, Win: true, TeamID: 100},
+		},
+	}
+
+	soloMatch := models.Match{
+		MatchID:      "solo-1",
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{PUUID: "sub", ChampionName: "Lux", Kills: 4, Deaths: 5, Assists: 8, TotalMinionsKilled: 90, VisionScore: 60, Win: false, TeamID: 200},
+		},
+	}
+
+	entries := []models.ComparisonEntry{
+		{Summoner: &models.Summoner{PUUID: "starter", Name: "Starter"}, Matches: []models.Match{sharedMatch}},
+		{Summoner: &models.Summoner{PUUID: "sub", Name: "Sub"}, Matches: []models.Match{sharedMatch, soloMatch}},
+	}
+
+	comparisonResult := service.ComparePlayers(entries)
+
+	if len(comparisonResult.Players) != 2 || comparisonResult.Players[1].TotalMatches != 2 {
+		t.Fatalf("Expected both players' stats in request order, got %+v", comparisonResult.Players)
+	}
+
+	csComparison := findMetricComparison(t, comparisonResult, metricCSPerMinute)
+	if csComparison.Leader != "starter" || csComparison.Deltas["sub"] != -5.5 || csComparison.Deltas["starter"] != 0 {
+		t.Errorf("Expected starter to lead CS/min by 5.5, got %+v", csComparison)
+	}
+
+	deathsComparison := findMetricComparison(t, comparisonResult, metricDeaths)
+	if !deathsComparison.LowerIsBetter || deathsComparison.Leader != "starter" {
+		t.Errorf("Expected fewer deaths to lead, got %+v", deathsComparison)
+	}
+
+	visionComparison := findMetricComparison(t, comparisonResult, metricVisionScore)
+	if visionComparison.Leader != "sub" {
+		t.Errorf("Expected sub to lead vision, got %+v", visionComparison)
+	}
+
+	if !strings.HasPrefix(comparisonResult.Summary, "Starter leads") {
+		t.Errorf("Expected summary to start with the player leading most categories, got '%s'", comparisonResult.Summary)
+	}
+
+	if len(comparisonResult.SharedGames) != 1 {
+		t.Fatalf("Expected 1 shared game, got %+v", comparisonResult.SharedGames)
+	}
+
+	sharedGame := comparisonResult.SharedGames[0]
+	if sharedGame.MatchID != "shared-1" || !sharedGame.SameTeam || len(sharedGame.PUUIDs) != 2 {
+		t.Errorf("Expected shared-1 played on the same team by both players, got %+v", sharedGame)
+	}
+}
+
+// TestComparePlayers_AppliesOptions tests that each player's filters and mode separation are applied
+func TestComparePlayers_AppliesOptions(t *testing.T) {
+	service := NewAnalysisService()
+
+	playerMatch := func(matchID string, puuid string, gameMode string, championName string, kills int) models.Match {
+		return models.Match{
+			MatchID:      matchID,
+			GameMode:     gameMode,
+			GameDuration: 1800,
+			Participants: []models.Participant{{PUUID: puuid, ChampionName: championName, Kills: kills, Deaths: 2, TeamID: 100}},
+		}
+	}
+
+	entries := []models.ComparisonEntry{
+		{
+			Summoner: &models.Summoner{PUUID: "ranked"},
+			Matches: []models.Match{
+				playerMatch("R1", "ranked", "CLASSIC", "Ahri", 4),
+				playerMatch("R2", "ranked", "CLASSIC", "Ahri", 6),
+				playerMatch("R3", "ranked", "ARAM", "Lux", 30),
+			},
+		},
+		{
+			Summoner: &models.Summoner{PUUID: "filtered"},
+			Matches: []models.Match{
+				playerMatch("F1", "filtered", "CLASSIC", "Zed", 10),
+				playerMatch("F2", "filtered", "ARAM", "Lux", 20),
+			},
+			Options: models.AnalysisOptions{Filters: models.AnalysisFilters{Champions: []string{"Zed"}}},
+		},
+	}
+
+	comparisonResult := service.ComparePlayers(entries)
+
+	if ranked := comparisonResult.Players[0]; ranked.TotalMatches != 2 || ranked.AverageKills != 5 || comparisonResult.StatsModes["ranked"] != "CLASSIC" {
+		t.Errorf("Expected the ARAM game to be left out of the CLASSIC stats, got %+v in mode %q", ranked, comparisonResult.StatsModes["ranked"])
+	}
+	if filtered := comparisonResult.Players[1]; filtered.TotalMatches != 1 || filtered.AverageKills != 10 {
+		t.Errorf("Expected the champion filter to keep only the Zed game, got %+v", filtered)
+	}
+
+	entries[0].Options.BlendModes = true
+	comparisonResult = service.ComparePlayers(entries)
+	if ranked := comparisonResult.Players[0]; ranked.TotalMatches != 3 || comparisonResult.StatsModes["ranked"] != "ALL" {
+		t.Errorf("Expected blended modes to include every game, got %+v in mode %q", ranked, comparisonResult.StatsModes["ranked"])
+	}
+}
+
+// TestComparePlayers_SharedGamesFiltered tests that a match filtered out of a player's stats is not listed as shared
+func TestComparePlayers_SharedGamesFiltered(t *testing.T) {
+	service := NewAnalysisService()
+
+	sharedMatch := models.Match{
+		MatchID:      "shared-1",
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{PUUID: "starter", ChampionName: "Ahri", Kills: 8, Deaths: 2, TeamID: 100},
+			{PUUID: "sub", ChampionName: "Lux", Kills: 2, Deaths: 5, TeamID: 100},
+		},
+	}
+
+	soloMatch := models.Match{
+		MatchID:      "solo-1",
+		GameDuration: 1800,
+		Participants: []models.Participant{{PUUID: "sub", ChampionName: "Zed", Kills: 4, Deaths: 5, TeamID: 200}},
+	}
+
+	entries := []models.ComparisonEntry{
+		{Summoner: &models.Summoner{PUUID: "starter"}, Matches: []models.Match{sharedMatch}},
+		{
+			Summoner: &models.Summoner{PUUID: "sub"},
+			Matches:  []models.Match{sharedMatch, soloMatch},
+			Options:  models.AnalysisOptions{Filters: models.AnalysisFilters{Champions: []string{"Zed"}}},
+		},
+	}
+
+	comparisonResult := service.ComparePlayers(entries)
+
+	if sub := comparisonResult.Players[1]; sub.TotalMatches != 1 {
+		t.Fatalf("Expected the champion filter to keep only the Zed game, got %+v", sub)
+	}
+
+	if len(comparisonResult.SharedGames) != 0 {
+		t.Errorf("Expected the filtered match not to be shared, got %+v", comparisonResult.SharedGames)
+	}
+}
+
+// TestComparePlayers_Tie tests that equal values have no leader
+func TestComparePlayers_Tie(t *testing.T) {
+	service := NewAnalysisService()
+
+	match := func(puuid string) models.Match {
+		return models.Match{
+			MatchID:      "match-" + puuid,
+			GameDuration: 1800,
+			Participants: []models.Participant{{PUUID: puuid, Kills: 5, Deaths: 5, Assists: 5, TotalMinionsKilled: 180, VisionScore: 30, Win: true}},
+		}
+	}
+
+	comparisonResult := service.ComparePlayers([]models.ComparisonEntry{
+		{Summoner: &models.Summoner{PUUID: "a"}, Matches: []models.Match{match("a")}},
+		{Summoner: &models.Summoner{PUUID: "b"}, Matches: []models.Match{match("b")}},
+	})
+
+	for _, metricComparison := range comparisonResult.Metrics {
+		if metricComparison.Leader != "" {
+			t.Errorf("Expected no leader for tied metric %s, got '%s'", metricComparison.Metric, metricComparison.Leader)
+		}
+	}
+
+	if len(comparisonResult.SharedGames) != 0 {
+		t.Errorf("Expected no shared games, got %+v", comparisonResult.SharedGames)
+	}
+
+	// Team metrics are skipped because neither player has teammates in the match data
+	for _, metricComparison := range comparisonResult.Metrics {
+		if metricComparison.Metric == metricKillParticipation {
+			t.Error("Expected kill participation to be skipped without team data")
+		}
+	}
+}
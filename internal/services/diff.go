@@ -0,0 +1,222 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// Metric trends between two analyses
+const (
+	diffImproved  = "improved"
+	diffDeclined  = "declined"
+	diffUnchanged = "unchanged"
+)
+
+// Champion and role share statuses between two analyses
+const (
+	shiftAdded   = "added"
+	shiftDropped = "dropped"
+	shiftChanged = "changed"
+)
+
+// minimumShareShift is the change in share of games (percentage points) reported as a shift
+const minimumShareShift = 5.0
+
+// priorityRank orders priorities so escalations can be detected
+var priorityRank = map[string]int{
+	priorityLow:    1,
+	priorityMedium: 2,
+	priorityHigh:   3,
+}
+
+// improvementAreaKey identifies the same improvement area across analyses
+type improvementAreaKey struct {
+	category string
+	metric   string
+	champion string
+	role     string
+	opponent string
+	mode     string
+}
+
+// newImprovementAreaKey returns the key of an improvement area
+func newImprovementAreaKey(area *models.ImprovementArea) improvementAreaKey {
+	return improvementAreaKey{
+		category: area.Category,
+		metric:   area.Metric,
+		champion: area.Champion,
+		role:     area.Role,
+		opponent: area.Opponent,
+		mode:     area.Mode,
+	}
+}
+
+// DiffAnalyses reports what changed from the base analysis to the target analysis
+// Metrics come from the active rule configuration so improved/declined follows each metric's direction
+// Both analyses must be of the same player and cover the same game mode; otherwise a *FieldError
+// pointing at /target is returned
+func (analysisService *AnalysisService) DiffAnalyses(base *models.AnalysisResult, target *models.AnalysisResult) (*models.AnalysisDiff, error) {
+	if base.PlayerStats.PUUID != target.PlayerStats.PUUID {
+		return nil, newFieldError("/target", fmt.Sprintf("target analysis is of player %q, base analysis is of player %q",
+			target.PlayerStats.PUUID, base.PlayerStats.PUUID))
+	}
+
+	if base.StatsMode != target.StatsMode {
+		return nil, newFieldError("/target", fmt.Sprintf("target analysis covers game mode %q, base analysis covers game mode %q",
+			target.StatsMode, base.StatsMode))
+	}
+
+	ruleConfig := analysisService.currentRuleConfig()
+
+	analysisDiff := &models.AnalysisDiff{
+		BaseAnalyzedAt:   base.AnalyzedAt,
+		TargetAnalyzedAt: target.AnalyzedAt,
+		Metrics:          diffMetrics(ruleConfig, &base.PlayerStats, &target.PlayerStats),
+		ResolvedAreas:    []models.ImprovementArea{},
+		NewAreas:         []models.ImprovementArea{},
+		EscalatedAreas:   []models.ImprovementAreaChange{},
+		DeescalatedAreas: []models.ImprovementAreaChange{},
+		ChampionShifts:   diffShares(championShares(base.PlayerStats.ChampionPool), championShares(target.PlayerStats.ChampionPool)),
+		RoleShifts:       diffShares(base.PlayerStats.RoleDistribution, target.PlayerStats.RoleDistribution),
+	}
+
+	positiveFeedback := ruleConfig.PositiveFeedback.Category
+	baseAreas := make(map[improvementAreaKey]models.ImprovementArea)
+	for _, area := range base.ImprovementAreas {
+		if area.Category != positiveFeedback {
+			baseAreas[newImprovementAreaKey(&area)] = area
+		}
+	}
+
+	for _, area := range target.ImprovementAreas {
+		if area.Category == positiveFeedback {
+			continue
+		}
+
+		key := newImprovementAreaKey(&area)
+		baseArea, existed := baseAreas[key]
+		delete(baseAreas, key)
+
+		switch {
+		case !existed:
+			analysisDiff.NewAreas = append(analysisDiff.NewAreas, area)
+		case priorityRank[area.Priority] > priorityRank[baseArea.Priority]:
+			analysisDiff.EscalatedAreas = append(analysisDiff.EscalatedAreas, models.ImprovementAreaChange{Area: area, PreviousPriority: baseArea.Priority})
+		case priorityRank[area.Priority] < priorityRank[baseArea.Priority]:
+			analysisDiff.DeescalatedAreas = append(analysisDiff.DeescalatedAreas, models.ImprovementAreaChange{Area: area, PreviousPriority: baseArea.Priority})
+		}
+	}
+
+	// Areas left over were not found in the target, in base order
+	for _, area := range base.ImprovementAreas {
+		if _, resolved := baseAreas[newImprovementAreaKey(&area)]; resolved {
+			analysisDiff.ResolvedAreas = append(analysisDiff.ResolvedAreas, area)
+		}
+	}
+
+	return analysisDiff, nil
+}
+
+// diffMetrics compares every configured metric present in both analyses
+func diffMetrics(ruleConfig *RuleConfig, base *models.PlayerStats, target *models.PlayerStats) []models.MetricDelta {
+	baseValues := playerMetricValues(base)
+	targetValues := playerMetricValues(target)
+
+	metricDeltas := []models.MetricDelta{}
+	for _, metricRule := range ruleConfig.Metrics {
+		baseValue, inBase := baseValues[metricRule.Metric]
+		targetValue, inTarget := targetValues[metricRule.Metric]
+		if !inBase || !inTarget {
+			continue
+		}
+
+		change := roundTo(targetValue-baseValue, metricRule.Precision)
+		trend := diffUnchanged
+		switch {
+		case change > 0 && metricRule.Direction != directionLower, change < 0 && metricRule.Direction == directionLower:
+			trend = diffImproved
+		case change != 0:
+			trend = diffDeclined
+		}
+
+		metricDeltas = append(metricDeltas, models.MetricDelta{
+			Metric:      metricRule.Metric,
+			Category:    metricRule.Category,
+			BaseValue:   roundTo(baseValue, metricRule.Precision),
+			TargetValue: roundTo(targetValue, metricRule.Precision),
+			Change:      change,
+			Trend:       trend,
+		})
+	}
+
+	return metricDeltas
+}
+
+// championShares converts a champion pool's game counts into shares of games
+func championShares(championPool map[string]int) map[string]float64 {
+	totalGames := 0
+	for _, games := range championPool {
+		totalGames += games
+	}
+
+	shares := make(map[string]float64, len(championPool))
+	for champion, games := range championPool {
+		shares[champion] = float64(games) / float64(totalGames) * 100
+	}
+	return shares
+}
+
+// diffShares reports champions or roles that were added, dropped or whose share moved by at least minimumShareShift
+// Shifts are ordered by the size of the change
+func diffShares(base map[string]float64, target map[string]float64) []models.PoolShift {
+	names := make(map[string]bool)
+	for name := range base {
+		names[name] = true
+	}
+	for name := range target {
+		names[name] = true
+	}
+
+	shifts := []models.PoolShift{}
+	for name := range names {
+		baseShare, inBase := base[name]
+		targetShare, inTarget := target[name]
+		change := targetShare - baseShare
+
+		status := shiftChanged
+		switch {
+		case !inBase:
+			status = shiftAdded
+		case !inTarget:
+			status = shiftDropped
+		case change < minimumShareShift && change > -minimumShareShift:
+			continue
+		}
+
+		shifts = append(shifts, models.PoolShift{
+			Name:        name,
+			BaseShare:   roundTo(baseShare, 1),
+			TargetShare: roundTo(targetShare, 1),
+			Change:      roundTo(change, 1),
+			Status:      status,
+		})
+	}
+
+	sort.Slice(shifts, func(left int, right int) bool {
+		leftSize, rightSize := shifts[left].Change, shifts[right].Change
+		if leftSize < 0 {
+			leftSize = -leftSize
+		}
+		if rightSize < 0 {
+			rightSize = -rightSize
+		}
+		if leftSize != rightSize {
+			return leftSize > rightSize
+		}
+		return shifts[left].Name < shifts[right].Name
+	})
+
+	return shifts
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// findMetricDelta returns the delta for a metric or fails the test
+func findMetricDelta(t *testing.T, analysisDiff *models.AnalysisDiff, metric string) models.MetricDelta {
+	t.Helper()
+
+	for _, metricDelta := range analysisDiff.Metrics {
+		if metricDelta.Metric == metric {
+			return metricDelta
+		}
+	}
+
+	t.Fatalf("Expected delta for metric '%s'", metric)
+	return models.MetricDelta{}
+}
+
+// TestDiffAnalyses tests metric deltas, improvement area changes and pool shifts
+func TestDiffAnalyses(t *testing.T) {
+	service := NewAnalysisService()
+
+	base := &models.AnalysisResult{
+		PlayerStats: models.PlayerStats{
+			CSPerMinute:      5.5,
+			AverageDeaths:    6.0,
+			KDA:              2.5,
+			ChampionPool:     map[string]int{"Ahri": 8, "Zed": 2},
+			RoleDistribution: map[string]float64{"MIDDLE": 100},
+		},
+		ImprovementAreas: []models.ImprovementArea{
+			{Category: "CS (Creep Score)", Priority: "HIGH"},
+			{Category: "Deaths", Priority: "LOW"},
+			{Category: "Vision", Priority: "MEDIUM"},
+			{Category: "CS (Creep Score)", Priority: "MEDIUM", Champion: "Zed"},
+		},
+	}
+
+	target := &models.AnalysisResult{
+		PlayerStats: models.PlayerStats{
+			CSPerMinute:      6.8,
+			AverageDeaths:    7.0,
+			KDA:              2.5,
+			ChampionPool:     map[string]int{"Ahri": 5, "Sylas": 5},
+			RoleDistribution: map[string]float64{"MIDDLE": 98, "TOP": 2},
+		},
+		ImprovementAreas: []models.ImprovementArea{
+			{Category: "CS (Creep Score)", Priority: "LOW"},
+			{Category: "Deaths", Priority: "HIGH"},
+			{Category: "Damage", Priority: "MEDIUM"},
+		},
+	}
+
+	analysisDiff, err := service.DiffAnalyses(base, target)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if csDelta := findMetricDelta(t, analysisDiff, metricCSPerMinute); csDelta.Change != 1.3 || csDelta.Trend != diffImproved {
+		t.Errorf("Expected CS/min to improve by 1.3, got %+v", csDelta)
+	}
+
+	// More deaths is a decline because deaths are lower-is-better
+	if deathsDelta := findMetricDelta(t, analysisDiff, metricDeaths); deathsDelta.Trend != diffDeclined {
+		t.Errorf("Expected deaths to decline, got %+v", deathsDelta)
+	}
+
+	if kdaDelta := findMetricDelta(t, analysisDiff, metricKDA); kdaDelta.Trend != diffUnchanged {
+		t.Errorf("Expected KDA to be unchanged, got %+v", kdaDelta)
+	}
+
+	if len(analysisDiff.ResolvedAreas) != 2 || analysisDiff.ResolvedAreas[0].Category != "Vision" || analysisDiff.ResolvedAreas[1].Champion != "Zed" {
+		t.Errorf("Expected Vision and Zed CS to be resolved, got %+v", analysisDiff.ResolvedAreas)
+	}
+
+	if len(analysisDiff.NewAreas) != 1 || analysisDiff.NewAreas[0].Category != "Damage" {
+		t.Errorf("Expected Damage to be new, got %+v", analysisDiff.NewAreas)
+	}
+
+	if len(analysisDiff.EscalatedAreas) != 1 || analysisDiff.EscalatedAreas[0].Area.Category != "Deaths" || analysisDiff.EscalatedAreas[0].PreviousPriority != "LOW" {
+		t.Errorf("Expected Deaths to escalate from LOW, got %+v", analysisDiff.EscalatedAreas)
+	}
+
+	if len(analysisDiff.DeescalatedAreas) != 1 || analysisDiff.DeescalatedAreas[0].Area.Priority != "LOW" {
+		t.Errorf("Expected CS to de-escalate to LOW, got %+v", analysisDiff.DeescalatedAreas)
+	}
+
+	// Sylas added (+50), Ahri 80% -> 50% (-30), Zed dropped (-20)
+	expectedShifts := []models.PoolShift{
+		{Name: "Sylas", BaseShare: 0, TargetShare: 50, Change: 50, Status: shiftAdded},
+		{Name: "Ahri", BaseShare: 80, TargetShare: 50, Change: -30, Status: shiftChanged},
+		{Name: "Zed", BaseShare: 20, TargetShare: 0, Change: -20, Status: shiftDropped},
+	}
+	if len(analysisDiff.ChampionShifts) != len(expectedShifts) {
+		t.Fatalf("Expected %d champion shifts, got %+v", len(expectedShifts), analysisDiff.ChampionShifts)
+	}
+	for index, expectedShift := range expectedShifts {
+		if analysisDiff.ChampionShifts[index] != expectedShift {
+			t.Errorf("Expected shift %+v, got %+v", expectedShift, analysisDiff.ChampionShifts[index])
+		}
+	}
+
+	// A new role is reported even below the minimum shift; MIDDLE moved only 2 points
+	if len(analysisDiff.RoleShifts) != 1 || analysisDiff.RoleShifts[0].Name != "TOP" || analysisDiff.RoleShifts[0].Status != shiftAdded {
+		t.Errorf("Expected only TOP to be reported as added, got %+v", analysisDiff.RoleShifts)
+	}
+}
+
+// TestDiffAnalyses_IgnoresPositiveFeedback tests that positive feedback is not reported as an improvement area change
+func TestDiffAnalyses_IgnoresPositiveFeedback(t *testing.T) {
+	service := NewAnalysisService()
+	positiveFeedback := models.ImprovementArea{Category: service.currentRuleConfig().PositiveFeedback.Category, Priority: "LOW"}
+
+	analysisDiff, _ := service.DiffAnalyses(
+		&models.AnalysisResult{ImprovementAreas: []models.ImprovementArea{{Category: "Vision", Priority: "HIGH"}}},
+		&models.AnalysisResult{ImprovementAreas: []models.ImprovementArea{positiveFeedback}},
+	)
+
+	if len(analysisDiff.NewAreas) != 0 || len(analysisDiff.ResolvedAreas) != 1 {
+		t.Errorf("Expected only the resolved Vision area, got new %+v and resolved %+v", analysisDiff.NewAreas, analysisDiff.ResolvedAreas)
+	}
+}
+
+// TestDiffAnalyses_DifferentPlayers tests that analyses of different players are rejected
+func TestDiffAnalyses_DifferentPlayers(t *testing.T) {
+	_, err := NewAnalysisService().DiffAnalyses(
+		&models.AnalysisResult{PlayerStats: models.PlayerStats{PUUID: "player-1"}},
+		&models.AnalysisResult{PlayerStats: models.PlayerStats{PUUID: "player-2"}},
+	)
+
+	var fieldError *FieldError
+	if !errors.As(err, &fieldError) || fieldError.Pointer != "/target" {
+		t.Errorf("Expected a field error at /target, got %v", err)
+	}
+}
+
+// TestDiffAnalyses_MatchupAreas tests that several lane matchup areas against one opponent are told apart
+func TestDiffAnalyses_MatchupAreas(t *testing.T) {
+	service := NewAnalysisService()
+	matchupAreas := []models.ImprovementArea{
+		{Category: "Lane Matchup", Metric: "cs", Opponent: "Yasuo", Priority: "HIGH", Mode: "CLASSIC"},
+		{Category: "Lane Matchup", Metric: "gold", Opponent: "Yasuo", Priority: "MEDIUM", Mode: "CLASSIC"},
+	}
+
+	analysisDiff, err := service.DiffAnalyses(
+		&models.AnalysisResult{StatsMode: "CLASSIC", ImprovementAreas: matchupAreas},
+		&models.AnalysisResult{StatsMode: "CLASSIC", ImprovementAreas: matchupAreas},
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(analysisDiff.NewAreas) != 0 || len(analysisDiff.ResolvedAreas) != 0 || len(analysisDiff.EscalatedAreas) != 0 || len(analysisDiff.DeescalatedAreas) != 0 {
+		t.Errorf("Expected unchanged matchup areas, got %+v", analysisDiff)
+	}
+}
+
+// TestDiffAnalyses_DifferentModes tests that analyses of different game modes are rejected
+func TestDiffAnalyses_DifferentModes(t *testing.T) {
+	_, err := NewAnalysisService().DiffAnalyses(
+		&models.AnalysisResult{StatsMode: "CLASSIC"},
+		&models.AnalysisResult{StatsMode: "ARAM"},
+	)
+
+	var fieldError *FieldError
+	if !errors.As(err, &fieldError) || fieldError.Pointer != "/target" || !strings.Contains(fieldError.Message, "ARAM") {
+		t.Errorf("Expected a field error at /target naming the mode, got %v", err)
+	}
+}
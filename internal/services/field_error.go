@@ -0,0 +1,19 @@
+package services
+
+// FieldError is a validation failure tied to a single field of an analysis request
+type FieldError struct {
+	// JSON pointer to the field within an analysis request (e.g., /summoner/tier, /options/filters/roles/0)
+	Pointer string
+	// Description of what is wrong with the field
+	Message string
+}
+
+// Error returns the failure description
+func (fieldError *FieldError) Error() string {
+	return fieldError.Message
+}
+
+// newFieldError creates a FieldError for the field at pointer
+func newFieldError(pointer string, message string) *FieldError {
+	return &FieldError{Pointer: pointer, Message: message}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// Game mode keys used for per-mode statistics
+const (
+	// modeAll marks statistics blended across every game mode
+	modeAll = "ALL"
+	// modeUnknown groups matches without a game mode
+	modeUnknown = "UNKNOWN"
+)
+
+// ValidateFilters checks that the requested filters can match anything
+// Every failure is reported, returned together as ValidationErrors
+func ValidateFilters(filters models.AnalysisFilters) error {
+	var validationErrors ValidationErrors
+	for index, role := range filters.Roles {
+		if normalizeRole(role) == "" {
+			validationErrors = append(validationErrors, newFieldError(fmt.Sprintf("/options/filters/roles/%d", index), fmt.Sprintf("unknown role %q", role)))
+		}
+	}
+
+	if filters.From != nil && filters.To != nil && filters.From.After(*filters.To) {
+		validationErrors = append(validationErrors, newFieldError("/options/filters/from", fmt.Sprintf("from (%s) is after to (%s)", filters.From.Format(time.RFC3339), filters.To.Format(time.RFC3339))))
+	}
+
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+// filterMatches returns the matches that satisfy every filter
+// Champion and role filters apply to the analyzed player's own participation
+func filterMatches(summoner *models.Summoner, matches []models.Match, filters models.AnalysisFilters) []models.Match {
+	filtered := make([]models.Match, 0, len(matches))
+	for matchIndex := range matches {
+		if matchesFilters(summoner, &matches[matchIndex], filters) {
+			filtered = append(filtered, matches[matchIndex])
+		}
+	}
+	return filtered
+}
+
+// matchesFilters reports whether a single match satisfies every filter
+func matchesFilters(summoner *models.Summoner, match *models.Match, filters models.AnalysisFilters) bool {
+	if len(filters.GameModes) > 0 && !containsFold(filters.GameModes, match.GameMode) {
+		return false
+	}
+
+	if len(filters.QueueIDs) > 0 && !containsInt(filters.QueueIDs, match.QueueID) {
+		return false
+	}
+
+	if filters.From != nil && match.GameCreation.Before(*filters.From) {
+		return false
+	}
+
+	if filters.To != nil && match.GameCreation.After(*filters.To) {
+		return false
+	}
+
+	if len(filters.Champions) == 0 && len(filters.Roles) == 0 {
+		return true
+	}
+
+	participant := findParticipant(match, summoner.PUUID)
+	if participant == nil {
+		return false
+	}
+
+	if len(filters.Champions) > 0 && !containsFold(filters.Champions, participant.ChampionName) {
+		return false
+	}
+
+	if len(filters.Roles) > 0 {
+		role := normalizeRole(participant.TeamPosition)
+		matched := false
+		for _, filterRole := range filters.Roles {
+			if normalizeRole(filterRole) == role {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// groupByMode splits matches by game mode
+func groupByMode(matches []models.Match) map[string][]models.Match {
+	modeMatches := make(map[string][]models.Match)
+	for _, match := range matches {
+		mode := matchMode(&match)
+		modeMatches[mode] = append(modeMatches[mode], match)
+	}
+	return modeMatches
+}
+
+// selectStatsMode groups matches by mode and returns the mode statistics are computed over with its matches
+// Modes are analyzed separately by default and the most played mode is used; blended matches report ALL
+func selectStatsMode(matches []models.Match, blendModes bool) (string, map[string][]models.Match, []models.Match) {
+	modeMatches := groupByMode(matches)
+	if blendModes || len(modeMatches) == 0 {
+		return modeAll, modeMatches, matches
+	}
+
+	statsMode := primaryMode(modeMatches)
+	return statsMode, modeMatches, modeMatches[statsMode]
+}
+
+// otherModes returns the modes, sorted, that have matches but are not covered by statsMode
+func otherModes(statsMode string, modeMatches map[string][]models.Match) []string {
+	if statsMode == modeAll {
+		return nil
+	}
+
+	var modes []string
+	for mode := range modeMatches {
+		if mode != statsMode {
+			modes = append(modes, mode)
+		}
+	}
+	sort.Strings(modes)
+	return modes
+}
+
+// labelSectionModes records the mode the lane matchup, synergy and trends sections were computed over
+func labelSectionModes(analysisResult *models.AnalysisResult, statsMode string) {
+	if analysisResult.LaneMatchup != nil {
+		analysisResult.LaneMatchup.Mode = statsMode
+	}
+	if analysisResult.Synergy != nil {
+		analysisResult.Synergy.Mode = statsMode
+	}
+	if analysisResult.Trends != nil {
+		analysisResult.Trends.Mode = statsMode
+	}
+}
+
+// matchMode returns the normalized game mode a match is grouped under
+func matchMode(match *models.Match) string {
+	mode := strings.ToUpper(strings.TrimSpace(match.GameMode))
+	if mode == "" {
+		return modeUnknown
+	}
+	return mode
+}
+
+// primaryMode returns the mode with the most matches (ties broken alphabetically)
+func primaryMode(modeMatches map[string][]models.Match) string {
+	modeCounts := make(map[string]int, len(modeMatches))
+	for mode, matches := range modeMatches {
+		modeCounts[mode] = len(matches)
+	}
+	return mostPlayedMode(modeCounts)
+}
+
+// mostPlayedMode returns the mode with the highest match count (ties broken alphabetically)
+func mostPlayedMode(modeCounts map[string]int) string {
+	modes := make([]string, 0, len(modeCounts))
+	for mode := range modeCounts {
+		modes = append(modes, mode)
+	}
+
+	sort.Slice(modes, func(left int, right int) bool {
+		if modeCounts[modes[left]] != modeCounts[modes[right]] {
+			return modeCounts[modes[left]] > modeCounts[modes[right]]
+		}
+		return modes[left] < modes[right]
+	})
+
+	if len(modes) == 0 {
+		return ""
+	}
+	return modes[0]
+}
+
+// calculateModeStats returns separate statistics per game mode
+// Returns nil when the matches span fewer than two modes
+func (analysisService *AnalysisService) calculateModeStats(summoner *models.Summoner, modeMatches map[string][]models.Match) map[string]models.PlayerStats {
+	if len(modeMatches) < 2 {
+		return nil
+	}
+
+	modeStats := make(map[string]models.PlayerStats, len(modeMatches))
+	for mode, matches := range modeMatches {
+		modeStats[mode] = analysisService.calculatePlayerStats(summoner, matches)
+	}
+	return modeStats
+}
+
+// containsFold reports whether values contains target, ignoring case and surrounding spaces
+func containsFold(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(strings.TrimSpace(value), strings.TrimSpace(target)) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt reports whether values contains target
+func containsInt(values []int, target int) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// filterMatch builds a match for test-puuid with the given mode, queue, day, champion and position
+func filterMatch(matchID string, gameMode string, queueID int, day int, championName string, teamPosition string) models.Match {
+	return models.Match{
+		MatchID:      matchID,
+		GameMode:     gameMode,
+		QueueID:      queueID,
+		GameCreation: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, day),
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{PUUID: "test-puuid", ChampionName: championName, TeamPosition: teamPosition, Kills: 5, Deaths: 3, Assists: 7, TotalMinionsKilled: 180, Win: true},
+		},
+	}
+}
+
+// TestFilterMatches tests each request-level filter
+func TestFilterMatches(t *testing.T) {
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+	matches := []models.Match{
+		filterMatch("ranked-ahri", "CLASSIC", 420, 0, "Ahri", "MIDDLE"),
+		filterMatch("flex-lux", "CLASSIC", 440, 1, "Lux", "UTILITY"),
+		filterMatch("aram-jinx", "ARAM", 450, 2, "Jinx", ""),
+	}
+
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		filters  models.AnalysisFilters
+		expected []string
+	}{
+		{"no filters", models.AnalysisFilters{}, []string{"ranked-ahri", "flex-lux", "aram-jinx"}},
+		{"game mode ignores case", models.AnalysisFilters{GameModes: []string{"aram"}}, []string{"aram-jinx"}},
+		{"queue IDs", models.AnalysisFilters{QueueIDs: []int{420, 440}}, []string{"ranked-ahri", "flex-lux"}},
+		{"inclusive date range", models.AnalysisFilters{From: &from, To: &to}, []string{"flex-lux", "aram-jinx"}},
+		{"champion", models.AnalysisFilters{Champions: []string{"ahri"}}, []string{"ranked-ahri"}},
+		{"role alias", models.AnalysisFilters{Roles: []string{"support"}}, []string{"flex-lux"}},
+		{"combined", models.AnalysisFilters{GameModes: []string{"CLASSIC"}, Champions: []string{"Jinx"}}, []string{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			filtered := filterMatches(summoner, matches, testCase.filters)
+			if len(filtered) != len(testCase.expected) {
+				t.Fatalf("Expected %v, got %d matches", testCase.expected, len(filtered))
+			}
+			for index, match := range filtered {
+				if match.MatchID != testCase.expected[index] {
+					t.Errorf("Expected match '%s' at %d, got '%s'", testCase.expected[index], index, match.MatchID)
+				}
+			}
+		})
+	}
+}
+
+// TestValidateFilters tests rejected filter combinations
+func TestValidateFilters(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if err := ValidateFilters(models.AnalysisFilters{Roles: []string{"MID", "jungle"}}); err != nil {
+		t.Errorf("Expected known roles to be accepted, got %v", err)
+	}
+
+	if err := ValidateFilters(models.AnalysisFilters{Roles: []string{"SWEEPER"}}); err == nil {
+		t.Error("Expected unknown role to be rejected")
+	}
+
+	if err := ValidateFilters(models.AnalysisFilters{From: &from, To: &to}); err == nil {
+		t.Error("Expected from after to to be rejected")
+	}
+
+	// Every problem is reported, not just the first
+	err := ValidateFilters(models.AnalysisFilters{Roles: []string{"SWEEPER", "MID", "GOALIE"}, From: &from, To: &to})
+	var validationErrors ValidationErrors
+	if !errors.As(err, &validationErrors) || len(validationErrors) != 3 {
+		t.Fatalf("Expected 3 validation errors, got %v", err)
+	}
+
+	expectedPointers := []string{"/options/filters/roles/0", "/options/filters/roles/2", "/options/filters/from"}
+	for index, fieldError := range validationErrors {
+		if fieldError.Pointer != expectedPointers[index] {
+			t.Errorf("Expected error %d at %s, got %+v", index, expectedPointers[index], fieldError)
+		}
+	}
+}
+
+// TestAnalyzePlayerWithOptions_ModeStats tests that modes are analyzed separately unless blended
+func TestAnalyzePlayerWithOptions_ModeStats(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+	matches := []models.Match{
+		filterMatch("classic-1", "CLASSIC", 420, 0, "Ahri", "MIDDLE"),
+		filterMatch("classic-2", "CLASSIC", 420, 1, "Ahri", "MIDDLE"),
+		filterMatch("aram-1", "ARAM", 450, 2, "Jinx", ""),
+	}
+
+	result := service.AnalyzePlayerWithOptions(context.Background(), summoner, matches, models.AnalysisOptions{})
+
+	if result.StatsMode != "CLASSIC" || result.PlayerStats.TotalMatches != 2 {
+		t.Errorf("Expected CLASSIC stats over 2 matches, got mode '%s' over %d matches", result.StatsMode, result.PlayerStats.TotalMatches)
+	}
+
+	if len(result.ModeStats) != 2 || result.ModeStats["ARAM"].TotalMatches != 1 {
+		t.Errorf("Expected separate CLASSIC and ARAM stats, got %+v", result.ModeStats)
+	}
+
+	if !reflect.DeepEqual(result.OtherModes, []string{"ARAM"}) {
+		t.Errorf("Expected ARAM to be reported as left out, got %+v", result.OtherModes)
+	}
+
+	for _, area := range result.ImprovementAreas {
+		if area.Mode != "CLASSIC" {
+			t.Errorf("Expected improvement areas to be labelled CLASSIC, got %+v", area)
+		}
+	}
+
+	blended := service.AnalyzePlayerWithOptions(context.Background(), summoner, matches, models.AnalysisOptions{BlendModes: true})
+	if blended.StatsMode != modeAll || blended.PlayerStats.TotalMatches != 3 || blended.OtherModes != nil {
+		t.Errorf("Expected blended stats over 3 matches, got mode '%s' over %d matches leaving out %+v", blended.StatsMode, blended.PlayerStats.TotalMatches, blended.OtherModes)
+	}
+
+	filtered := service.AnalyzePlayerWithOptions(context.Background(), summoner, matches, models.AnalysisOptions{Filters: models.AnalysisFilters{GameModes: []string{"ARAM"}}})
+	if filtered.StatsMode != "ARAM" || filtered.ModeStats != nil {
+		t.Errorf("Expected only ARAM stats without a per-mode breakdown, got mode '%s' and %+v", filtered.StatsMode, filtered.ModeStats)
+	}
+}
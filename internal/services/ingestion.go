@@ -0,0 +1,222 @@
+package services
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/requestctx"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
+)
+
+// DefaultMaxCachedAggregates bounds the running aggregates held in memory; the least recently used
+// player's aggregate is evicted beyond it and rebuilt from the store when next needed
+const DefaultMaxCachedAggregates = 10000
+
+// MatchIngestor appends pushed matches to each player's stored match history and keeps
+// running all-time aggregates, so statistics never require re-sending or re-aggregating the history
+type MatchIngestor struct {
+	analysisService *AnalysisService
+	matchStore      storage.MatchStore
+	// Maximum number of players whose aggregates are cached
+	maxCachedAggregates int
+
+	mutex sync.Mutex
+	// Cached aggregates per PUUID, pointing into recentlyUsed
+	aggregates map[string]*list.Element
+	// Cached aggregates, most recently used first
+	recentlyUsed *list.List
+}
+
+// playerAggregate holds a player's running totals per game mode over every stored match that passed outlier screening
+type playerAggregate struct {
+	puuid string
+	modes map[string]*playerStatsAccumulator
+}
+
+// newPlayerAggregate creates an empty aggregate for a player
+func newPlayerAggregate(puuid string) *playerAggregate {
+	return &playerAggregate{puuid: puuid, modes: make(map[string]*playerStatsAccumulator)}
+}
+
+// add records a match under its game mode
+func (aggregate *playerAggregate) add(match *models.Match) {
+	mode := matchMode(match)
+	accumulator, exists := aggregate.modes[mode]
+	if !exists {
+		accumulator = newPlayerStatsAccumulator(aggregate.puuid)
+		aggregate.modes[mode] = accumulator
+	}
+	accumulator.add(match)
+}
+
+// NewMatchIngestor creates a MatchIngestor keeping match histories in matchStore
+func NewMatchIngestor(analysisService *AnalysisService, matchStore storage.MatchStore) *MatchIngestor {
+	return &MatchIngestor{
+		analysisService:     analysisService,
+		matchStore:          matchStore,
+		maxCachedAggregates: DefaultMaxCachedAggregates,
+		aggregates:          make(map[string]*list.Element),
+		recentlyUsed:        list.New(),
+	}
+}
+
+// Ingest appends matches to a player's stored history and updates the player's running aggregates
+// Matches are validated leniently first, and matches without a match ID or game creation time are dropped
+// with the other invalid input. Matches already stored are ignored; only new matches are screened and
+// added to the aggregates
+func (matchIngestor *MatchIngestor) Ingest(ctx context.Context, summoner *models.Summoner, matches []models.Match) (*models.IngestResult, error) {
+	receivedMatches := len(matches)
+	matches, warnings := screenInvalidMatches(summoner, matches, true)
+
+	matchIngestor.mutex.Lock()
+	defer matchIngestor.mutex.Unlock()
+
+	// Load the aggregate before appending so the new matches are not counted twice
+	aggregate, err := matchIngestor.aggregate(ctx, summoner)
+	if err != nil {
+		return nil, err
+	}
+
+	_, span := tracing.Start(ctx, "storage.AppendMatches")
+	span.SetAttribute("matches", len(matches))
+	added, err := matchIngestor.matchStore.AppendMatches(summoner.PUUID, matches)
+	span.SetError(err)
+	span.End()
+	if err != nil {
+		return nil, err
+	}
+
+	screened, excludedMatches := screenMatches(summoner, added)
+	for matchIndex := range screened {
+		aggregate.add(&screened[matchIndex])
+	}
+	if len(added) > 0 {
+		matchIngestor.cache(aggregate)
+	}
+
+	requestctx.Logger(ctx).Debug().
+		Str("puuid", summoner.PUUID).
+		Int("received_matches", receivedMatches).
+		Int("added_matches", len(added)).
+		Int("excluded_matches", len(excludedMatches)).
+		Msg("Matches ingested")
+
+	return &models.IngestResult{
+		PUUID:           summoner.PUUID,
+		Received:        len(matches),
+		Added:           len(added),
+		Duplicates:      len(matches) - len(added),
+		ExcludedMatches: excludedMatches,
+		Warnings:        warnings,
+		AllTimeStats:    matchIngestor.allTimeStats(summoner, aggregate),
+	}, nil
+}
+
+// Stats returns a player's all-time statistics per game mode from the running aggregates
+// Like an analysis, PlayerStats covers the most played mode and ModeStats each mode when there are several
+func (matchIngestor *MatchIngestor) Stats(ctx context.Context, summoner *models.Summoner) (*models.AllTimeStats, error) {
+	matchIngestor.mutex.Lock()
+	defer matchIngestor.mutex.Unlock()
+
+	aggregate, err := matchIngestor.aggregate(ctx, summoner)
+	if err != nil {
+		return nil, err
+	}
+
+	allTimeStats := matchIngestor.allTimeStats(summoner, aggregate)
+	return &allTimeStats, nil
+}
+
+// AnalyzeRecent analyzes a player's last N stored matches, or the whole history when lastN is zero
+// The full analysis pipeline runs, so filters, mode separation and outlier screening apply as usual
+func (matchIngestor *MatchIngestor) AnalyzeRecent(ctx context.Context, summoner *models.Summoner, lastN int, options models.AnalysisOptions) (*models.AnalysisResult, error) {
+	matches, err := matchIngestor.recentMatches(ctx, summoner.PUUID, lastN)
+	if err != nil {
+		return nil, err
+	}
+
+	return matchIngestor.analysisService.AnalyzePlayerWithOptions(ctx, summoner, matches, options), nil
+}
+
+// aggregate returns the running aggregate of a player, rebuilding it from the stored history
+// when it is not held in memory (e.g., after a restart with a persistent store or after eviction)
+// Rebuilt aggregates are cached only when the player has stored matches, so lookups of unknown
+// players do not grow the cache. The caller must hold the mutex
+func (matchIngestor *MatchIngestor) aggregate(ctx context.Context, summoner *models.Summoner) (*playerAggregate, error) {
+	if element, exists := matchIngestor.aggregates[summoner.PUUID]; exists {
+		matchIngestor.recentlyUsed.MoveToFront(element)
+		return element.Value.(*playerAggregate), nil
+	}
+
+	storedMatches, err := matchIngestor.recentMatches(ctx, summoner.PUUID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate := newPlayerAggregate(summoner.PUUID)
+	screened, _ := screenMatches(summoner, storedMatches)
+	for matchIndex := range screened {
+		aggregate.add(&screened[matchIndex])
+	}
+
+	if len(storedMatches) > 0 {
+		matchIngestor.cache(aggregate)
+	}
+	return aggregate, nil
+}
+
+// cache stores a player's aggregate as the most recently used, evicting the least recently used beyond the limit
+// The caller must hold the mutex
+func (matchIngestor *MatchIngestor) cache(aggregate *playerAggregate) {
+	if element, exists := matchIngestor.aggregates[aggregate.puuid]; exists {
+		element.Value = aggregate
+		matchIngestor.recentlyUsed.MoveToFront(element)
+		return
+	}
+
+	matchIngestor.aggregates[aggregate.puuid] = matchIngestor.recentlyUsed.PushFront(aggregate)
+	for matchIngestor.recentlyUsed.Len() > matchIngestor.maxCachedAggregates {
+		oldest := matchIngestor.recentlyUsed.Back()
+		matchIngestor.recentlyUsed.Remove(oldest)
+		delete(matchIngestor.aggregates, oldest.Value.(*playerAggregate).puuid)
+	}
+}
+
+// allTimeStats converts a player's aggregate into statistics for the most played mode and each mode
+func (matchIngestor *MatchIngestor) allTimeStats(summoner *models.Summoner, aggregate *playerAggregate) models.AllTimeStats {
+	modeCounts := make(map[string]int, len(aggregate.modes))
+	for mode, accumulator := range aggregate.modes {
+		modeCounts[mode] = accumulator.matchCount
+	}
+
+	statsMode := mostPlayedMode(modeCounts)
+	if statsMode == "" {
+		return models.AllTimeStats{PlayerStats: matchIngestor.analysisService.toPlayerStats(summoner, newPlayerStatsAccumulator(summoner.PUUID))}
+	}
+
+	allTimeStats := models.AllTimeStats{
+		PlayerStats: matchIngestor.analysisService.toPlayerStats(summoner, aggregate.modes[statsMode]),
+		StatsMode:   statsMode,
+	}
+	if len(aggregate.modes) > 1 {
+		allTimeStats.ModeStats = make(map[string]models.PlayerStats, len(aggregate.modes))
+		for mode, accumulator := range aggregate.modes {
+			allTimeStats.ModeStats[mode] = matchIngestor.analysisService.toPlayerStats(summoner, accumulator)
+		}
+	}
+	return allTimeStats
+}
+
+// recentMatches loads a player's last N stored matches inside a storage span
+func (matchIngestor *MatchIngestor) recentMatches(ctx context.Context, puuid string, lastN int) ([]models.Match, error) {
+	_, span := tracing.Start(ctx, "storage.RecentMatches")
+	defer span.End()
+
+	matches, err := matchIngestor.matchStore.RecentMatches(puuid, lastN)
+	span.SetError(err)
+	span.SetAttribute("matches", len(matches))
+	return matches, err
+}
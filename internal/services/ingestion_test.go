@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+)
+
+// ingestionMatch builds a normal match created on the given day of January 2024
+func ingestionMatch(matchID string, day int, kills int) models.Match {
+	match := outlierMatch(matchID)
+	match.GameCreation = time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC)
+	match.Participants[0].Kills = kills
+	return match
+}
+
+// TestMatchIngestor_Ingest tests deduplication and that running aggregates match a full recalculation
+func TestMatchIngestor_Ingest(t *testing.T) {
+	analysisService := NewAnalysisService()
+	matchIngestor := NewMatchIngestor(analysisService, storage.NewMemoryStore())
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	remake := ingestionMatch("EUW1_3", 3, 0)
+	remake.GameDuration = 200
+
+	firstResult, err := matchIngestor.Ingest(context.Background(), summoner, []models.Match{ingestionMatch("EUW1_1", 1, 4), ingestionMatch("EUW1_2", 2, 8)})
+	if err != nil {
+		t.Fatalf("Failed to ingest matches: %v", err)
+	}
+	if firstResult.Added != 2 || firstResult.Duplicates != 0 || firstResult.PlayerStats.TotalMatches != 2 {
+		t.Errorf("Unexpected first ingest result %+v", firstResult)
+	}
+
+	// EUW1_2 is re-sent and ignored; the remake is stored but left out of the aggregates
+	secondResult, err := matchIngestor.Ingest(context.Background(), summoner, []models.Match{ingestionMatch("EUW1_2", 2, 8), remake, ingestionMatch("EUW1_4", 4, 12)})
+	if err != nil {
+		t.Fatalf("Failed to ingest matches: %v", err)
+	}
+	if secondResult.Received != 3 || secondResult.Added != 2 || secondResult.Duplicates != 1 {
+		t.Errorf("Expected 2 added and 1 duplicate, got %+v", secondResult)
+	}
+	if len(secondResult.ExcludedMatches) != 1 || secondResult.ExcludedMatches[0].Reason != exclusionRemake {
+		t.Errorf("Expected the remake to be excluded, got %+v", secondResult.ExcludedMatches)
+	}
+
+	expectedStats := analysisService.calculatePlayerStats(summoner, []models.Match{
+		ingestionMatch("EUW1_1", 1, 4), ingestionMatch("EUW1_2", 2, 8), ingestionMatch("EUW1_4", 4, 12),
+	})
+	if !reflect.DeepEqual(secondResult.PlayerStats, expectedStats) {
+		t.Errorf("Expected running aggregates to equal a full recalculation\ngot:  %+v\nwant: %+v", secondResult.PlayerStats, expectedStats)
+	}
+
+	allTimeStats, err := matchIngestor.Stats(context.Background(), summoner)
+	if err != nil {
+		t.Fatalf("Failed to load stats: %v", err)
+	}
+	if allTimeStats.PlayerStats.TotalMatches != 3 || allTimeStats.PlayerStats.AverageKills != 8 {
+		t.Errorf("Expected 3 matches averaging 8 kills, got %d and %f", allTimeStats.PlayerStats.TotalMatches, allTimeStats.PlayerStats.AverageKills)
+	}
+}
+
+// TestMatchIngestor_ValidatesMatches tests that matches which cannot be stored or analyzed are dropped with warnings
+func TestMatchIngestor_ValidatesMatches(t *testing.T) {
+	matchIngestor := NewMatchIngestor(NewAnalysisService(), storage.NewMemoryStore())
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	missingCreation := ingestionMatch("EUW1_2", 2, 8)
+	missingCreation.GameCreation = time.Time{}
+	otherPlayer := ingestionMatch("EUW1_3", 3, 8)
+	otherPlayer.Participants[0].PUUID = "someone-else"
+
+	ingestResult, err := matchIngestor.Ingest(context.Background(), summoner, []models.Match{ingestionMatch("EUW1_1", 1, 4), missingCreation, otherPlayer})
+	if err != nil {
+		t.Fatalf("Failed to ingest matches: %v", err)
+	}
+
+	if ingestResult.Received != 1 || ingestResult.Added != 1 {
+		t.Errorf("Expected only the valid match to be added, got %+v", ingestResult)
+	}
+
+	if len(ingestResult.Warnings) != 2 || ingestResult.Warnings[0].Pointer != "/matches/1" || ingestResult.Warnings[1].Pointer != "/matches/2" {
+		t.Errorf("Expected warnings for /matches/1 and /matches/2, got %+v", ingestResult.Warnings)
+	}
+}
+
+// TestMatchIngestor_SeparatesModes tests that all-time stats cover the most played mode with per-mode breakdowns
+func TestMatchIngestor_SeparatesModes(t *testing.T) {
+	matchIngestor := NewMatchIngestor(NewAnalysisService(), storage.NewMemoryStore())
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches := []models.Match{ingestionMatch("EUW1_1", 1, 4), ingestionMatch("EUW1_2", 2, 8), ingestionMatch("EUW1_3", 3, 20)}
+	matches[0].GameMode, matches[1].GameMode, matches[2].GameMode = "CLASSIC", "CLASSIC", "ARAM"
+	ingestResult, err := matchIngestor.Ingest(context.Background(), summoner, matches)
+	if err != nil {
+		t.Fatalf("Failed to ingest matches: %v", err)
+	}
+
+	if ingestResult.StatsMode != "CLASSIC" || ingestResult.PlayerStats.TotalMatches != 2 || ingestResult.PlayerStats.AverageKills != 6 {
+		t.Errorf("Expected stats over the 2 CLASSIC matches, got mode %q with %+v", ingestResult.StatsMode, ingestResult.PlayerStats)
+	}
+	if len(ingestResult.ModeStats) != 2 || ingestResult.ModeStats["ARAM"].AverageKills != 20 {
+		t.Errorf("Expected CLASSIC and ARAM mode stats, got %+v", ingestResult.ModeStats)
+	}
+}
+
+// TestMatchIngestor_BoundsCachedAggregates tests that unknown players are not cached and the cache evicts beyond its limit
+func TestMatchIngestor_BoundsCachedAggregates(t *testing.T) {
+	matchIngestor := NewMatchIngestor(NewAnalysisService(), storage.NewMemoryStore())
+	matchIngestor.maxCachedAggregates = 2
+
+	allTimeStats, err := matchIngestor.Stats(context.Background(), &models.Summoner{PUUID: "never-ingested"})
+	if err != nil || allTimeStats.PlayerStats.TotalMatches != 0 {
+		t.Fatalf("Expected empty stats for an unknown player, got %+v (%v)", allTimeStats, err)
+	}
+	if len(matchIngestor.aggregates) != 0 {
+		t.Errorf("Expected no aggregate to be cached for an unknown player, got %d", len(matchIngestor.aggregates))
+	}
+
+	for _, puuid := range []string{"player-1", "player-2", "player-3"} {
+		summoner := &models.Summoner{PUUID: puuid}
+		match := ingestionMatch("EUW1_"+puuid, 1, 4)
+		match.Participants[0].PUUID = puuid
+		matchIngestor.Ingest(context.Background(), summoner, []models.Match{match})
+	}
+	if _, exists := matchIngestor.aggregates["player-1"]; exists || len(matchIngestor.aggregates) != 2 {
+		t.Errorf("Expected the least recently used aggregate to be evicted, got %d cached", len(matchIngestor.aggregates))
+	}
+
+	// An evicted aggregate is rebuilt from the store
+	allTimeStats, _ = matchIngestor.Stats(context.Background(), &models.Summoner{PUUID: "player-1"})
+	if allTimeStats.PlayerStats.TotalMatches != 1 {
+		t.Errorf("Expected the evicted aggregate to be rebuilt, got %+v", allTimeStats.PlayerStats)
+	}
+}
+
+// TestMatchIngestor_RebuildsAggregates tests that aggregates are rebuilt from the store by a new ingestor
+func TestMatchIngestor_RebuildsAggregates(t *testing.T) {
+	analysisService := NewAnalysisService()
+	matchStore := storage.NewMemoryStore()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	NewMatchIngestor(analysisService, matchStore).Ingest(context.Background(), summoner, []models.Match{ingestionMatch("EUW1_1", 1, 4), ingestionMatch("EUW1_2", 2, 8)})
+
+	restartedIngestor := NewMatchIngestor(analysisService, matchStore)
+	ingestResult, err := restartedIngestor.Ingest(context.Background(), summoner, []models.Match{ingestionMatch("EUW1_2", 2, 8), ingestionMatch("EUW1_3", 3, 12)})
+	if err != nil {
+		t.Fatalf("Failed to ingest matches: %v", err)
+	}
+
+	if ingestResult.Added != 1 || ingestResult.PlayerStats.TotalMatches != 3 {
+		t.Errorf("Expected 1 new match on top of 2 stored, got %+v", ingestResult)
+	}
+}
+
+// TestMatchIngestor_AnalyzeRecent tests analyzing the last N stored matches
+func TestMatchIngestor_AnalyzeRecent(t *testing.T) {
+	matchIngestor := NewMatchIngestor(NewAnalysisService(), storage.NewMemoryStore())
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matchIngestor.Ingest(context.Background(), summoner, []models.Match{ingestionMatch("EUW1_3", 3, 12), ingestionMatch("EUW1_1", 1, 4), ingestionMatch("EUW1_2", 2, 8)})
+
+	analysisResult, err := matchIngestor.AnalyzeRecent(context.Background(), summoner, 2, models.AnalysisOptions{})
+	if err != nil {
+		t.Fatalf("Failed to analyze stored matches: %v", err)
+	}
+	if analysisResult.PlayerStats.TotalMatches != 2 || analysisResult.PlayerStats.AverageKills != 10 {
+		t.Errorf("Expected the 2 most recent matches averaging 10 kills, got %d and %f", analysisResult.PlayerStats.TotalMatches, analysisResult.PlayerStats.AverageKills)
+	}
+
+	analysisResult, _ = matchIngestor.AnalyzeRecent(context.Background(), summoner, 0, models.AnalysisOptions{})
+	if analysisResult.PlayerStats.TotalMatches != 3 {
+		t.Errorf("Expected all 3 stored matches, got %d", analysisResult.PlayerStats.TotalMatches)
+	}
+}
@@ -1,10 +1,35 @@
 package services
 
-import "github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+import (
+	"context"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
 
 // AnalysisServiceInterface defines the interface for analysis service operations
 // This interface enables mocking in tests
 type AnalysisServiceInterface interface {
 	// AnalyzePlayer performs comprehensive analysis on a player's match history
 	AnalyzePlayer(summoner *models.Summoner, matches []models.Match) *models.AnalysisResult
+	// AnalyzePlayerWithOptions performs analysis using request-level options such as the climb goal,
+	// logging and tracing through the request-scoped logger and span in ctx
+	// Matches are validated leniently first; dropped input is reported in the result's warnings
+	AnalyzePlayerWithOptions(ctx context.Context, summoner *models.Summoner, matches []models.Match, options models.AnalysisOptions) *models.AnalysisResult
+	// ComparePlayers puts two or more players side by side with per-metric deltas and leaders
+	ComparePlayers(entries []models.ComparisonEntry) *models.ComparisonResult
+	// DiffAnalyses reports what changed between an earlier and a later analysis of a player
+	DiffAnalyses(base *models.AnalysisResult, target *models.AnalysisResult) (*models.AnalysisDiff, error)
+	// ReloadRules re-reads the rule file and returns the version of the newly active rules
+	ReloadRules() (string, error)
+}
+
+// MatchIngestorInterface defines the interface for stored match history operations
+// This interface enables mocking in tests
+type MatchIngestorInterface interface {
+	// Ingest appends matches to a player's stored history and updates the player's running aggregates
+	Ingest(ctx context.Context, summoner *models.Summoner, matches []models.Match) (*models.IngestResult, error)
+	// Stats returns a player's all-time statistics per game mode from the running aggregates
+	Stats(ctx context.Context, summoner *models.Summoner) (*models.AllTimeStats, error)
+	// AnalyzeRecent analyzes a player's last N stored matches, or the whole history when lastN is zero
+	AnalyzeRecent(ctx context.Context, summoner *models.Summoner, lastN int, options models.AnalysisOptions) (*models.AnalysisResult, error)
 }
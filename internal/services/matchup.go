@@ -0,0 +1,220 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// Supported lane differential identifiers for matchup rules
+const (
+	differentialCS     = "cs"
+	differentialGold   = "gold"
+	differentialDamage = "damage"
+	differentialVision = "vision"
+)
+
+// supportedDifferentials lists the differential identifiers matchup rules can evaluate
+var supportedDifferentials = map[string]bool{
+	differentialCS:     true,
+	differentialGold:   true,
+	differentialDamage: true,
+	differentialVision: true,
+}
+
+// matchupAccumulator sums the player's differentials against lane opponents
+type matchupAccumulator struct {
+	games  int
+	wins   int
+	cs     int
+	gold   int
+	damage int
+	vision int
+}
+
+// add records a single game against a lane opponent
+func (accumulator *matchupAccumulator) add(player *models.Participant, opponent *models.Participant) {
+	accumulator.games++
+	accumulator.cs += player.TotalMinionsKilled - opponent.TotalMinionsKilled
+	accumulator.gold += player.GoldEarned - opponent.GoldEarned
+	accumulator.damage += player.TotalDamageDealtToChampions - opponent.TotalDamageDealtToChampions
+	accumulator.vision += player.VisionScore - opponent.VisionScore
+
+	if player.Win {
+		accumulator.wins++
+	}
+}
+
+// average divides a total by the number of games recorded
+func (accumulator *matchupAccumulator) average(total int) float64 {
+	if accumulator.games == 0 {
+		return 0
+	}
+	return float64(total) / float64(accumulator.games)
+}
+
+// differential returns the average differential for a differential identifier
+func (accumulator *matchupAccumulator) differential(stat string) float64 {
+	switch stat {
+	case differentialCS:
+		return accumulator.average(accumulator.cs)
+	case differentialGold:
+		return accumulator.average(accumulator.gold)
+	case differentialDamage:
+		return accumulator.average(accumulator.damage)
+	case differentialVision:
+		return accumulator.average(accumulator.vision)
+	default:
+		return 0
+	}
+}
+
+// laneOpponent returns the enemy participant playing the player's position, or nil when there is none
+func laneOpponent(match *models.Match, player *models.Participant) *models.Participant {
+	role := normalizeRole(player.TeamPosition)
+	if role == "" {
+		return nil
+	}
+
+	for index := range match.Participants {
+		participant := &match.Participants[index]
+		if !isTeammate(player, participant) && normalizeRole(participant.TeamPosition) == role {
+			return participant
+		}
+	}
+
+	return nil
+}
+
+// calculateLaneMatchup compares the player with their direct lane opponent in every game
+// Returns nil when no game lists an opponent in the player's position
+func (analysisService *AnalysisService) calculateLaneMatchup(summoner *models.Summoner, matches []models.Match) *models.LaneMatchup {
+	overall := &matchupAccumulator{}
+	opponentAccumulators := make(map[string]*matchupAccumulator)
+
+	for matchIndex := range matches {
+		match := &matches[matchIndex]
+		for participantIndex := range match.Participants {
+			player := &match.Participants[participantIndex]
+			if player.PUUID != summoner.PUUID {
+				continue
+			}
+
+			if opponent := laneOpponent(match, player); opponent != nil {
+				overall.add(player, opponent)
+
+				opponentAccumulator, exists := opponentAccumulators[opponent.ChampionName]
+				if !exists {
+					opponentAccumulator = &matchupAccumulator{}
+					opponentAccumulators[opponent.ChampionName] = opponentAccumulator
+				}
+				opponentAccumulator.add(player, opponent)
+			}
+			break
+		}
+	}
+
+	if overall.games == 0 {
+		return nil
+	}
+
+	opponents := make([]models.OpponentStats, 0, len(opponentAccumulators))
+	for championName, accumulator := range opponentAccumulators {
+		opponents = append(opponents, models.OpponentStats{
+			ChampionName:              championName,
+			GamesPlayed:               accumulator.games,
+			Wins:                      accumulator.wins,
+			WinRate:                   accumulator.average(accumulator.wins) * 100.0,
+			AverageCSDifferential:     accumulator.differential(differentialCS),
+			AverageGoldDifferential:   accumulator.differential(differentialGold),
+			AverageDamageDifferential: accumulator.differential(differentialDamage),
+			AverageVisionDifferential: accumulator.differential(differentialVision),
+		})
+	}
+
+	sort.Slice(opponents, func(left int, right int) bool {
+		if opponents[left].GamesPlayed != opponents[right].GamesPlayed {
+			return opponents[left].GamesPlayed > opponents[right].GamesPlayed
+		}
+		return opponents[left].ChampionName < opponents[right].ChampionName
+	})
+
+	return &models.LaneMatchup{
+		GamesCompared:             overall.games,
+		AverageCSDifferential:     overall.differential(differentialCS),
+		AverageGoldDifferential:   overall.differential(differentialGold),
+		AverageDamageDifferential: overall.differential(differentialDamage),
+		AverageVisionDifferential: overall.differential(differentialVision),
+		Opponents:                 opponents,
+	}
+}
+
+// opponentDifferential returns an opponent's average differential for a differential identifier
+func opponentDifferential(opponentStats *models.OpponentStats, stat string) float64 {
+	switch stat {
+	case differentialCS:
+		return opponentStats.AverageCSDifferential
+	case differentialGold:
+		return opponentStats.AverageGoldDifferential
+	case differentialDamage:
+		return opponentStats.AverageDamageDifferential
+	case differentialVision:
+		return opponentStats.AverageVisionDifferential
+	default:
+		return 0
+	}
+}
+
+// laneMatchupRule flags opponent champions the player consistently falls behind in lane
+type laneMatchupRule struct{}
+
+// Name identifies the rule
+func (rule *laneMatchupRule) Name() string {
+	return "matchup:lane"
+}
+
+// Evaluate applies the configured differential rules to each opponent champion with enough games
+func (rule *laneMatchupRule) Evaluate(ruleContext *RuleContext) []models.ImprovementArea {
+	matchupRule := ruleContext.RuleConfig.Matchup
+	if matchupRule == nil || ruleContext.LaneMatchup == nil {
+		return nil
+	}
+
+	var improvementAreas []models.ImprovementArea
+
+	for index := range ruleContext.LaneMatchup.Opponents {
+		opponentStats := &ruleContext.LaneMatchup.Opponents[index]
+		if opponentStats.GamesPlayed < matchupRule.MinimumGames {
+			continue
+		}
+
+		for _, differentialRule := range matchupRule.Differentials {
+			differential := opponentDifferential(opponentStats, differentialRule.Stat)
+			priority := differentialRule.Bands.priorityFor(-differential)
+			if priority == "" {
+				continue
+			}
+
+			recommendation := strings.NewReplacer(
+				"{opponent}", opponentStats.ChampionName,
+				"{value}", strconv.FormatFloat(-differential, 'f', differentialRule.Precision, 64),
+				"{games}", strconv.Itoa(opponentStats.GamesPlayed),
+			).Replace(differentialRule.Recommendation)
+
+			improvementAreas = append(improvementAreas, models.ImprovementArea{
+				Category:       differentialRule.Category,
+				Metric:         differentialRule.Stat,
+				CurrentValue:   roundTo(differential, differentialRule.Precision),
+				ExpectedValue:  0,
+				Gap:            roundTo(differential, differentialRule.Precision),
+				Priority:       priority,
+				Recommendation: recommendation,
+				Opponent:       opponentStats.ChampionName,
+			})
+		}
+	}
+
+	return improvementAreas
+}
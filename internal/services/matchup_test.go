@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// laneMatch builds a match where test-puuid plays MIDDLE against an opponent champion
+func laneMatch(opponentChampion string, win bool, playerCS int, opponentCS int) models.Match {
+	return models.Match{
+		MatchID:      "match",
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{PUUID: "test-puuid", ChampionName: "Ahri", TotalMinionsKilled: playerCS, GoldEarned: 10000, VisionScore: 20, Win: win, TeamPosition: "MIDDLE"},
+			{PUUID: "teammate", ChampionName: "Jinx", TotalMinionsKilled: 250, GoldEarned: 12000, Win: win, TeamPosition: "BOTTOM"},
+			{PUUID: "enemy-bottom", ChampionName: "Caitlyn", TotalMinionsKilled: 260, GoldEarned: 12500, Win: !win, TeamPosition: "BOTTOM"},
+			{PUUID: "enemy-middle", ChampionName: opponentChampion, TotalMinionsKilled: opponentCS, GoldEarned: 10500, VisionScore: 25, Win: !win, TeamPosition: "MID"},
+		},
+	}
+}
+
+// TestCalculateLaneMatchup tests differentials and per-opponent win rates
+func TestCalculateLaneMatchup(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches := []models.Match{
+		laneMatch("Yasuo", false, 180, 220),
+		laneMatch("Yasuo", false, 190, 230),
+		laneMatch("Yasuo", true, 200, 200),
+		laneMatch("Syndra", true, 230, 200),
+	}
+
+	laneMatchup := service.calculateLaneMatchup(summoner, matches)
+	if laneMatchup == nil {
+		t.Fatal("Expected lane matchup to be calculated")
+	}
+
+	if laneMatchup.GamesCompared != 4 {
+		t.Errorf("Expected 4 games compared, got %d", laneMatchup.GamesCompared)
+	}
+
+	// (-40 - 40 + 0 + 30) / 4
+	if laneMatchup.AverageCSDifferential != -12.5 {
+		t.Errorf("Expected average CS differential -12.5, got %.2f", laneMatchup.AverageCSDifferential)
+	}
+
+	if laneMatchup.AverageGoldDifferential != -500 {
+		t.Errorf("Expected average gold differential -500, got %.2f", laneMatchup.AverageGoldDifferential)
+	}
+
+	if len(laneMatchup.Opponents) != 2 || laneMatchup.Opponents[0].ChampionName != "Yasuo" {
+		t.Fatalf("Expected Yasuo first of 2 opponents, got %+v", laneMatchup.Opponents)
+	}
+
+	yasuo := laneMatchup.Opponents[0]
+	if yasuo.GamesPlayed != 3 || yasuo.Wins != 1 {
+		t.Errorf("Expected 1 win in 3 Yasuo games, got %d in %d", yasuo.Wins, yasuo.GamesPlayed)
+	}
+
+	if yasuo.AverageVisionDifferential != -5 {
+		t.Errorf("Expected Yasuo vision differential -5, got %.2f", yasuo.AverageVisionDifferential)
+	}
+}
+
+// TestCalculateLaneMatchup_NoOpponent tests that the matchup is omitted without an opponent in the same position
+func TestCalculateLaneMatchup_NoOpponent(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	match := laneMatch("Yasuo", true, 200, 200)
+	match.Participants = match.Participants[:3]
+
+	if laneMatchup := service.calculateLaneMatchup(summoner, []models.Match{match}); laneMatchup != nil {
+		t.Errorf("Expected no lane matchup, got %+v", laneMatchup)
+	}
+}
+
+// TestLaneMatchupRule tests matchup-specific recommendations
+func TestLaneMatchupRule(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches := []models.Match{
+		laneMatch("Yasuo", false, 180, 220),
+		laneMatch("Yasuo", false, 190, 230),
+		// A single bad game against Zed is below the minimum sample
+		laneMatch("Zed", false, 100, 250),
+	}
+	for index := range matches {
+		matches[index].MatchID = fmt.Sprintf("match-%d", index)
+	}
+
+	result := service.AnalyzePlayer(summoner, matches)
+
+	var matchupAreas []models.ImprovementArea
+	for _, area := range result.ImprovementAreas {
+		if area.Opponent != "" {
+			matchupAreas = append(matchupAreas, area)
+		}
+	}
+
+	if len(matchupAreas) != 1 {
+		t.Fatalf("Expected 1 matchup improvement area, got %+v", matchupAreas)
+	}
+
+	if result.LaneMatchup == nil || result.LaneMatchup.Mode != modeUnknown {
+		t.Errorf("Expected the lane matchup to report the analyzed mode, got %+v", result.LaneMatchup)
+	}
+
+	area := matchupAreas[0]
+	if area.Opponent != "Yasuo" || area.Priority != priorityHigh || area.Gap != -40 {
+		t.Errorf("Expected HIGH Yasuo CS area with gap -40, got %+v", area)
+	}
+
+	if !strings.Contains(area.Recommendation, "You lose CS lead vs Yasuo: 40 CS behind") {
+		t.Errorf("Expected matchup recommendation, got '%s'", area.Recommendation)
+	}
+
+	if result.LaneMatchup == nil || result.LaneMatchup.GamesCompared != 3 {
+		t.Error("Expected analysis result to include the lane matchup")
+	}
+}
+
+// TestValidate_MatchupRule tests matchup rule validation
+func TestValidate_MatchupRule(t *testing.T) {
+	ruleConfig := DefaultRuleConfig()
+	ruleConfig.Matchup.MinimumGames = 0
+	ruleConfig.Matchup.Differentials[0].Stat = "wards"
+
+	err := ruleConfig.Validate()
+	if err == nil {
+		t.Fatal("Expected invalid matchup rule to be rejected")
+	}
+
+	for _, expectedProblem := range []string{"matchup.minimumGames", "unsupported stat \"wards\""} {
+		if !strings.Contains(err.Error(), expectedProblem) {
+			t.Errorf("Expected error to mention '%s', got '%s'", expectedProblem, err.Error())
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// Reasons a match is excluded before analysis
+const (
+	exclusionInvalidDuration = "invalid_duration"
+	exclusionRemake          = "remake"
+	exclusionPlayerMissing   = "player_missing"
+	exclusionPlayerAFK       = "player_afk"
+	exclusionTeammateAFK     = "teammate_afk"
+	exclusionOpponentAFK     = "opponent_afk"
+)
+
+// Outlier screening thresholds
+const (
+	// remakeDurationSeconds is the game length below which a match is treated as a remake
+	remakeDurationSeconds = 300
+	// afkGoldPerMinute is the gold/min below which a participant may be AFK
+	// Passive income alone is about 120 gold/min on Summoner's Rift
+	afkGoldPerMinute = 150.0
+	// afkCSPerMinute is the CS/min below which a participant may be AFK
+	afkCSPerMinute = 1.0
+)
+
+// screenMatches separates normal matches from remakes and abnormal games
+// Returns the matches to analyze and the excluded matches with the reason for each
+func screenMatches(summoner *models.Summoner, matches []models.Match) ([]models.Match, []models.ExcludedMatch) {
+	screened := make([]models.Match, 0, len(matches))
+	var excluded []models.ExcludedMatch
+
+	for matchIndex := range matches {
+		match := &matches[matchIndex]
+		if reason := exclusionReason(summoner, match); reason != "" {
+			excluded = append(excluded, models.ExcludedMatch{MatchID: match.MatchID, Reason: reason})
+			continue
+		}
+		screened = append(screened, *match)
+	}
+
+	return screened, excluded
+}
+
+// exclusionReason returns why a match should be excluded, or an empty string for a normal match
+func exclusionReason(summoner *models.Summoner, match *models.Match) string {
+	if match.GameDuration <= 0 {
+		return exclusionInvalidDuration
+	}
+
+	player := findParticipant(match, summoner.PUUID)
+	if player == nil {
+		return exclusionPlayerMissing
+	}
+
+	if match.GameDuration < remakeDurationSeconds || player.GameEndedInEarlySurrender {
+		return exclusionRemake
+	}
+
+	if isAFK(player, match.GameDuration) {
+		return exclusionPlayerAFK
+	}
+
+	for index := range match.Participants {
+		other := &match.Participants[index]
+		if other.PUUID == summoner.PUUID || !isAFK(other, match.GameDuration) {
+			continue
+		}
+		if isTeammate(player, other) {
+			return exclusionTeammateAFK
+		}
+		return exclusionOpponentAFK
+	}
+
+	return ""
+}
+
+// isAFK reports whether a participant barely played: near-zero gold and CS with no takedowns
+// Every player starts with gold, so zero gold means the payload has no economy data rather than an AFK
+func isAFK(participant *models.Participant, gameDuration int) bool {
+	if participant.GoldEarned == 0 {
+		return false
+	}
+
+	minutes := float64(gameDuration) / 60.0
+	goldPerMinute := float64(participant.GoldEarned) / minutes
+	csPerMinute := float64(participant.TotalMinionsKilled) / minutes
+
+	return goldPerMinute < afkGoldPerMinute &&
+		csPerMinute < afkCSPerMinute &&
+		participant.Kills+participant.Assists == 0
+}
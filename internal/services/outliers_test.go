@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// outlierMatch builds a 30 minute match between test-puuid, a teammate and an opponent
+func outlierMatch(matchID string) models.Match {
+	return models.Match{
+		MatchID:      matchID,
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{PUUID: "test-puuid", Kills: 5, Deaths: 3, Assists: 7, TotalMinionsKilled: 200, GoldEarned: 11000, Win: true, TeamID: 100},
+			{PUUID: "teammate", Kills: 2, Deaths: 4, Assists: 9, TotalMinionsKilled: 40, GoldEarned: 8000, Win: true, TeamID: 100},
+			{PUUID: "opponent", Kills: 4, Deaths: 5, Assists: 3, TotalMinionsKilled: 190, GoldEarned: 10000, Win: false, TeamID: 200},
+		},
+	}
+}
+
+// TestScreenMatches tests that remakes and abnormal games are excluded with a reason
+func TestScreenMatches(t *testing.T) {
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	remake := outlierMatch("remake")
+	remake.GameDuration = 200
+
+	earlySurrender := outlierMatch("early-surrender")
+	earlySurrender.Participants[0].GameEndedInEarlySurrender = true
+
+	playerAFK := outlierMatch("player-afk")
+	playerAFK.Participants[0] = models.Participant{PUUID: "test-puuid", Deaths: 2, TotalMinionsKilled: 3, GoldEarned: 3900, TeamID: 100}
+
+	teammateAFK := outlierMatch("teammate-afk")
+	teammateAFK.Participants[1] = models.Participant{PUUID: "teammate", GoldEarned: 3800, TeamID: 100}
+
+	opponentAFK := outlierMatch("opponent-afk")
+	opponentAFK.Participants[2] = models.Participant{PUUID: "opponent", GoldEarned: 3800, TeamID: 200}
+
+	missingPlayer := outlierMatch("missing-player")
+	missingPlayer.Participants = missingPlayer.Participants[1:]
+
+	noDuration := outlierMatch("no-duration")
+	noDuration.GameDuration = 0
+
+	// Zero gold means the payload carries no economy data, not an AFK player
+	noEconomy := outlierMatch("no-economy")
+	noEconomy.Participants[1] = models.Participant{PUUID: "teammate", TeamID: 100}
+
+	matches := []models.Match{
+		outlierMatch("normal"), remake, earlySurrender, playerAFK, teammateAFK, opponentAFK, missingPlayer, noDuration, noEconomy,
+	}
+
+	screened, excluded := screenMatches(summoner, matches)
+
+	if len(screened) != 2 || screened[0].MatchID != "normal" || screened[1].MatchID != "no-economy" {
+		t.Errorf("Expected only the normal and no-economy matches to remain, got %+v", screened)
+	}
+
+	expectedReasons := map[string]string{
+		"remake":          exclusionRemake,
+		"early-surrender": exclusionRemake,
+		"player-afk":      exclusionPlayerAFK,
+		"teammate-afk":    exclusionTeammateAFK,
+		"opponent-afk":    exclusionOpponentAFK,
+		"missing-player":  exclusionPlayerMissing,
+		"no-duration":     exclusionInvalidDuration,
+	}
+
+	if len(excluded) != len(expectedReasons) {
+		t.Fatalf("Expected %d excluded matches, got %+v", len(expectedReasons), excluded)
+	}
+
+	for _, excludedMatch := range excluded {
+		if expectedReasons[excludedMatch.MatchID] != excludedMatch.Reason {
+			t.Errorf("Expected %s to be excluded as '%s', got '%s'", excludedMatch.MatchID, expectedReasons[excludedMatch.MatchID], excludedMatch.Reason)
+		}
+	}
+}
+
+// TestAnalyzePlayer_ExcludesRemakes tests that remakes do not count toward stats and are listed in the result
+func TestAnalyzePlayer_ExcludesRemakes(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	remake := outlierMatch("remake")
+	remake.GameDuration = 210
+	remake.Participants[0].Win = false
+
+	result := service.AnalyzePlayer(summoner, []models.Match{outlierMatch("normal"), remake})
+
+	if result.PlayerStats.TotalMatches != 1 || result.PlayerStats.WinRate != 100.0 {
+		t.Errorf("Expected 1 counted match at 100%% win rate, got %d at %.1f%%", result.PlayerStats.TotalMatches, result.PlayerStats.WinRate)
+	}
+
+	if len(result.ExcludedMatches) != 1 || result.ExcludedMatches[0].MatchID != "remake" || result.ExcludedMatches[0].Reason != exclusionRemake {
+		t.Errorf("Expected the remake to be listed as excluded, got %+v", result.ExcludedMatches)
+	}
+}
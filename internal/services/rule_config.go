@@ -0,0 +1,489 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRulesYAML is the rule configuration compiled into the binary
+//
+//go:embed default_rules.yaml
+var defaultRulesYAML []byte
+
+// Supported metric identifiers for rule configuration
+const (
+	metricCSPerMinute = "csPerMinute"
+	metricVisionScore = "visionScore"
+	metricKDA         = "kda"
+	metricDeaths      = "deaths"
+	metricDamage      = "damage"
+	metricWinRate     = "winRate"
+
+	metricKillParticipation = "killParticipation"
+	metricDamageShare       = "damageShare"
+	metricGoldShare         = "goldShare"
+	metricDamagePerGold     = "damagePerGold"
+)
+
+// Metric directions
+const (
+	directionHigher = "higher"
+	directionLower  = "lower"
+)
+
+// Metric comparison modes
+const (
+	comparisonAbsolute = "absolute"
+	comparisonRatio    = "ratio"
+)
+
+// Priority levels
+const (
+	priorityHigh   = "HIGH"
+	priorityMedium = "MEDIUM"
+	priorityLow    = "LOW"
+)
+
+// supportedMetrics lists the metric identifiers the analysis can evaluate
+var supportedMetrics = map[string]bool{
+	metricCSPerMinute: true,
+	metricVisionScore: true,
+	metricKDA:         true,
+	metricDeaths:      true,
+	metricDamage:      true,
+	metricWinRate:     true,
+
+	metricKillParticipation: true,
+	metricDamageShare:       true,
+	metricGoldShare:         true,
+	metricDamagePerGold:     true,
+}
+
+// RuleConfig defines the benchmarks, gap bands and recommendations used to identify improvement areas
+type RuleConfig struct {
+	// Version of the rule set, reported with every analysis
+	Version string `json:"version" yaml:"version"`
+	// Tier the benchmark and role benchmark values were calibrated for
+	ReferenceTier string `json:"referenceTier" yaml:"referenceTier"`
+	// Metric rules in evaluation order
+	Metrics []MetricRule `json:"metrics" yaml:"metrics"`
+	// Optional lane matchup rules comparing the player with their direct lane opponent
+	Matchup *MatchupRule `json:"matchup,omitempty" yaml:"matchup,omitempty"`
+	// Improvement area returned when no other area is identified
+	PositiveFeedback FeedbackRule `json:"positiveFeedback" yaml:"positiveFeedback"`
+}
+
+// MetricRule defines how a single metric is benchmarked
+type MetricRule struct {
+	// Metric identifier (csPerMinute, visionScore, kda, deaths, damage, winRate,
+	// killParticipation, damageShare, goldShare, damagePerGold)
+	Metric string `json:"metric" yaml:"metric"`
+	// Category reported on the improvement area
+	Category string `json:"category" yaml:"category"`
+	// Whether higher or lower values are better ("higher" or "lower")
+	Direction string `json:"direction" yaml:"direction"`
+	// How the gap is measured against the benchmark ("absolute" or "ratio")
+	Comparison string `json:"comparison" yaml:"comparison"`
+	// Number of decimals used when reporting values
+	Precision int `json:"precision" yaml:"precision"`
+	// Benchmark used when no role benchmark applies
+	Benchmark float64 `json:"benchmark" yaml:"benchmark"`
+	// Benchmarks per role; metrics with role benchmarks are judged per role
+	RoleBenchmarks map[string]float64 `json:"roleBenchmarks,omitempty" yaml:"roleBenchmarks,omitempty"`
+	// Benchmarks per ranked tier used to scale the benchmark to the player's tier
+	TierBenchmarks map[string]float64 `json:"tierBenchmarks,omitempty" yaml:"tierBenchmarks,omitempty"`
+	// Shortfall thresholds for each priority
+	Bands GapBands `json:"bands" yaml:"bands"`
+	// Recommendation text for the player
+	Recommendation string `json:"recommendation" yaml:"recommendation"`
+	// Optional champion-specific rule for the same metric
+	Champion *ChampionRule `json:"champion,omitempty" yaml:"champion,omitempty"`
+}
+
+// GapBands holds the shortfall each priority level must exceed (0 disables a band)
+type GapBands struct {
+	High   float64 `json:"high" yaml:"high"`
+	Medium float64 `json:"medium" yaml:"medium"`
+	Low    float64 `json:"low" yaml:"low"`
+	// Bands (high, medium, low) reached by a shortfall equal to their threshold
+	Inclusive []string `json:"inclusive,omitempty" yaml:"inclusive,omitempty"`
+}
+
+// Gap band names used by GapBands.Inclusive
+const (
+	bandHigh   = "high"
+	bandMedium = "medium"
+	bandLow    = "low"
+)
+
+// ChampionRule defines a champion-specific variant of a metric rule
+type ChampionRule struct {
+	// Minimum number of games on a champion before it is judged
+	MinimumGames int `json:"minimumGames" yaml:"minimumGames"`
+	// Shortfall thresholds for each priority
+	Bands GapBands `json:"bands" yaml:"bands"`
+	// Recommendation template; {champion}, {value} and {games} are substituted
+	Recommendation string `json:"recommendation" yaml:"recommendation"`
+}
+
+// MatchupRule defines which lane differentials against an opponent champion are flagged
+type MatchupRule struct {
+	// Minimum number of games against an opponent champion before it is judged
+	MinimumGames int `json:"minimumGames" yaml:"minimumGames"`
+	// Differential rules in evaluation order
+	Differentials []DifferentialRule `json:"differentials" yaml:"differentials"`
+}
+
+// DifferentialRule flags a lane differential (cs, gold, damage, vision) when the player falls behind
+type DifferentialRule struct {
+	// Differential identifier (cs, gold, damage, vision)
+	Stat string `json:"stat" yaml:"stat"`
+	// Category reported on the improvement area
+	Category string `json:"category" yaml:"category"`
+	// Number of decimals used when reporting values
+	Precision int `json:"precision" yaml:"precision"`
+	// Deficit thresholds for each priority
+	Bands GapBands `json:"bands" yaml:"bands"`
+	// Recommendation template; {opponent}, {value} and {games} are substituted
+	Recommendation string `json:"recommendation" yaml:"recommendation"`
+}
+
+// FeedbackRule defines a fixed improvement area
+type FeedbackRule struct {
+	Category       string `json:"category" yaml:"category"`
+	Recommendation string `json:"recommendation" yaml:"recommendation"`
+}
+
+// RuleConfigError lists every validation problem found in a rule configuration
+type RuleConfigError struct {
+	Problems []string
+}
+
+// Error joins the validation problems into a single message
+func (ruleConfigError *RuleConfigError) Error() string {
+	return "invalid rule configuration: " + strings.Join(ruleConfigError.Problems, "; ")
+}
+
+// DefaultRuleConfig returns the rule configuration compiled into the binary
+func DefaultRuleConfig() *RuleConfig {
+	ruleConfig, err := ParseRuleConfig(defaultRulesYAML, "yaml")
+	if err != nil {
+		panic(fmt.Sprintf("embedded default rules are invalid: %v", err))
+	}
+	return ruleConfig
+}
+
+// LoadRuleConfig reads and validates a rule configuration file
+// Files ending in .json are parsed as JSON, everything else as YAML
+func LoadRuleConfig(path string) (*RuleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rule file: %w", err)
+	}
+
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+
+	return ParseRuleConfig(data, format)
+}
+
+// ParseRuleConfig decodes and validates a rule configuration in the given format ("json" or "yaml")
+func ParseRuleConfig(data []byte, format string) (*RuleConfig, error) {
+	var ruleConfig RuleConfig
+
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&ruleConfig); err != nil {
+			return nil, fmt.Errorf("failed to parse rule file: %w", err)
+		}
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&ruleConfig); err != nil {
+			return nil, fmt.Errorf("failed to parse rule file: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported rule file format %q", format)
+	}
+
+	ruleConfig.ReferenceTier = strings.ToUpper(strings.TrimSpace(ruleConfig.ReferenceTier))
+
+	if err := ruleConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &ruleConfig, nil
+}
+
+// Validate checks the rule configuration and reports every problem found
+func (ruleConfig *RuleConfig) Validate() error {
+	var problems []string
+
+	if strings.TrimSpace(ruleConfig.Version) == "" {
+		problems = append(problems, "version is required")
+	}
+
+	if ruleConfig.ReferenceTier != "" && tierIndex(ruleConfig.ReferenceTier) < 0 {
+		problems = append(problems, fmt.Sprintf("referenceTier %q is not a ranked tier", ruleConfig.ReferenceTier))
+	}
+
+	if len(ruleConfig.Metrics) == 0 {
+		problems = append(problems, "at least one metric rule is required")
+	}
+
+	seenMetrics := make(map[string]bool)
+	for index, metricRule := range ruleConfig.Metrics {
+		prefix := fmt.Sprintf("metrics[%d]", index)
+		if metricRule.Metric != "" {
+			prefix = fmt.Sprintf("metrics[%d] (%s)", index, metricRule.Metric)
+		}
+
+		if !supportedMetrics[metricRule.Metric] {
+			problems = append(problems, fmt.Sprintf("%s: unsupported metric %q", prefix, metricRule.Metric))
+		}
+		if seenMetrics[metricRule.Metric] {
+			problems = append(problems, fmt.Sprintf("%s: duplicate metric", prefix))
+		}
+		seenMetrics[metricRule.Metric] = true
+
+		problems = append(problems, metricRule.validate(prefix, ruleConfig.ReferenceTier)...)
+	}
+
+	if ruleConfig.Matchup != nil {
+		problems = append(problems, ruleConfig.Matchup.validate()...)
+	}
+
+	if strings.TrimSpace(ruleConfig.PositiveFeedback.Category) == "" {
+		problems = append(problems, "positiveFeedback.category is required")
+	}
+	if strings.TrimSpace(ruleConfig.PositiveFeedback.Recommendation) == "" {
+		problems = append(problems, "positiveFeedback.recommendation is required")
+	}
+
+	if len(problems) > 0 {
+		return &RuleConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// validate checks a single metric rule
+func (metricRule *MetricRule) validate(prefix string, referenceTier string) []string {
+	var problems []string
+
+	if strings.TrimSpace(metricRule.Category) == "" {
+		problems = append(problems, prefix+": category is required")
+	}
+
+	if metricRule.Direction != directionHigher && metricRule.Direction != directionLower {
+		problems = append(problems, fmt.Sprintf("%s: direction must be %q or %q", prefix, directionHigher, directionLower))
+	}
+
+	if metricRule.Comparison != comparisonAbsolute && metricRule.Comparison != comparisonRatio {
+		problems = append(problems, fmt.Sprintf("%s: comparison must be %q or %q", prefix, comparisonAbsolute, comparisonRatio))
+	}
+
+	if metricRule.Precision < 0 || metricRule.Precision > 4 {
+		problems = append(problems, prefix+": precision must be between 0 and 4")
+	}
+
+	if metricRule.Benchmark <= 0 {
+		problems = append(problems, prefix+": benchmark must be positive")
+	}
+
+	for role, benchmark := range metricRule.RoleBenchmarks {
+		if normalizeRole(role) != role {
+			problems = append(problems, fmt.Sprintf("%s: roleBenchmarks has unknown role %q", prefix, role))
+		}
+		if benchmark <= 0 {
+			problems = append(problems, fmt.Sprintf("%s: roleBenchmarks.%s must be positive", prefix, role))
+		}
+	}
+
+	if len(metricRule.TierBenchmarks) > 0 {
+		if referenceTier == "" {
+			problems = append(problems, prefix+": tierBenchmarks require a referenceTier")
+		}
+		for _, tier := range rankedTiers {
+			benchmark, exists := metricRule.TierBenchmarks[tier]
+			if !exists {
+				problems = append(problems, fmt.Sprintf("%s: tierBenchmarks is missing tier %s", prefix, tier))
+			} else if benchmark <= 0 {
+				problems = append(problems, fmt.Sprintf("%s: tierBenchmarks.%s must be positive", prefix, tier))
+			}
+		}
+		for tier := range metricRule.TierBenchmarks {
+			if tierIndex(tier) < 0 {
+				problems = append(problems, fmt.Sprintf("%s: tierBenchmarks has unknown tier %q", prefix, tier))
+			}
+		}
+	}
+
+	problems = append(problems, metricRule.Bands.validate(prefix+": bands")...)
+
+	if strings.TrimSpace(metricRule.Recommendation) == "" {
+		problems = append(problems, prefix+": recommendation is required")
+	}
+
+	if metricRule.Champion != nil {
+		if metricRule.Champion.MinimumGames < 1 {
+			problems = append(problems, prefix+": champion.minimumGames must be at least 1")
+		}
+		problems = append(problems, metricRule.Champion.Bands.validate(prefix+": champion.bands")...)
+		if strings.TrimSpace(metricRule.Champion.Recommendation) == "" {
+			problems = append(problems, prefix+": champion.recommendation is required")
+		}
+	}
+
+	return problems
+}
+
+// validate checks the lane matchup rules
+func (matchupRule *MatchupRule) validate() []string {
+	var problems []string
+
+	if matchupRule.MinimumGames < 1 {
+		problems = append(problems, "matchup.minimumGames must be at least 1")
+	}
+
+	seenStats := make(map[string]bool)
+	for index, differentialRule := range matchupRule.Differentials {
+		prefix := fmt.Sprintf("matchup.differentials[%d]", index)
+
+		if !supportedDifferentials[differentialRule.Stat] {
+			problems = append(problems, fmt.Sprintf("%s: unsupported stat %q", prefix, differentialRule.Stat))
+		}
+		if seenStats[differentialRule.Stat] {
+			problems = append(problems, fmt.Sprintf("%s: duplicate stat", prefix))
+		}
+		seenStats[differentialRule.Stat] = true
+
+		if strings.TrimSpace(differentialRule.Category) == "" {
+			problems = append(problems, prefix+": category is required")
+		}
+		if differentialRule.Precision < 0 || differentialRule.Precision > 4 {
+			problems = append(problems, prefix+": precision must be between 0 and 4")
+		}
+		problems = append(problems, differentialRule.Bands.validate(prefix+": bands")...)
+		if strings.TrimSpace(differentialRule.Recommendation) == "" {
+			problems = append(problems, prefix+": recommendation is required")
+		}
+	}
+
+	return problems
+}
+
+// validate checks that the bands are non-negative, ordered and not all disabled
+func (gapBands GapBands) validate(prefix string) []string {
+	var problems []string
+
+	if gapBands.High < 0 || gapBands.Medium < 0 || gapBands.Low < 0 {
+		problems = append(problems, prefix+" must not be negative")
+	}
+
+	if gapBands.High == 0 && gapBands.Medium == 0 && gapBands.Low == 0 {
+		problems = append(problems, prefix+" must enable at least one of high, medium or low")
+	}
+
+	if gapBands.High > 0 && gapBands.Medium > 0 && gapBands.High < gapBands.Medium {
+		problems = append(problems, prefix+": high must be greater than or equal to medium")
+	}
+	if gapBands.Medium > 0 && gapBands.Low > 0 && gapBands.Medium < gapBands.Low {
+		problems = append(problems, prefix+": medium must be greater than or equal to low")
+	}
+	if gapBands.High > 0 && gapBands.Low > 0 && gapBands.High < gapBands.Low {
+		problems = append(problems, prefix+": high must be greater than or equal to low")
+	}
+
+	for index, band := range gapBands.Inclusive {
+		if band != bandHigh && band != bandMedium && band != bandLow {
+			problems = append(problems, fmt.Sprintf("%s.inclusive[%d]: unknown band %q (expected high, medium or low)", prefix, index, band))
+		}
+	}
+
+	return problems
+}
+
+// priorityFor returns the priority for a shortfall, or an empty string when no band is reached
+func (gapBands GapBands) priorityFor(shortfall float64) string {
+	switch {
+	case gapBands.reaches(bandHigh, gapBands.High, shortfall):
+		return priorityHigh
+	case gapBands.reaches(bandMedium, gapBands.Medium, shortfall):
+		return priorityMedium
+	case gapBands.reaches(bandLow, gapBands.Low, shortfall):
+		return priorityLow
+	default:
+		return ""
+	}
+}
+
+// reaches reports whether a shortfall reaches an enabled band: it must exceed the threshold,
+// or equal it when the band is inclusive
+func (gapBands GapBands) reaches(band string, threshold float64, shortfall float64) bool {
+	if threshold <= 0 {
+		return false
+	}
+	if shortfall == threshold {
+		for _, inclusiveBand := range gapBands.Inclusive {
+			if inclusiveBand == band {
+				return true
+			}
+		}
+	}
+	return shortfall > threshold
+}
+
+// isRoleAware reports whether the metric is judged per role
+func (metricRule *MetricRule) isRoleAware() bool {
+	return len(metricRule.RoleBenchmarks) > 0
+}
+
+// expectedValue returns the benchmark for a role, scaled to the selected tier
+// The returned profile name is the role whose benchmark was used, or DEFAULT
+func (metricRule *MetricRule) expectedValue(role string, selection benchmarkSelection, referenceTier string) (float64, string) {
+	benchmark := metricRule.Benchmark
+	profileName := defaultProfileName
+
+	if roleBenchmark, exists := metricRule.RoleBenchmarks[role]; exists {
+		benchmark = roleBenchmark
+		profileName = role
+	}
+
+	if len(metricRule.TierBenchmarks) > 0 {
+		benchmark = selection.scaleBenchmark(benchmark, metricRule.TierBenchmarks, referenceTier)
+	}
+
+	return roundTo(benchmark, metricRule.Precision), profileName
+}
+
+// shortfall measures how far the current value falls short of the expected value
+// Positive values mean underperformance; ratio comparisons return a fraction of the benchmark
+func (metricRule *MetricRule) shortfall(currentValue float64, expectedValue float64) float64 {
+	difference := expectedValue - currentValue
+	if metricRule.Direction == directionLower {
+		difference = currentValue - expectedValue
+	}
+
+	if metricRule.Comparison == comparisonRatio {
+		return difference / expectedValue
+	}
+	return difference
+}
+
+// roundTo rounds a value to the given number of decimals
+func roundTo(value float64, precision int) float64 {
+	scale := math.Pow10(precision)
+	return math.Round(value*scale) / scale
+}
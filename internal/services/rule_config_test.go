@@ -0,0 +1,288 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// findMetricRule returns the rule for a metric or fails the test
+func findMetricRule(t *testing.T, ruleConfig *RuleConfig, metric string) *MetricRule {
+	t.Helper()
+
+	for index := range ruleConfig.Metrics {
+		if ruleConfig.Metrics[index].Metric == metric {
+			return &ruleConfig.Metrics[index]
+		}
+	}
+
+	t.Fatalf("Expected metric rule '%s' to exist", metric)
+	return nil
+}
+
+// writeRuleFile writes rule file content into a temporary directory and returns its path
+func writeRuleFile(t *testing.T, fileName string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), fileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write rule file: %v", err)
+	}
+	return path
+}
+
+// minimalRulesJSON is a small valid rule file with a single CS rule
+const minimalRulesJSON = `{
+  "version": "test-1",
+  "metrics": [
+    {
+      "metric": "csPerMinute",
+      "category": "Farming",
+      "direction": "higher",
+      "comparison": "absolute",
+      "precision": 1,
+      "benchmark": 9.0,
+      "bands": {"high": 3.0, "medium": 1.0},
+      "recommendation": "Farm more."
+    }
+  ],
+  "positiveFeedback": {"category": "All Good", "recommendation": "Keep it up."}
+}`
+
+// TestDefaultRuleConfig tests that the embedded default rules are valid
+func TestDefaultRuleConfig(t *testing.T) {
+	ruleConfig := DefaultRuleConfig()
+
+	if ruleConfig.Version == "" {
+		t.Error("Expected default rules to have a version")
+	}
+
+	if ruleConfig.ReferenceTier != "GOLD" {
+		t.Errorf("Expected reference tier 'GOLD', got '%s'", ruleConfig.ReferenceTier)
+	}
+
+	for metric := range supportedMetrics {
+		findMetricRule(t, ruleConfig, metric)
+	}
+}
+
+// TestGapBandsPriorityFor tests priority selection from strict and inclusive gap bands
+func TestGapBandsPriorityFor(t *testing.T) {
+	strictBands := GapBands{High: 2.0, Medium: 1.0, Low: 0.5}
+	inclusiveHigh := GapBands{High: 2.0, Medium: 1.0, Low: 0.5, Inclusive: []string{bandHigh}}
+
+	testCases := []struct {
+		gapBands  GapBands
+		shortfall float64
+		expected  string
+	}{
+		{strictBands, 3.0, priorityHigh},
+		{strictBands, 2.0, priorityMedium},
+		{strictBands, 1.5, priorityMedium},
+		{strictBands, 1.0, priorityLow},
+		{strictBands, 0.6, priorityLow},
+		{strictBands, 0.5, ""},
+		{strictBands, 0.1, ""},
+		{strictBands, -1.0, ""},
+		{inclusiveHigh, 2.0, priorityHigh},
+		{inclusiveHigh, 1.0, priorityLow},
+	}
+
+	for _, testCase := range testCases {
+		result := testCase.gapBands.priorityFor(testCase.shortfall)
+		if result != testCase.expected {
+			t.Errorf("Expected priority '%s' for shortfall %.1f with inclusive bands %v, got '%s'", testCase.expected, testCase.shortfall, testCase.gapBands.Inclusive, result)
+		}
+	}
+}
+
+// TestDefaultRules_BandBoundaries tests shortfalls exactly on a band of the default rules:
+// CS and vision reach HIGH at their high band, while KDA must exceed it
+func TestDefaultRules_BandBoundaries(t *testing.T) {
+	service := NewAnalysisService()
+
+	testCases := []struct {
+		name             string
+		playerStats      models.PlayerStats
+		category         string
+		expectedPriority string
+	}{
+		{"CS 2.0 below benchmark", models.PlayerStats{CSPerMinute: 4.0, AverageVisionScore: 45.0, KDA: 3.5, AverageDeaths: 4.0, WinRate: 55.0}, "CS (Creep Score)", priorityHigh},
+		{"vision 20 below benchmark", models.PlayerStats{CSPerMinute: 7.0, AverageVisionScore: 20.0, KDA: 3.5, AverageDeaths: 4.0, WinRate: 55.0}, "Vision Control", priorityHigh},
+		{"KDA 1.0 below benchmark", models.PlayerStats{CSPerMinute: 7.0, AverageVisionScore: 45.0, KDA: 2.0, AverageDeaths: 4.0, WinRate: 55.0}, "KDA Ratio", priorityMedium},
+		{"KDA 0.5 below benchmark", models.PlayerStats{CSPerMinute: 7.0, AverageVisionScore: 45.0, KDA: 2.5, AverageDeaths: 4.0, WinRate: 55.0}, "KDA Ratio", ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			priority := ""
+			for _, area := range service.identifyImprovementAreas(&RuleContext{PlayerStats: &testCase.playerStats}) {
+				if area.Category == testCase.category {
+					priority = area.Priority
+				}
+			}
+
+			if priority != testCase.expectedPriority {
+				t.Errorf("Expected %s priority '%s', got '%s'", testCase.category, testCase.expectedPriority, priority)
+			}
+		})
+	}
+}
+
+// TestNewAnalysisServiceWithRules_JSON tests loading a JSON rule file
+func TestNewAnalysisServiceWithRules_JSON(t *testing.T) {
+	path := writeRuleFile(t, "rules.json", minimalRulesJSON)
+
+	service, err := NewAnalysisServiceWithRules(path)
+	if err != nil {
+		t.Fatalf("Expected rule file to load, got %v", err)
+	}
+
+	playerStats := &models.PlayerStats{CSPerMinute: 7.0}
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
+
+	if len(areas) != 1 {
+		t.Fatalf("Expected 1 improvement area, got %d", len(areas))
+	}
+
+	if areas[0].Category != "Farming" || areas[0].Priority != priorityMedium || areas[0].ExpectedValue != 9.0 {
+		t.Errorf("Expected MEDIUM Farming area against 9.0, got %+v", areas[0])
+	}
+}
+
+// TestNewAnalysisServiceWithRules_YAML tests loading a YAML rule file
+func TestNewAnalysisServiceWithRules_YAML(t *testing.T) {
+	path := writeRuleFile(t, "rules.yaml", `
+version: yaml-1
+metrics:
+  - metric: deaths
+    category: Deaths
+    direction: lower
+    comparison: absolute
+    precision: 1
+    benchmark: 3.0
+    bands:
+      medium: 0.5
+    recommendation: Die less.
+positiveFeedback:
+  category: Overall Performance
+  recommendation: Nice.
+`)
+
+	service, err := NewAnalysisServiceWithRules(path)
+	if err != nil {
+		t.Fatalf("Expected rule file to load, got %v", err)
+	}
+
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: &models.PlayerStats{AverageDeaths: 4.0}})
+
+	if len(areas) != 1 || areas[0].Recommendation != "Die less." {
+		t.Errorf("Expected the YAML deaths rule to fire, got %+v", areas)
+	}
+
+	if service.AnalyzePlayer(&models.Summoner{}, nil).Benchmark.Version != "yaml-1" {
+		t.Error("Expected analysis to report the rule file version")
+	}
+}
+
+// TestNewAnalysisServiceWithRules_Invalid tests that invalid files report every problem
+func TestNewAnalysisServiceWithRules_Invalid(t *testing.T) {
+	path := writeRuleFile(t, "rules.json", `{
+  "metrics": [
+    {"metric": "jungleProximity", "category": "", "direction": "up", "comparison": "absolute", "benchmark": -1, "bands": {"inclusive": ["extreme"]}, "recommendation": ""}
+  ],
+  "positiveFeedback": {"category": "x", "recommendation": "y"}
+}`)
+
+	_, err := NewAnalysisServiceWithRules(path)
+	if err == nil {
+		t.Fatal("Expected invalid rule file to be rejected")
+	}
+
+	var ruleConfigError *RuleConfigError
+	if !errors.As(err, &ruleConfigError) {
+		t.Fatalf("Expected RuleConfigError, got %T", err)
+	}
+
+	expectedProblems := []string{"version is required", "unsupported metric", "category is required", "direction must be", "benchmark must be positive", "bands must enable", "unknown band \"extreme\"", "recommendation is required"}
+	for _, expectedProblem := range expectedProblems {
+		if !strings.Contains(err.Error(), expectedProblem) {
+			t.Errorf("Expected error to mention '%s', got '%s'", expectedProblem, err.Error())
+		}
+	}
+}
+
+// TestNewAnalysisServiceWithRules_UnknownField tests that typos in rule files are rejected
+func TestNewAnalysisServiceWithRules_UnknownField(t *testing.T) {
+	path := writeRuleFile(t, "rules.json", strings.Replace(minimalRulesJSON, `"benchmark"`, `"benchmrk"`, 1))
+
+	if _, err := NewAnalysisServiceWithRules(path); err == nil {
+		t.Error("Expected unknown field to be rejected")
+	}
+}
+
+// TestValidate_IncompleteTierBenchmarks tests that tier tables must cover every tier
+func TestValidate_IncompleteTierBenchmarks(t *testing.T) {
+	ruleConfig := DefaultRuleConfig()
+	delete(findMetricRule(t, ruleConfig, metricKDA).TierBenchmarks, "EMERALD")
+
+	err := ruleConfig.Validate()
+	if err == nil || !strings.Contains(err.Error(), "missing tier EMERALD") {
+		t.Errorf("Expected missing tier error, got %v", err)
+	}
+}
+
+// TestReloadRules tests hot-reloading the rule file
+func TestReloadRules(t *testing.T) {
+	path := writeRuleFile(t, "rules.json", minimalRulesJSON)
+
+	service, err := NewAnalysisServiceWithRules(path)
+	if err != nil {
+		t.Fatalf("Expected rule file to load, got %v", err)
+	}
+
+	updatedRules := strings.Replace(strings.Replace(minimalRulesJSON, `"test-1"`, `"test-2"`, 1), `9.0`, `5.0`, 1)
+	if err := os.WriteFile(path, []byte(updatedRules), 0o600); err != nil {
+		t.Fatalf("Failed to update rule file: %v", err)
+	}
+
+	version, err := service.ReloadRules()
+	if err != nil {
+		t.Fatalf("Expected reload to succeed, got %v", err)
+	}
+
+	if version != "test-2" {
+		t.Errorf("Expected version 'test-2', got '%s'", version)
+	}
+
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: &models.PlayerStats{CSPerMinute: 7.0}})
+	if len(areas) != 1 || areas[0].Category != "All Good" {
+		t.Errorf("Expected reloaded benchmark to clear the CS area, got %+v", areas)
+	}
+
+	// An invalid edit must keep the previous rules active
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("Failed to update rule file: %v", err)
+	}
+
+	if _, err := service.ReloadRules(); err == nil {
+		t.Error("Expected reload of an invalid file to fail")
+	}
+
+	if service.currentRuleConfig().Version != "test-2" {
+		t.Errorf("Expected previous rules to stay active, got version '%s'", service.currentRuleConfig().Version)
+	}
+}
+
+// TestReloadRules_BuiltInRules tests that reloading without a rule file fails
+func TestReloadRules_BuiltInRules(t *testing.T) {
+	service := NewAnalysisService()
+
+	if _, err := service.ReloadRules(); err == nil {
+		t.Error("Expected reload without a rule file to fail")
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// Rule produces improvement areas from a player's statistics and raw matches
+// Register custom rules with AnalysisService.RegisterRule to extend the analysis
+type Rule interface {
+	// Name identifies the rule
+	Name() string
+	// Evaluate returns zero or more improvement areas for the player
+	Evaluate(ruleContext *RuleContext) []models.ImprovementArea
+}
+
+// RuleContext carries the inputs available to every rule
+type RuleContext struct {
+	// Summoner being analyzed (tier and division select the benchmark table)
+	Summoner *models.Summoner
+	// Aggregated statistics for the analyzed matches
+	PlayerStats *models.PlayerStats
+	// Raw matches the statistics were computed from
+	Matches []models.Match
+	// Head-to-head comparison against lane opponents (nil when no opponent was found)
+	LaneMatchup *models.LaneMatchup
+	// Request-level analysis options
+	Options models.AnalysisOptions
+	// Active benchmarks, gap bands and recommendations
+	RuleConfig *RuleConfig
+}
+
+// benchmarkSelection returns the tier benchmark selection for the analyzed summoner
+func (ruleContext *RuleContext) benchmarkSelection() benchmarkSelection {
+	if ruleContext.Summoner == nil {
+		return benchmarkSelection{Goal: strings.ToLower(strings.TrimSpace(ruleContext.Options.Goal))}
+	}
+	return newBenchmarkSelection(ruleContext.Summoner, ruleContext.Options)
+}
+
+// metricRule returns the configured rule for a metric, or nil when the metric is not configured
+func (ruleContext *RuleContext) metricRule(metric string) *MetricRule {
+	for index := range ruleContext.RuleConfig.Metrics {
+		if ruleContext.RuleConfig.Metrics[index].Metric == metric {
+			return &ruleContext.RuleConfig.Metrics[index]
+		}
+	}
+	return nil
+}
+
+// builtInRules returns the rules every AnalysisService starts with
+func builtInRules() []Rule {
+	return []Rule{
+		&benchmarkRule{metric: metricCSPerMinute},
+		&benchmarkRule{metric: metricVisionScore},
+		&benchmarkRule{metric: metricKDA},
+		&benchmarkRule{metric: metricDeaths},
+		&benchmarkRule{metric: metricDamage},
+		&benchmarkRule{metric: metricWinRate},
+		&benchmarkRule{metric: metricKillParticipation},
+		&benchmarkRule{metric: metricDamageShare},
+		&benchmarkRule{metric: metricGoldShare},
+		&benchmarkRule{metric: metricDamagePerGold},
+		&championBenchmarkRule{},
+		&laneMatchupRule{},
+	}
+}
+
+// benchmarkRule compares a single configured metric against its benchmark
+// Metrics with role benchmarks are judged per role so each game is compared to its own role's expectations
+type benchmarkRule struct {
+	metric string
+}
+
+// Name identifies the rule
+func (rule *benchmarkRule) Name() string {
+	return "benchmark:" + rule.metric
+}
+
+// Evaluate compares the metric against the configured benchmark
+func (rule *benchmarkRule) Evaluate(ruleContext *RuleContext) []models.ImprovementArea {
+	metricRule := ruleContext.metricRule(rule.metric)
+	if metricRule == nil {
+		return nil
+	}
+
+	selection := ruleContext.benchmarkSelection()
+	playerStats := ruleContext.PlayerStats
+
+	if !metricRule.isRoleAware() || len(playerStats.RoleStats) == 0 {
+		return evaluateMetricRule(ruleContext.RuleConfig, metricRule, playerMetricValues(playerStats), "", selection)
+	}
+
+	var improvementAreas []models.ImprovementArea
+	for _, roleStats := range judgedRoles(playerStats.RoleStats) {
+		improvementAreas = append(improvementAreas, evaluateMetricRule(ruleContext.RuleConfig, metricRule, roleMetricValues(&roleStats), roleStats.Role, selection)...)
+	}
+
+	// Games from thin or unknown roles are judged together against the default benchmark
+	if playerStats.PooledRoleStats != nil {
+		improvementAreas = append(improvementAreas, evaluateMetricRule(ruleContext.RuleConfig, metricRule, roleMetricValues(playerStats.PooledRoleStats), "", selection)...)
+	}
+	return improvementAreas
+}
+
+// evaluateMetricRule compares a metric value against a metric rule's benchmark for a role
+func evaluateMetricRule(ruleConfig *RuleConfig, metricRule *MetricRule, metricValues map[string]float64, role string, selection benchmarkSelection) []models.ImprovementArea {
+	currentValue, exists := metricValues[metricRule.Metric]
+	if !exists {
+		return nil
+	}
+
+	expectedValue, profileName := metricRule.expectedValue(role, selection, ruleConfig.ReferenceTier)
+	priority := metricRule.Bands.priorityFor(metricRule.shortfall(currentValue, expectedValue))
+	if priority == "" {
+		return nil
+	}
+
+	improvementArea := models.ImprovementArea{
+		Category:       metricRule.Category,
+		Metric:         metricRule.Metric,
+		CurrentValue:   roundTo(currentValue, metricRule.Precision),
+		ExpectedValue:  expectedValue,
+		Gap:            roundTo(currentValue-expectedValue, metricRule.Precision),
+		Priority:       priority,
+		Recommendation: metricRule.Recommendation,
+	}
+	if metricRule.isRoleAware() {
+		improvementArea.Role = profileName
+	}
+
+	return []models.ImprovementArea{improvementArea}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// firstBloodRule is a custom rule used to test rule registration
+type firstBloodRule struct {
+	receivedContext *RuleContext
+}
+
+// Name identifies the rule
+func (rule *firstBloodRule) Name() string {
+	return "first-blood"
+}
+
+// Evaluate records the context and reports an area when matches are present
+func (rule *firstBloodRule) Evaluate(ruleContext *RuleContext) []models.ImprovementArea {
+	rule.receivedContext = ruleContext
+
+	if len(ruleContext.Matches) == 0 {
+		return nil
+	}
+
+	return []models.ImprovementArea{{
+		Category:       "Early Game",
+		Priority:       priorityMedium,
+		Recommendation: "Look for early skirmishes.",
+	}}
+}
+
+// TestRegisterRule tests that custom rules run alongside the built-in rules
+func TestRegisterRule(t *testing.T) {
+	service := NewAnalysisService()
+	customRule := &firstBloodRule{}
+	service.RegisterRule(customRule)
+
+	summoner := &models.Summoner{PUUID: "test-puuid", Tier: "GOLD"}
+	matches := []models.Match{
+		{
+			MatchID:      "match-1",
+			GameDuration: 1800,
+			Participants: []models.Participant{
+				{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 5, Deaths: 2, Assists: 8, TotalMinionsKilled: 240, VisionScore: 30, Win: true},
+			},
+		},
+	}
+
+	result := service.AnalyzePlayerWithOptions(context.Background(), summoner, matches, models.AnalysisOptions{Goal: GoalClimb})
+
+	if customRule.receivedContext == nil {
+		t.Fatal("Expected custom rule to be evaluated")
+	}
+
+	if customRule.receivedContext.Summoner != summoner || len(customRule.receivedContext.Matches) != 1 {
+		t.Error("Expected custom rule to receive the summoner and raw matches")
+	}
+
+	if customRule.receivedContext.Options.Goal != GoalClimb {
+		t.Errorf("Expected custom rule to receive the options, got %+v", customRule.receivedContext.Options)
+	}
+
+	foundCustomArea := false
+	for _, area := range result.ImprovementAreas {
+		if area.Category == "Early Game" {
+			foundCustomArea = true
+		}
+		if area.Category == DefaultRuleConfig().PositiveFeedback.Category {
+			t.Error("Expected no positive feedback when a custom rule reports an area")
+		}
+	}
+
+	if !foundCustomArea {
+		t.Error("Expected custom rule improvement area in the result")
+	}
+}
+
+// TestBenchmarkRule_UnconfiguredMetric tests that a metric missing from the rule file reports nothing
+func TestBenchmarkRule_UnconfiguredMetric(t *testing.T) {
+	ruleConfig := DefaultRuleConfig()
+	ruleConfig.Metrics = nil
+
+	rule := &benchmarkRule{metric: metricCSPerMinute}
+	areas := rule.Evaluate(&RuleContext{PlayerStats: &models.PlayerStats{CSPerMinute: 1.0}, RuleConfig: ruleConfig})
+
+	if len(areas) != 0 {
+		t.Errorf("Expected no improvement areas for an unconfigured metric, got %+v", areas)
+	}
+}
+
+// TestBuiltInRules_Names tests that built-in rules have unique names
+func TestBuiltInRules_Names(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, rule := range builtInRules() {
+		if seen[rule.Name()] {
+			t.Errorf("Duplicate rule name '%s'", rule.Name())
+		}
+		seen[rule.Name()] = true
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import "github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+
+// Team identifiers used by match data
+const (
+	teamIDBlue = 100
+	teamIDRed  = 200
+)
+
+// Map side names
+const (
+	sideBlue = "BLUE"
+	sideRed  = "RED"
+)
+
+// sideForTeam returns the map side for a team ID, or an empty string when unknown
+func sideForTeam(teamID int) string {
+	switch teamID {
+	case teamIDBlue:
+		return sideBlue
+	case teamIDRed:
+		return sideRed
+	default:
+		return ""
+	}
+}
+
+// findTeam returns the team with the given ID from a match, or nil when the match has no such team
+func findTeam(match *models.Match, teamID int) *models.Team {
+	for index := range match.Teams {
+		if match.Teams[index].TeamID == teamID {
+			return &match.Teams[index]
+		}
+	}
+	return nil
+}
+
+// objectiveAccumulator sums the objectives taken by the player's team and the enemy team
+type objectiveAccumulator struct {
+	games        int
+	dragons      int
+	enemyDragons int
+	barons       int
+	towers       int
+	heralds      int
+	firstBloods  int
+	firstDragons int
+	firstTowers  int
+}
+
+// add records a single game; games without team data for the player's team are skipped
+func (accumulator *objectiveAccumulator) add(match *models.Match, participant *models.Participant) {
+	if participant.TeamID == 0 {
+		return
+	}
+
+	team := findTeam(match, participant.TeamID)
+	if team == nil {
+		return
+	}
+
+	accumulator.games++
+	accumulator.dragons += team.Objectives.Dragon.Kills
+	accumulator.barons += team.Objectives.Baron.Kills
+	accumulator.towers += team.Objectives.Tower.Kills
+	accumulator.heralds += team.Objectives.RiftHerald.Kills
+
+	if team.Objectives.Champion.First {
+		accumulator.firstBloods++
+	}
+	if team.Objectives.Dragon.First {
+		accumulator.firstDragons++
+	}
+	if team.Objectives.Tower.First {
+		accumulator.firstTowers++
+	}
+
+	for index := range match.Teams {
+		if match.Teams[index].TeamID != participant.TeamID {
+			accumulator.enemyDragons += match.Teams[index].Objectives.Dragon.Kills
+		}
+	}
+}
+
+// objectiveControl converts the accumulated totals into per-game averages and rates
+// Returns nil when no game carried team data
+func (accumulator *objectiveAccumulator) objectiveControl() *models.ObjectiveControl {
+	if accumulator.games == 0 {
+		return nil
+	}
+
+	games := float64(accumulator.games)
+
+	return &models.ObjectiveControl{
+		GamesWithData:   accumulator.games,
+		AverageDragons:  float64(accumulator.dragons) / games,
+		AverageBarons:   float64(accumulator.barons) / games,
+		AverageTowers:   float64(accumulator.towers) / games,
+		AverageHeralds:  float64(accumulator.heralds) / games,
+		DragonControl:   percentageOf(accumulator.dragons, accumulator.dragons+accumulator.enemyDragons),
+		FirstBloodRate:  percentageOf(accumulator.firstBloods, accumulator.games),
+		FirstDragonRate: percentageOf(accumulator.firstDragons, accumulator.games),
+		FirstTowerRate:  percentageOf(accumulator.firstTowers, accumulator.games),
+	}
+}
+
+// toSideStats converts accumulated totals into a side summary
+func toSideStats(accumulator *statsAccumulator, side string) models.SideStats {
+	return models.SideStats{
+		Side:        side,
+		GamesPlayed: accumulator.games,
+		Wins:        accumulator.wins,
+		WinRate:     accumulator.winRate(),
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// sideMatch builds a match where test-puuid plays on the given team with objective data for both teams
+func sideMatch(teamID int, win bool, playerObjectives models.Objectives, enemyObjectives models.Objectives) models.Match {
+	enemyTeamID := teamIDRed
+	if teamID == teamIDRed {
+		enemyTeamID = teamIDBlue
+	}
+
+	return models.Match{
+		MatchID:      "match",
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 4, Assists: 4, Win: win, TeamPosition: "MIDDLE", TeamID: teamID},
+			{PUUID: "teammate", ChampionName: "Jinx", Kills: 6, Win: win, TeamPosition: "BOTTOM", TeamID: teamID},
+			{PUUID: "opponent", ChampionName: "Zed", Kills: 5, Win: !win, TeamPosition: "MIDDLE", TeamID: enemyTeamID},
+		},
+		Teams: []models.Team{
+			{TeamID: teamID, Win: win, Objectives: playerObjectives},
+			{TeamID: enemyTeamID, Win: !win, Objectives: enemyObjectives},
+		},
+	}
+}
+
+// TestCalculatePlayerStats_SideStats tests blue-side and red-side win rates
+func TestCalculatePlayerStats_SideStats(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches := []models.Match{
+		sideMatch(teamIDBlue, true, models.Objectives{}, models.Objectives{}),
+		sideMatch(teamIDBlue, true, models.Objectives{}, models.Objectives{}),
+		sideMatch(teamIDRed, false, models.Objectives{}, models.Objectives{}),
+		sideMatch(teamIDRed, true, models.Objectives{}, models.Objectives{}),
+	}
+
+	playerStats := service.calculatePlayerStats(summoner, matches)
+
+	if playerStats.SideStats[sideBlue].WinRate != 100.0 {
+		t.Errorf("Expected blue-side win rate 100.0, got %.1f", playerStats.SideStats[sideBlue].WinRate)
+	}
+
+	if playerStats.SideStats[sideRed].GamesPlayed != 2 || playerStats.SideStats[sideRed].WinRate != 50.0 {
+		t.Errorf("Expected red-side win rate 50.0 over 2 games, got %+v", playerStats.SideStats[sideRed])
+	}
+}
+
+// TestCalculatePlayerStats_ObjectiveControl tests team objective averages and first-objective rates
+func TestCalculatePlayerStats_ObjectiveControl(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches := []models.Match{
+		sideMatch(teamIDBlue, true,
+			models.Objectives{Dragon: models.Objective{First: true, Kills: 3}, Baron: models.Objective{Kills: 1}, Tower: models.Objective{First: true, Kills: 9}, Champion: models.Objective{First: true}},
+			models.Objectives{Dragon: models.Objective{Kills: 1}, Tower: models.Objective{Kills: 2}},
+		),
+		sideMatch(teamIDRed, false,
+			models.Objectives{Dragon: models.Objective{Kills: 1}, Tower: models.Objective{Kills: 3}, RiftHerald: models.Objective{Kills: 1}},
+			models.Objectives{Dragon: models.Objective{First: true, Kills: 3}, Tower: models.Objective{First: true, Kills: 11}, Champion: models.Objective{First: true}},
+		),
+	}
+
+	// A match without team data is not counted
+	matchWithoutTeams := sideMatch(teamIDBlue, true, models.Objectives{}, models.Objectives{})
+	matchWithoutTeams.Teams = nil
+	matches = append(matches, matchWithoutTeams)
+
+	objectiveControl := service.calculatePlayerStats(summoner, matches).ObjectiveControl
+	if objectiveControl == nil {
+		t.Fatal("Expected objective control to be calculated")
+	}
+
+	if objectiveControl.GamesWithData != 2 {
+		t.Errorf("Expected 2 games with team data, got %d", objectiveControl.GamesWithData)
+	}
+
+	testCases := []struct {
+		name     string
+		actual   float64
+		expected float64
+	}{
+		{"averageDragons", objectiveControl.AverageDragons, 2.0},
+		{"averageBarons", objectiveControl.AverageBarons, 0.5},
+		{"averageTowers", objectiveControl.AverageTowers, 6.0},
+		{"averageHeralds", objectiveControl.AverageHeralds, 0.5},
+		{"dragonControl", objectiveControl.DragonControl, 50.0},
+		{"firstBloodRate", objectiveControl.FirstBloodRate, 50.0},
+		{"firstDragonRate", objectiveControl.FirstDragonRate, 50.0},
+		{"firstTowerRate", objectiveControl.FirstTowerRate, 50.0},
+	}
+
+	for _, testCase := range testCases {
+		if testCase.actual != testCase.expected {
+			t.Errorf("Expected %s %.1f, got %.1f", testCase.name, testCase.expected, testCase.actual)
+		}
+	}
+}
+
+// TestIsTeammate_Remake tests that team IDs separate teams when the outcome does not
+func TestIsTeammate_Remake(t *testing.T) {
+	player := &models.Participant{PUUID: "test-puuid", Win: false, TeamID: teamIDBlue}
+	enemy := &models.Participant{PUUID: "opponent", Win: false, TeamID: teamIDRed}
+	ally := &models.Participant{PUUID: "teammate", Win: false, TeamID: teamIDBlue}
+
+	if isTeammate(player, enemy) {
+		t.Error("Expected players on different team IDs not to be teammates")
+	}
+
+	if !isTeammate(player, ally) {
+		t.Error("Expected players on the same team ID to be teammates")
+	}
+
+	// Without team IDs the match outcome is the only signal
+	if !isTeammate(&models.Participant{Win: true}, &models.Participant{Win: true}) {
+		t.Error("Expected participants with the same outcome to be teammates when team IDs are missing")
+	}
+}
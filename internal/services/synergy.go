@@ -0,0 +1,134 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// minimumSynergyGames is the number of shared games before another player counts as recurring
+const minimumSynergyGames = 2
+
+// synergyAccumulator sums the games shared with another player
+type synergyAccumulator struct {
+	puuid        string
+	summonerName string
+	player       *statsAccumulator
+	other        *statsAccumulator
+	champions    map[string]int
+}
+
+// newSynergyAccumulator creates an empty synergyAccumulator for another player
+func newSynergyAccumulator(puuid string) *synergyAccumulator {
+	return &synergyAccumulator{
+		puuid:     puuid,
+		player:    newStatsAccumulator(),
+		other:     newStatsAccumulator(),
+		champions: make(map[string]int),
+	}
+}
+
+// add records a shared game
+func (accumulator *synergyAccumulator) add(match *models.Match, player *models.Participant, other *models.Participant) {
+	accumulator.player.add(match, player)
+	accumulator.other.add(match, other)
+	accumulator.champions[other.ChampionName]++
+
+	// Games are visited oldest first, so the last name seen is the most recent
+	if other.SummonerName != "" {
+		accumulator.summonerName = other.SummonerName
+	}
+}
+
+// toPlayerSynergy converts the accumulated totals into a synergy summary
+func (accumulator *synergyAccumulator) toPlayerSynergy(overallWinRate float64, teammate bool) models.PlayerSynergy {
+	winRate := accumulator.player.winRate()
+
+	playerSynergy := models.PlayerSynergy{
+		PUUID:        accumulator.puuid,
+		SummonerName: accumulator.summonerName,
+		GamesPlayed:  accumulator.player.games,
+		Wins:         accumulator.player.wins,
+		WinRate:      winRate,
+		WinRateDelta: winRate - overallWinRate,
+		PlayerKDA:    accumulator.player.kda(),
+		OtherKDA:     accumulator.other.kda(),
+		Champions:    accumulator.champions,
+	}
+
+	if teammate {
+		combined := &statsAccumulator{
+			games:   accumulator.player.games,
+			kills:   accumulator.player.kills + accumulator.other.kills,
+			deaths:  accumulator.player.deaths + accumulator.other.deaths,
+			assists: accumulator.player.assists + accumulator.other.assists,
+			damage:  accumulator.player.damage + accumulator.other.damage,
+		}
+		playerSynergy.CombinedKDA = combined.kda()
+		playerSynergy.AverageCombinedDamage = combined.average(combined.damage)
+	}
+
+	return playerSynergy
+}
+
+// calculateSynergy finds the teammates and opponents who appear in several of the summoner's games
+// Returns nil when no other player appears in at least minimumSynergyGames games
+func (analysisService *AnalysisService) calculateSynergy(summoner *models.Summoner, matches []models.Match, overallWinRate float64) *models.SynergyAnalysis {
+	teammateAccumulators := make(map[string]*synergyAccumulator)
+	opponentAccumulators := make(map[string]*synergyAccumulator)
+
+	for _, game := range chronologicalGames(summoner, matches) {
+		for index := range game.match.Participants {
+			other := &game.match.Participants[index]
+			if other.PUUID == "" || other.PUUID == summoner.PUUID {
+				continue
+			}
+
+			accumulators := opponentAccumulators
+			if isTeammate(game.participant, other) {
+				accumulators = teammateAccumulators
+			}
+
+			accumulator, exists := accumulators[other.PUUID]
+			if !exists {
+				accumulator = newSynergyAccumulator(other.PUUID)
+				accumulators[other.PUUID] = accumulator
+			}
+			accumulator.add(game.match, game.participant, other)
+		}
+	}
+
+	teammates := recurringPlayers(teammateAccumulators, overallWinRate, true)
+	opponents := recurringPlayers(opponentAccumulators, overallWinRate, false)
+
+	if len(teammates) == 0 && len(opponents) == 0 {
+		return nil
+	}
+
+	return &models.SynergyAnalysis{
+		Teammates: teammates,
+		Opponents: opponents,
+	}
+}
+
+// recurringPlayers returns the players with enough shared games, ordered by games then win rate
+func recurringPlayers(accumulators map[string]*synergyAccumulator, overallWinRate float64, teammate bool) []models.PlayerSynergy {
+	players := make([]models.PlayerSynergy, 0)
+	for _, accumulator := range accumulators {
+		if accumulator.player.games >= minimumSynergyGames {
+			players = append(players, accumulator.toPlayerSynergy(overallWinRate, teammate))
+		}
+	}
+
+	sort.Slice(players, func(left int, right int) bool {
+		if players[left].GamesPlayed != players[right].GamesPlayed {
+			return players[left].GamesPlayed > players[right].GamesPlayed
+		}
+		if players[left].WinRate != players[right].WinRate {
+			return players[left].WinRate > players[right].WinRate
+		}
+		return players[left].PUUID < players[right].PUUID
+	})
+
+	return players
+}
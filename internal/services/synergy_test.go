@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// synergyMatch builds a match with test-puuid, a duo partner on team 100 and a rival on team 200
+func synergyMatch(day int, win bool, duoPUUID string, rivalPUUID string) models.Match {
+	return models.Match{
+		MatchID:      "match",
+		GameCreation: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, day),
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 6, Deaths: 2, Assists: 4, TotalDamageDealtToChampions: 20000, Win: win, TeamID: 100},
+			{PUUID: duoPUUID, SummonerName: "Duo" + string(rune('A'+day)), ChampionName: "Lee Sin", Kills: 4, Deaths: 4, Assists: 8, TotalDamageDealtToChampions: 15000, Win: win, TeamID: 100},
+			{PUUID: rivalPUUID, ChampionName: "Zed", Kills: 3, Deaths: 5, Assists: 2, Win: !win, TeamID: 200},
+		},
+	}
+}
+
+// TestCalculateSynergy tests recurring teammates and opponents
+func TestCalculateSynergy(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches := []models.Match{
+		synergyMatch(0, true, "duo", "rival"),
+		synergyMatch(1, true, "duo", "rival"),
+		synergyMatch(2, true, "duo", "one-off-rival"),
+		synergyMatch(3, false, "one-off-duo", "rival"),
+	}
+
+	// Overall win rate is 75%
+	synergy := service.calculateSynergy(summoner, matches, 75.0)
+	if synergy == nil {
+		t.Fatal("Expected synergy analysis")
+	}
+
+	if len(synergy.Teammates) != 1 {
+		t.Fatalf("Expected 1 recurring teammate, got %+v", synergy.Teammates)
+	}
+
+	duo := synergy.Teammates[0]
+	if duo.PUUID != "duo" || duo.GamesPlayed != 3 || duo.WinRate != 100.0 || duo.WinRateDelta != 25.0 {
+		t.Errorf("Expected duo with 3 wins in 3 games (+25), got %+v", duo)
+	}
+
+	// Most recent name wins
+	if duo.SummonerName != "DuoC" {
+		t.Errorf("Expected most recent summoner name 'DuoC', got '%s'", duo.SummonerName)
+	}
+
+	// (6 + 4 kills + 4 + 8 assists) / (2 + 4 deaths) per game
+	if duo.CombinedKDA != 22.0/6.0 {
+		t.Errorf("Expected combined KDA %.2f, got %.2f", 22.0/6.0, duo.CombinedKDA)
+	}
+
+	if duo.AverageCombinedDamage != 35000 || duo.Champions["Lee Sin"] != 3 {
+		t.Errorf("Expected combined damage 35000 on Lee Sin, got %+v", duo)
+	}
+
+	if len(synergy.Opponents) != 1 {
+		t.Fatalf("Expected 1 recurring opponent, got %+v", synergy.Opponents)
+	}
+
+	rival := synergy.Opponents[0]
+	if rival.PUUID != "rival" || rival.GamesPlayed != 3 || rival.Wins != 2 {
+		t.Errorf("Expected 2 wins in 3 games against rival, got %+v", rival)
+	}
+
+	if rival.CombinedKDA != 0 || rival.OtherKDA != 1.0 {
+		t.Errorf("Expected no combined stats and rival KDA 1.0, got %+v", rival)
+	}
+}
+
+// TestCalculateSynergy_NoRecurringPlayers tests that synergy is omitted without recurring players
+func TestCalculateSynergy_NoRecurringPlayers(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches := []models.Match{
+		synergyMatch(0, true, "duo-1", "rival-1"),
+		synergyMatch(1, true, "duo-2", "rival-2"),
+	}
+
+	if synergy := service.calculateSynergy(summoner, matches, 100.0); synergy != nil {
+		t.Errorf("Expected no synergy analysis, got %+v", synergy)
+	}
+}
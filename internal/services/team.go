@@ -0,0 +1,43 @@
+package services
+
+import "github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+
+// teamTotals sums kills, champion damage and gold for one team in a match
+type teamTotals struct {
+	kills  int
+	damage int
+	gold   int
+}
+
+// isTeammate reports whether two participants of the same match played on the same team
+// Team IDs are used when both participants carry one; otherwise teams are told apart by the match outcome
+func isTeammate(player *models.Participant, other *models.Participant) bool {
+	if player.TeamID != 0 && other.TeamID != 0 {
+		return player.TeamID == other.TeamID
+	}
+	return player.Win == other.Win
+}
+
+// teamTotalsFor sums the totals of the player's team, including the player
+// The second return value is false when the match lists no teammates, since shares would be meaningless
+func teamTotalsFor(match *models.Match, player *models.Participant) (teamTotals, bool) {
+	var totals teamTotals
+	teammates := 0
+
+	for index := range match.Participants {
+		participant := &match.Participants[index]
+		if !isTeammate(player, participant) {
+			continue
+		}
+
+		totals.kills += participant.Kills
+		totals.damage += participant.TotalDamageDealtToChampions
+		totals.gold += participant.GoldEarned
+
+		if participant.PUUID != player.PUUID {
+			teammates++
+		}
+	}
+
+	return totals, teammates > 0
+}
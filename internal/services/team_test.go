@@ -0,0 +1,109 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// teamMatch builds a match where test-puuid plays MIDDLE alongside one teammate against one opponent
+func teamMatch() models.Match {
+	return models.Match{
+		MatchID:      "match-1",
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{PUUID: "test-puuid", ChampionName: "Ahri", Kills: 4, Deaths: 2, Assists: 4, TotalDamageDealtToChampions: 15000, GoldEarned: 10000, Win: true, TeamPosition: "MIDDLE"},
+			{PUUID: "teammate", ChampionName: "Jinx", Kills: 6, Deaths: 3, Assists: 2, TotalDamageDealtToChampions: 25000, GoldEarned: 12000, Win: true, TeamPosition: "BOTTOM"},
+			{PUUID: "opponent", ChampionName: "Zed", Kills: 5, Deaths: 4, Assists: 1, TotalDamageDealtToChampions: 30000, GoldEarned: 14000, Win: false, TeamPosition: "MIDDLE"},
+		},
+	}
+}
+
+// TestCalculatePlayerStats_TeamMetrics tests kill participation, damage share, gold share and damage per gold
+func TestCalculatePlayerStats_TeamMetrics(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	playerStats := service.calculatePlayerStats(summoner, []models.Match{teamMatch()})
+
+	testCases := []struct {
+		name     string
+		actual   float64
+		expected float64
+	}{
+		// (4 + 4) / (4 + 6) team kills
+		{"killParticipation", playerStats.KillParticipation, 80.0},
+		// 15000 / 40000 team damage
+		{"damageShare", playerStats.DamageShare, 37.5},
+		// 10000 / 22000 team gold
+		{"goldShare", playerStats.GoldShare, 45.45},
+		// 15000 damage / 10000 gold
+		{"damagePerGold", playerStats.DamagePerGold, 1.5},
+	}
+
+	for _, testCase := range testCases {
+		if math.Abs(testCase.actual-testCase.expected) > 0.01 {
+			t.Errorf("Expected %s %.2f, got %.2f", testCase.name, testCase.expected, testCase.actual)
+		}
+	}
+
+	if playerStats.RoleStats[roleMiddle].KillParticipation != playerStats.KillParticipation {
+		t.Errorf("Expected MIDDLE kill participation to match overall, got %.2f", playerStats.RoleStats[roleMiddle].KillParticipation)
+	}
+}
+
+// TestCalculatePlayerStats_TeamMetricsWithoutTeammates tests that shares are left empty without teammates
+func TestCalculatePlayerStats_TeamMetricsWithoutTeammates(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	match := teamMatch()
+	match.Participants = match.Participants[:1]
+
+	playerStats := service.calculatePlayerStats(summoner, []models.Match{match})
+
+	if playerStats.KillParticipation != 0 || playerStats.DamageShare != 0 || playerStats.GoldShare != 0 {
+		t.Errorf("Expected no team shares without teammates, got %+v", playerStats)
+	}
+
+	// Damage per gold only depends on the player's own row
+	if playerStats.DamagePerGold != 1.5 {
+		t.Errorf("Expected damage per gold 1.5, got %.2f", playerStats.DamagePerGold)
+	}
+
+	if _, exists := playerMetricValues(&playerStats)[metricKillParticipation]; exists {
+		t.Error("Expected kill participation to be omitted from metric values")
+	}
+}
+
+// TestIdentifyImprovementAreas_LowKillParticipation tests the kill participation rule against role benchmarks
+func TestIdentifyImprovementAreas_LowKillParticipation(t *testing.T) {
+	service := NewAnalysisService()
+
+	playerStats := &models.PlayerStats{
+		RoleStats: map[string]models.RoleStats{
+			roleJungle: {Role: roleJungle, GamesPlayed: 10, WinRate: 55.0, KDA: 3.5, CSPerMinute: 5.5, AverageVisionScore: 35.0, AverageDeaths: 4.0, KillParticipation: 40.0, DamageShare: 18.0},
+		},
+		WinRate: 55.0,
+	}
+
+	areas := service.identifyImprovementAreas(&RuleContext{PlayerStats: playerStats})
+
+	foundKillParticipation := false
+	for _, area := range areas {
+		switch area.Category {
+		case "Kill Participation":
+			foundKillParticipation = true
+			if area.Priority != priorityHigh || area.ExpectedValue != 60.0 || area.Role != roleJungle {
+				t.Errorf("Expected HIGH JUNGLE kill participation area against 60.0, got %+v", area)
+			}
+		case "Damage Share":
+			t.Errorf("Expected jungle damage share of 18%% to meet the benchmark, got %+v", area)
+		}
+	}
+
+	if !foundKillParticipation {
+		t.Error("Expected kill participation improvement area to be identified")
+	}
+}
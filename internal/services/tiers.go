@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// GoalClimb compares the player against the next tier up instead of their current tier
+const GoalClimb = "climb"
+
+// rankedTiers lists the ranked tiers from lowest to highest
+var rankedTiers = []string{
+	"IRON",
+	"BRONZE",
+	"SILVER",
+	"GOLD",
+	"PLATINUM",
+	"EMERALD",
+	"DIAMOND",
+	"MASTER",
+	"GRANDMASTER",
+	"CHALLENGER",
+}
+
+// divisionProgress maps a division to how far the player has progressed toward the next tier
+var divisionProgress = map[string]float64{
+	"IV":  0.0,
+	"III": 0.25,
+	"II":  0.5,
+	"I":   0.75,
+}
+
+// benchmarkSelection identifies which tier's expectations a player is compared against
+// The zero value compares against the reference tier
+type benchmarkSelection struct {
+	Tier     string
+	Division string
+	Goal     string
+}
+
+// newBenchmarkSelection builds a benchmarkSelection from the summoner's rank and the request options
+func newBenchmarkSelection(summoner *models.Summoner, options models.AnalysisOptions) benchmarkSelection {
+	return benchmarkSelection{
+		Tier:     normalizeTier(summoner.Tier),
+		Division: strings.ToUpper(strings.TrimSpace(summoner.Division)),
+		Goal:     strings.ToLower(strings.TrimSpace(options.Goal)),
+	}
+}
+
+// ValidateBenchmarkSelection checks that the summoner's rank and the requested goal are known
+// Failures are returned as *FieldError
+func ValidateBenchmarkSelection(summoner *models.Summoner, options models.AnalysisOptions) error {
+	if summoner.Tier != "" && normalizeTier(summoner.Tier) == "" {
+		return newFieldError("/summoner/tier", fmt.Sprintf("unknown tier %q", summoner.Tier))
+	}
+
+	if summoner.Division != "" {
+		if _, exists := divisionProgress[strings.ToUpper(strings.TrimSpace(summoner.Division))]; !exists {
+			return newFieldError("/summoner/division", fmt.Sprintf("unknown division %q", summoner.Division))
+		}
+	}
+
+	goal := strings.ToLower(strings.TrimSpace(options.Goal))
+	if goal != "" && goal != GoalClimb {
+		return newFieldError("/options/goal", fmt.Sprintf("unknown goal %q", options.Goal))
+	}
+
+	return nil
+}
+
+// normalizeTier converts a tier name to its canonical form, returning an empty string when unknown
+func normalizeTier(tier string) string {
+	normalizedTier := strings.ToUpper(strings.TrimSpace(tier))
+	if tierIndex(normalizedTier) >= 0 {
+		return normalizedTier
+	}
+	return ""
+}
+
+// tierIndex returns the position of a tier in rankedTiers, or -1 when unknown
+func tierIndex(tier string) int {
+	for index, rankedTier := range rankedTiers {
+		if rankedTier == tier {
+			return index
+		}
+	}
+	return -1
+}
+
+// isApexTier reports whether a tier has no divisions
+func isApexTier(tier string) bool {
+	return tierIndex(tier) >= tierIndex("MASTER")
+}
+
+// targetTier returns the tier whose benchmarks apply to this selection
+// Unranked players are judged against the reference tier
+func (selection benchmarkSelection) targetTier(referenceTier string) string {
+	if selection.Tier == "" {
+		return referenceTier
+	}
+
+	if selection.Goal == GoalClimb {
+		nextIndex := tierIndex(selection.Tier) + 1
+		if nextIndex < len(rankedTiers) {
+			return rankedTiers[nextIndex]
+		}
+	}
+
+	return selection.Tier
+}
+
+// tierValue returns the benchmark for this selection from a tier benchmark table
+// Within a tier the value is interpolated toward the next tier based on the division
+func (selection benchmarkSelection) tierValue(tierBenchmarks map[string]float64, referenceTier string) float64 {
+	target := selection.targetTier(referenceTier)
+	value := tierBenchmarks[target]
+
+	// Division progress only applies when judging against the player's own, non-apex tier
+	if target != selection.Tier || isApexTier(target) {
+		return value
+	}
+
+	progress, exists := divisionProgress[selection.Division]
+	if !exists || progress == 0 {
+		return value
+	}
+
+	nextValue := tierBenchmarks[rankedTiers[tierIndex(target)+1]]
+	return value + (nextValue-value)*progress
+}
+
+// scaleBenchmark adjusts a benchmark calibrated for the reference tier to the selected tier
+func (selection benchmarkSelection) scaleBenchmark(benchmark float64, tierBenchmarks map[string]float64, referenceTier string) float64 {
+	referenceValue := tierBenchmarks[referenceTier]
+	if referenceValue <= 0 {
+		return benchmark
+	}
+	return benchmark * selection.tierValue(tierBenchmarks, referenceTier) / referenceValue
+}
+
+// info describes the selection for inclusion in the analysis result
+func (selection benchmarkSelection) info(ruleConfig *RuleConfig) models.BenchmarkInfo {
+	return models.BenchmarkInfo{
+		Version:    ruleConfig.Version,
+		Tier:       selection.Tier,
+		Division:   selection.Division,
+		TargetTier: selection.targetTier(ruleConfig.ReferenceTier),
+		Goal:       selection.Goal,
+	}
+}
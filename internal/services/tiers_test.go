@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// TestBenchmarkSelection_TargetTier tests tier selection for current and climb goals
+func TestBenchmarkSelection_TargetTier(t *testing.T) {
+	testCases := []struct {
+		name      string
+		selection benchmarkSelection
+		expected  string
+	}{
+		{"unranked uses reference tier", benchmarkSelection{}, "GOLD"},
+		{"current tier", benchmarkSelection{Tier: "SILVER"}, "SILVER"},
+		{"climb goal uses next tier", benchmarkSelection{Tier: "SILVER", Goal: GoalClimb}, "GOLD"},
+		{"climb from challenger stays", benchmarkSelection{Tier: "CHALLENGER", Goal: GoalClimb}, "CHALLENGER"},
+		{"climb while unranked uses reference tier", benchmarkSelection{Goal: GoalClimb}, "GOLD"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := testCase.selection.targetTier("GOLD")
+			if result != testCase.expected {
+				t.Errorf("Expected target tier '%s', got '%s'", testCase.expected, result)
+			}
+		})
+	}
+}
+
+// TestBenchmarkSelection_DivisionInterpolation tests that higher divisions move toward the next tier
+func TestBenchmarkSelection_DivisionInterpolation(t *testing.T) {
+	tierBenchmarks := map[string]float64{"SILVER": 5.5, "GOLD": 6.0}
+
+	divisionFour := benchmarkSelection{Tier: "SILVER", Division: "IV"}.tierValue(tierBenchmarks, "GOLD")
+	divisionTwo := benchmarkSelection{Tier: "SILVER", Division: "II"}.tierValue(tierBenchmarks, "GOLD")
+
+	if divisionFour != 5.5 {
+		t.Errorf("Expected SILVER IV CS benchmark 5.50, got %.2f", divisionFour)
+	}
+
+	// SILVER II is halfway between SILVER (5.5) and GOLD (6.0)
+	if divisionTwo != 5.75 {
+		t.Errorf("Expected SILVER II CS benchmark 5.75, got %.2f", divisionTwo)
+	}
+}
+
+// TestBenchmarkSelection_ScaleBenchmark tests scaling a reference-tier benchmark to another tier
+func TestBenchmarkSelection_ScaleBenchmark(t *testing.T) {
+	tierBenchmarks := map[string]float64{"IRON": 4.5, "GOLD": 6.0}
+
+	referenceValue := benchmarkSelection{}.scaleBenchmark(7.0, tierBenchmarks, "GOLD")
+	if referenceValue != 7.0 {
+		t.Errorf("Expected reference tier to leave benchmark unchanged, got %.2f", referenceValue)
+	}
+
+	// A 7.0 MIDDLE benchmark at GOLD scales by 4.5 / 6.0 for IRON
+	ironValue := benchmarkSelection{Tier: "IRON"}.scaleBenchmark(7.0, tierBenchmarks, "GOLD")
+	if ironValue != 5.25 {
+		t.Errorf("Expected IRON benchmark 5.25, got %.2f", ironValue)
+	}
+}
+
+// TestValidateBenchmarkSelection tests tier, division and goal validation
+func TestValidateBenchmarkSelection(t *testing.T) {
+	testCases := []struct {
+		name        string
+		summoner    models.Summoner
+		options     models.AnalysisOptions
+		expectError bool
+	}{
+		{"unranked", models.Summoner{}, models.AnalysisOptions{}, false},
+		{"valid tier and division", models.Summoner{Tier: "gold", Division: "ii"}, models.AnalysisOptions{Goal: "climb"}, false},
+		{"unknown tier", models.Summoner{Tier: "WOOD"}, models.AnalysisOptions{}, true},
+		{"unknown division", models.Summoner{Tier: "GOLD", Division: "V"}, models.AnalysisOptions{}, true},
+		{"unknown goal", models.Summoner{Tier: "GOLD"}, models.AnalysisOptions{Goal: "smurf"}, true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ValidateBenchmarkSelection(&testCase.summoner, testCase.options)
+			if testCase.expectError && err == nil {
+				t.Error("Expected validation error")
+			}
+			if !testCase.expectError && err != nil {
+				t.Errorf("Expected no validation error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestAnalyzePlayerWithOptions_ClimbGoal tests that the climb goal raises expectations
+func TestAnalyzePlayerWithOptions_ClimbGoal(t *testing.T) {
+	service := NewAnalysisService()
+
+	playerStats := &models.PlayerStats{
+		CSPerMinute:        6.0,
+		AverageVisionScore: 45.0,
+		KDA:                3.0,
+		AverageDeaths:      5.0,
+		WinRate:            55.0,
+	}
+
+	currentTierAreas := service.identifyImprovementAreas(&RuleContext{Summoner: &models.Summoner{Tier: "SILVER"}, PlayerStats: playerStats})
+	for _, area := range currentTierAreas {
+		if area.Category == "CS (Creep Score)" {
+			t.Error("Expected no CS improvement area against SILVER benchmarks")
+		}
+	}
+
+	climbAreas := service.identifyImprovementAreas(&RuleContext{Summoner: &models.Summoner{Tier: "DIAMOND"}, PlayerStats: playerStats, Options: models.AnalysisOptions{Goal: GoalClimb}})
+	foundCS := false
+	for _, area := range climbAreas {
+		if area.Category == "CS (Creep Score)" {
+			foundCS = true
+			masterBenchmark := findMetricRule(t, DefaultRuleConfig(), metricCSPerMinute).TierBenchmarks["MASTER"]
+			if area.ExpectedValue != masterBenchmark {
+				t.Errorf("Expected MASTER CS benchmark %.1f, got %.1f", masterBenchmark, area.ExpectedValue)
+			}
+		}
+	}
+
+	if !foundCS {
+		t.Error("Expected CS improvement area when climbing from DIAMOND")
+	}
+}
+
+// TestAnalyzePlayerWithOptions_BenchmarkInfo tests that the result reports the benchmark table used
+func TestAnalyzePlayerWithOptions_BenchmarkInfo(t *testing.T) {
+	service := NewAnalysisService()
+
+	summoner := &models.Summoner{PUUID: "test-puuid", Tier: "platinum", Division: "III"}
+
+	result := service.AnalyzePlayerWithOptions(context.Background(), summoner, []models.Match{}, models.AnalysisOptions{Goal: "climb"})
+
+	if result.Benchmark.Version != DefaultRuleConfig().Version {
+		t.Errorf("Expected benchmark version '%s', got '%s'", DefaultRuleConfig().Version, result.Benchmark.Version)
+	}
+
+	if result.Benchmark.Tier != "PLATINUM" {
+		t.Errorf("Expected tier 'PLATINUM', got '%s'", result.Benchmark.Tier)
+	}
+
+	if result.Benchmark.TargetTier != "EMERALD" {
+		t.Errorf("Expected target tier 'EMERALD', got '%s'", result.Benchmark.TargetTier)
+	}
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// Trend labels
+const (
+	trendImproving = "improving"
+	trendDeclining = "declining"
+	trendStable    = "stable"
+)
+
+const (
+	// minimumTrendWindow is the smallest number of games compared in each window
+	minimumTrendWindow = 3
+	// maximumTrendWindow caps each window so "recent" stays recent for long histories
+	maximumTrendWindow = 10
+)
+
+// trendMetric describes how a metric is read from a window and how much it must move to count as a trend
+type trendMetric struct {
+	metric        string
+	lowerIsBetter bool
+	// Smallest absolute change that is not considered noise
+	threshold float64
+	value     func(accumulator *statsAccumulator) float64
+}
+
+// trendMetrics lists the metrics tracked over time, in output order
+var trendMetrics = []trendMetric{
+	{metric: metricWinRate, threshold: 10.0, value: (*statsAccumulator).winRate},
+	{metric: metricKDA, threshold: 0.5, value: (*statsAccumulator).kda},
+	{metric: metricCSPerMinute, threshold: 0.5, value: (*statsAccumulator).csPerMinute},
+	{metric: metricVisionScore, threshold: 3.0, value: func(accumulator *statsAccumulator) float64 {
+		return accumulator.average(accumulator.visionScore)
+	}},
+	{metric: metricDeaths, lowerIsBetter: true, threshold: 1.0, value: func(accumulator *statsAccumulator) float64 {
+		return accumulator.average(accumulator.deaths)
+	}},
+}
+
+// playerGame pairs a match with the analyzed player's participation in it
+type playerGame struct {
+	match       *models.Match
+	participant *models.Participant
+}
+
+// calculateTrends compares the most recent games with the same number of games before them
+// Returns nil when there are not enough games to fill two windows
+func (analysisService *AnalysisService) calculateTrends(summoner *models.Summoner, matches []models.Match) *models.TrendAnalysis {
+	games := chronologicalGames(summoner, matches)
+
+	windowSize := len(games) / 2
+	if windowSize > maximumTrendWindow {
+		windowSize = maximumTrendWindow
+	}
+	if windowSize < minimumTrendWindow {
+		return nil
+	}
+
+	recentGames := games[len(games)-windowSize:]
+	olderGames := games[len(games)-2*windowSize : len(games)-windowSize]
+
+	olderAccumulator := accumulateGames(olderGames)
+	recentAccumulator := accumulateGames(recentGames)
+
+	metricTrends := make([]models.MetricTrend, 0, len(trendMetrics))
+	for _, trendMetric := range trendMetrics {
+		olderValue := trendMetric.value(olderAccumulator)
+		recentValue := trendMetric.value(recentAccumulator)
+
+		metricTrends = append(metricTrends, models.MetricTrend{
+			Metric:      trendMetric.metric,
+			OlderValue:  roundTo(olderValue, 2),
+			RecentValue: roundTo(recentValue, 2),
+			Change:      roundTo(recentValue-olderValue, 2),
+			Trend:       trendMetric.label(recentValue - olderValue),
+		})
+	}
+
+	return &models.TrendAnalysis{
+		WindowSize:        windowSize,
+		OlderWindowStart:  olderGames[0].match.GameCreation,
+		RecentWindowStart: recentGames[0].match.GameCreation,
+		Metrics:           metricTrends,
+	}
+}
+
+// label classifies a change as improving, declining or stable
+func (trendMetric trendMetric) label(change float64) string {
+	if trendMetric.lowerIsBetter {
+		change = -change
+	}
+
+	switch {
+	case change >= trendMetric.threshold:
+		return trendImproving
+	case change <= -trendMetric.threshold:
+		return trendDeclining
+	default:
+		return trendStable
+	}
+}
+
+// chronologicalGames returns the player's games ordered from oldest to newest by GameCreation
+func chronologicalGames(summoner *models.Summoner, matches []models.Match) []playerGame {
+	var games []playerGame
+	for matchIndex := range matches {
+		match := &matches[matchIndex]
+		for participantIndex := range match.Participants {
+			if match.Participants[participantIndex].PUUID == summoner.PUUID {
+				games = append(games, playerGame{match: match, participant: &match.Participants[participantIndex]})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(games, func(left int, right int) bool {
+		return games[left].match.GameCreation.Before(games[right].match.GameCreation)
+	})
+
+	return games
+}
+
+// accumulateGames sums the player's totals over a set of games
+func accumulateGames(games []playerGame) *statsAccumulator {
+	accumulator := newStatsAccumulator()
+	for _, game := range games {
+		accumulator.add(game.match, game.participant)
+	}
+	return accumulator
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// trendMatch builds a 30-minute match for test-puuid created the given number of days after a fixed start
+func trendMatch(day int, win bool, kills int, deaths int, creepScore int, visionScore int) models.Match {
+	return models.Match{
+		MatchID:      "match",
+		GameCreation: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, day),
+		GameDuration: 1800,
+		Participants: []models.Participant{
+			{
+				PUUID:              "test-puuid",
+				ChampionName:       "Ahri",
+				Kills:              kills,
+				Deaths:             deaths,
+				Assists:            5,
+				TotalMinionsKilled: creepScore,
+				VisionScore:        visionScore,
+				Win:                win,
+			},
+		},
+	}
+}
+
+// findTrend returns the trend for a metric or fails the test
+func findTrend(t *testing.T, trends *models.TrendAnalysis, metric string) models.MetricTrend {
+	t.Helper()
+
+	for _, metricTrend := range trends.Metrics {
+		if metricTrend.Metric == metric {
+			return metricTrend
+		}
+	}
+
+	t.Fatalf("Expected trend for metric '%s'", metric)
+	return models.MetricTrend{}
+}
+
+// TestCalculateTrends_Improving tests that recent gains are labelled improving regardless of input order
+func TestCalculateTrends_Improving(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	// Recent games (days 3-5) are listed first to check ordering by GameCreation
+	matches := []models.Match{
+		trendMatch(5, true, 8, 2, 240, 30),
+		trendMatch(4, true, 8, 2, 240, 30),
+		trendMatch(3, true, 8, 2, 240, 30),
+		trendMatch(2, false, 3, 7, 150, 30),
+		trendMatch(1, false, 3, 7, 150, 30),
+		trendMatch(0, false, 3, 7, 150, 30),
+	}
+
+	trends := service.calculateTrends(summoner, matches)
+	if trends == nil {
+		t.Fatal("Expected trends for six games")
+	}
+
+	if trends.WindowSize != 3 {
+		t.Errorf("Expected window size 3, got %d", trends.WindowSize)
+	}
+
+	if !trends.RecentWindowStart.Equal(matches[2].GameCreation) {
+		t.Errorf("Expected recent window to start at day 3, got %v", trends.RecentWindowStart)
+	}
+
+	testCases := []struct {
+		metric   string
+		expected string
+	}{
+		{metricWinRate, trendImproving},
+		{metricKDA, trendImproving},
+		{metricCSPerMinute, trendImproving},
+		{metricVisionScore, trendStable},
+		// Fewer deaths is an improvement
+		{metricDeaths, trendImproving},
+	}
+
+	for _, testCase := range testCases {
+		metricTrend := findTrend(t, trends, testCase.metric)
+		if metricTrend.Trend != testCase.expected {
+			t.Errorf("Expected %s to be %s, got %s (%+v)", testCase.metric, testCase.expected, metricTrend.Trend, metricTrend)
+		}
+	}
+
+	if change := findTrend(t, trends, metricCSPerMinute).Change; change != 3.0 {
+		t.Errorf("Expected CS/min change 3.0, got %.2f", change)
+	}
+}
+
+// TestCalculateTrends_Declining tests that recent drops are labelled declining
+func TestCalculateTrends_Declining(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches := []models.Match{
+		trendMatch(0, true, 8, 2, 240, 40),
+		trendMatch(1, true, 8, 2, 240, 40),
+		trendMatch(2, true, 8, 2, 240, 40),
+		trendMatch(3, false, 3, 7, 240, 20),
+		trendMatch(4, false, 3, 7, 240, 20),
+		trendMatch(5, false, 3, 7, 240, 20),
+	}
+
+	trends := service.calculateTrends(summoner, matches)
+	if trends == nil {
+		t.Fatal("Expected trends for six games")
+	}
+
+	if trend := findTrend(t, trends, metricDeaths).Trend; trend != trendDeclining {
+		t.Errorf("Expected deaths to be declining, got %s", trend)
+	}
+
+	if trend := findTrend(t, trends, metricVisionScore).Trend; trend != trendDeclining {
+		t.Errorf("Expected vision to be declining, got %s", trend)
+	}
+
+	if trend := findTrend(t, trends, metricCSPerMinute).Trend; trend != trendStable {
+		t.Errorf("Expected CS/min to be stable, got %s", trend)
+	}
+}
+
+// TestCalculateTrends_WindowCap tests that long histories compare only the most recent games
+func TestCalculateTrends_WindowCap(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	var matches []models.Match
+	for day := 0; day < 30; day++ {
+		matches = append(matches, trendMatch(day, true, 5, 5, 200, 30))
+	}
+
+	trends := service.calculateTrends(summoner, matches)
+	if trends == nil {
+		t.Fatal("Expected trends for thirty games")
+	}
+
+	if trends.WindowSize != maximumTrendWindow {
+		t.Errorf("Expected window size %d, got %d", maximumTrendWindow, trends.WindowSize)
+	}
+
+	if !trends.OlderWindowStart.Equal(matches[10].GameCreation) {
+		t.Errorf("Expected older window to start at day 10, got %v", trends.OlderWindowStart)
+	}
+}
+
+// TestCalculateTrends_TooFewGames tests that trends are omitted for short histories
+func TestCalculateTrends_TooFewGames(t *testing.T) {
+	service := NewAnalysisService()
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches := []models.Match{
+		trendMatch(0, true, 8, 2, 240, 30),
+		trendMatch(1, true, 8, 2, 240, 30),
+		trendMatch(2, true, 8, 2, 240, 30),
+		trendMatch(3, true, 8, 2, 240, 30),
+		trendMatch(4, true, 8, 2, 240, 30),
+	}
+
+	if trends := service.calculateTrends(summoner, matches); trends != nil {
+		t.Errorf("Expected no trends for five games, got %+v", trends)
+	}
+
+	if result := service.AnalyzePlayer(summoner, matches); result.Trends != nil {
+		t.Error("Expected analysis result to omit trends for five games")
+	}
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// Validation modes for analysis requests
+const (
+	// ValidationStrict rejects a request containing any invalid match
+	ValidationStrict = "strict"
+	// ValidationLenient drops invalid matches and reports them and participants without a PUUID as warnings
+	ValidationLenient = "lenient"
+)
+
+// ValidationErrors lists every invalid field of a request rejected by strict validation
+type ValidationErrors []*FieldError
+
+// Error joins the failure descriptions
+func (validationErrors ValidationErrors) Error() string {
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		messages = append(messages, fieldError.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// NormalizeValidationMode converts a validation mode to its canonical form, returning an empty string when unknown
+func NormalizeValidationMode(mode string) string {
+	switch normalizedMode := strings.ToLower(strings.TrimSpace(mode)); normalizedMode {
+	case ValidationStrict, ValidationLenient:
+		return normalizedMode
+	default:
+		return ""
+	}
+}
+
+// ValidateMatches enforces the invariants the analysis relies on:
+// a summoner PUUID, positive game durations, unique match IDs, the summoner among each match's
+// participants and a PUUID on every participant
+// In strict mode any violation returns ValidationErrors; in lenient mode offending matches are dropped
+// and reported as warnings. Participants without a PUUID are kept in lenient mode, as they still count
+// towards team totals, and only reported. A missing summoner PUUID is rejected in both modes
+// AnalysisService and MatchIngestor apply lenient validation themselves, so callers only need strict mode
+// to reject a request outright
+func ValidateMatches(summoner *models.Summoner, matches []models.Match, mode string) ([]models.Match, []models.ValidationWarning, error) {
+	return validateMatches(summoner, matches, mode, false)
+}
+
+// validateMatches implements ValidateMatches, additionally requiring a match ID and game creation time
+// on every match when storable is set
+func validateMatches(summoner *models.Summoner, matches []models.Match, mode string, storable bool) ([]models.Match, []models.ValidationWarning, error) {
+	if summoner.PUUID == "" {
+		return nil, nil, ValidationErrors{newFieldError("/summoner/puuid", "summoner PUUID is required")}
+	}
+
+	var validationErrors ValidationErrors
+	var warnings []models.ValidationWarning
+	validMatches := make([]models.Match, 0, len(matches))
+	firstIndexByID := make(map[string]int)
+
+	for matchIndex, match := range matches {
+		matchPointer := fmt.Sprintf("/matches/%d", matchIndex)
+
+		participantErrors := validateParticipants(match.Participants, matchPointer)
+		matchErrors := validateMatch(summoner, &match, matchIndex, matchPointer, firstIndexByID)
+		if storable {
+			matchErrors = append(validateStorableMatch(&match, matchPointer), matchErrors...)
+		}
+
+		if mode == ValidationStrict {
+			validationErrors = append(validationErrors, matchErrors...)
+			validationErrors = append(validationErrors, participantErrors...)
+			continue
+		}
+
+		if len(matchErrors) > 0 {
+			for _, matchError := range matchErrors {
+				warnings = append(warnings, models.ValidationWarning{
+					Pointer: matchPointer,
+					Message: "match dropped: " + matchError.Message,
+				})
+			}
+			continue
+		}
+
+		for _, participantError := range participantErrors {
+			warnings = append(warnings, models.ValidationWarning{
+				Pointer: participantError.Pointer,
+				Message: "participant counted in team totals only: " + participantError.Message,
+			})
+		}
+
+		validMatches = append(validMatches, match)
+	}
+
+	if len(validationErrors) > 0 {
+		return nil, nil, validationErrors
+	}
+
+	return validMatches, warnings, nil
+}
+
+// validateMatch checks the match-level invariants, recording the first index of each match ID
+func validateMatch(summoner *models.Summoner, match *models.Match, matchIndex int, matchPointer string, firstIndexByID map[string]int) []*FieldError {
+	var matchErrors []*FieldError
+
+	if match.GameDuration <= 0 {
+		matchErrors = append(matchErrors, newFieldError(matchPointer+"/gameDuration", fmt.Sprintf("gameDuration must be positive, got %d", match.GameDuration)))
+	}
+
+	if match.MatchID != "" {
+		if firstIndex, duplicate := firstIndexByID[match.MatchID]; duplicate {
+			matchErrors = append(matchErrors, newFieldError(matchPointer+"/matchId", fmt.Sprintf("duplicate matchId %q (first at /matches/%d)", match.MatchID, firstIndex)))
+		} else {
+			firstIndexByID[match.MatchID] = matchIndex
+		}
+	}
+
+	if findParticipant(match, summoner.PUUID) == nil {
+		matchErrors = append(matchErrors, newFieldError(matchPointer+"/participants", "no participant matches the summoner PUUID"))
+	}
+
+	return matchErrors
+}
+
+// validateParticipants checks that every participant has a PUUID
+func validateParticipants(participants []models.Participant, matchPointer string) []*FieldError {
+	var participantErrors []*FieldError
+	for participantIndex, participant := range participants {
+		if participant.PUUID == "" {
+			participantErrors = append(participantErrors, newFieldError(fmt.Sprintf("%s/participants/%d/puuid", matchPointer, participantIndex), "participant PUUID is required"))
+		}
+	}
+	return participantErrors
+}
+
+// screenInvalidMatches applies lenient validation in front of an analysis or ingestion, so direct callers
+// get the same invariants as API requests. Without a summoner PUUID no match can be attributed to the
+// player, so every match is dropped
+func screenInvalidMatches(summoner *models.Summoner, matches []models.Match, storable bool) ([]models.Match, []models.ValidationWarning) {
+	validMatches, warnings, err := validateMatches(summoner, matches, ValidationLenient, storable)
+	if err != nil {
+		if len(matches) == 0 {
+			return nil, nil
+		}
+		return nil, []models.ValidationWarning{{Pointer: "/summoner/puuid", Message: "all matches dropped: " + err.Error()}}
+	}
+	return validMatches, warnings
+}
+
+// ValidateIngestedMatches requires a match ID and game creation time on every match, as stored match
+// histories are deduplicated by ID and ordered by game creation
+// It is applied before ValidateMatches so pointers index the request as sent
+func ValidateIngestedMatches(matches []models.Match) error {
+	var validationErrors ValidationErrors
+	for matchIndex := range matches {
+		validationErrors = append(validationErrors, validateStorableMatch(&matches[matchIndex], fmt.Sprintf("/matches/%d", matchIndex))...)
+	}
+
+	if len(validationErrors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+// validateStorableMatch checks that a match has the match ID and game creation time a stored history needs
+func validateStorableMatch(match *models.Match, matchPointer string) []*FieldError {
+	var matchErrors []*FieldError
+	if match.MatchID == "" {
+		matchErrors = append(matchErrors, newFieldError(matchPointer+"/matchId", "matchId is required"))
+	}
+	if match.GameCreation.IsZero() {
+		matchErrors = append(matchErrors, newFieldError(matchPointer+"/gameCreation", "gameCreation is required"))
+	}
+	return matchErrors
+}
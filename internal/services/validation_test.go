@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// invalidMatches returns one valid match followed by one match per violated invariant
+func invalidMatches() []models.Match {
+	player := models.Participant{PUUID: "test-puuid", Kills: 5, Deaths: 2, Assists: 6}
+
+	return []models.Match{
+		{MatchID: "valid", GameDuration: 1800, Participants: []models.Participant{player}},
+		{MatchID: "negative-duration", GameDuration: -60, Participants: []models.Participant{player}},
+		{MatchID: "valid", GameDuration: 1800, Participants: []models.Participant{player}},
+		{MatchID: "missing-player", GameDuration: 1800, Participants: []models.Participant{{PUUID: "someone-else"}}},
+		{MatchID: "empty-participant", GameDuration: 1800, Participants: []models.Participant{player, {ChampionName: "Teemo"}}},
+	}
+}
+
+// TestValidateMatches_Lenient tests that invalid matches are dropped and participants without a PUUID reported
+func TestValidateMatches_Lenient(t *testing.T) {
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches, warnings, err := ValidateMatches(summoner, invalidMatches(), ValidationLenient)
+	if err != nil {
+		t.Fatalf("Expected no error in lenient mode, got %v", err)
+	}
+
+	if len(matches) != 2 || matches[0].MatchID != "valid" || matches[1].MatchID != "empty-participant" {
+		t.Fatalf("Expected the valid match and the match with an incomplete participant to remain, got %+v", matches)
+	}
+
+	// The participant without a PUUID still counts towards team totals
+	if len(matches[1].Participants) != 2 {
+		t.Errorf("Expected the participant without a PUUID to be kept, got %+v", matches[1].Participants)
+	}
+
+	expectedPointers := []string{"/matches/1", "/matches/2", "/matches/3", "/matches/4/participants/1/puuid"}
+	if len(warnings) != len(expectedPointers) {
+		t.Fatalf("Expected %d warnings, got %+v", len(expectedPointers), warnings)
+	}
+
+	for index, warning := range warnings {
+		if warning.Pointer != expectedPointers[index] {
+			t.Errorf("Expected warning %d at %s, got %+v", index, expectedPointers[index], warning)
+		}
+	}
+}
+
+// TestValidateMatches_Strict tests that every violation is reported and nothing is returned
+func TestValidateMatches_Strict(t *testing.T) {
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	matches, warnings, err := ValidateMatches(summoner, invalidMatches(), ValidationStrict)
+	if matches != nil || warnings != nil {
+		t.Errorf("Expected no matches or warnings in strict mode, got %+v and %+v", matches, warnings)
+	}
+
+	var validationErrors ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+
+	expectedPointers := []string{
+		"/matches/1/gameDuration",
+		"/matches/2/matchId",
+		"/matches/3/participants",
+		"/matches/4/participants/1/puuid",
+	}
+	if len(validationErrors) != len(expectedPointers) {
+		t.Fatalf("Expected %d errors, got %v", len(expectedPointers), validationErrors)
+	}
+
+	for index, fieldError := range validationErrors {
+		if fieldError.Pointer != expectedPointers[index] {
+			t.Errorf("Expected error %d at %s, got %+v", index, expectedPointers[index], fieldError)
+		}
+	}
+}
+
+// TestValidateMatches_MissingSummonerPUUID tests that a summoner without a PUUID is rejected in both modes
+func TestValidateMatches_MissingSummonerPUUID(t *testing.T) {
+	for _, mode := range []string{ValidationStrict, ValidationLenient} {
+		_, _, err := ValidateMatches(&models.Summoner{Name: "NoPUUID"}, nil, mode)
+
+		var validationErrors ValidationErrors
+		if !errors.As(err, &validationErrors) || validationErrors[0].Pointer != "/summoner/puuid" {
+			t.Errorf("Expected %s mode to reject the missing summoner PUUID, got %v", mode, err)
+		}
+	}
+}
+
+// TestNormalizeValidationMode tests validation mode normalization
+func TestNormalizeValidationMode(t *testing.T) {
+	testCases := map[string]string{
+		"strict":   ValidationStrict,
+		" Lenient": ValidationLenient,
+		"paranoid": "",
+		"":         "",
+	}
+
+	for mode, expected := range testCases {
+		if normalized := NormalizeValidationMode(mode); normalized != expected {
+			t.Errorf("Expected '%s' to normalize to '%s', got '%s'", mode, expected, normalized)
+		}
+	}
+}
+
+// TestValidateIngestedMatches tests that matches without an ID or game creation time are reported by index
+func TestValidateIngestedMatches(t *testing.T) {
+	gameCreation := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	matches := []models.Match{{MatchID: "EUW1_1", GameCreation: gameCreation}, {GameCreation: gameCreation}, {MatchID: "EUW1_3"}}
+
+	var validationErrors ValidationErrors
+	if err := ValidateIngestedMatches(matches); !errors.As(err, &validationErrors) || len(validationErrors) != 2 ||
+		validationErrors[0].Pointer != "/matches/1/matchId" || validationErrors[1].Pointer != "/matches/2/gameCreation" {
+		t.Errorf("Expected the missing matchId at /matches/1/matchId and gameCreation at /matches/2/gameCreation, got %v", err)
+	}
+
+	if err := ValidateIngestedMatches(matches[:1]); err != nil {
+		t.Errorf("Expected complete matches to pass, got %v", err)
+	}
+}
+
+// TestAnalyzePlayer_ValidatesMatches tests that direct callers of the analysis get lenient validation
+func TestAnalyzePlayer_ValidatesMatches(t *testing.T) {
+	summoner := &models.Summoner{PUUID: "test-puuid"}
+
+	result := NewAnalysisService().AnalyzePlayer(summoner, invalidMatches())
+
+	if result.PlayerStats.TotalMatches != 2 {
+		t.Errorf("Expected the 2 valid matches to be analyzed, got %d", result.PlayerStats.TotalMatches)
+	}
+
+	if len(result.Warnings) != 4 || result.Warnings[0].Pointer != "/matches/1" {
+		t.Errorf("Expected 4 warnings starting at /matches/1, got %+v", result.Warnings)
+	}
+}
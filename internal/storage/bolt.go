@@ -0,0 +1,369 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Top-level buckets, each holding one nested bucket per PUUID
+var (
+	analysesBucket = []byte("analyses")
+	savedAtBucket  = []byte("analysisSavedAt")
+	matchesBucket  = []byte("matches")
+	matchIDsBucket = []byte("matchIds")
+)
+
+// BoltStore is an AnalysisStore and MatchStore backed by an embedded bbolt database file
+// The analysis history is bounded per player and expires after the retention period, as in a MemoryStore
+type BoltStore struct {
+	historyLimits
+
+	database *bolt.DB
+	// Last sweep of expired analyses, only accessed inside write transactions
+	lastSweep time.Time
+	// Clock, replaced in tests
+	now func() time.Time
+}
+
+// NewBoltStore opens (or creates) the bbolt database at path
+func NewBoltStore(path string, options ...HistoryOption) (*BoltStore, error) {
+	database, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analysis database %s: %w", path, err)
+	}
+
+	err = database.Update(func(transaction *bolt.Tx) error {
+		for _, bucket := range [][]byte{analysesBucket, savedAtBucket, matchesBucket, matchIDsBucket} {
+			if _, err := transaction.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to initialize analysis database %s: %w", path, err)
+	}
+
+	return &BoltStore{
+		historyLimits: newHistoryLimits(options),
+		database:      database,
+		now:           time.Now,
+	}, nil
+}
+
+// Save stores an analysis result for a player and returns the stored record
+// Analyses past the retention period and the oldest analyses beyond the cap are deleted in the same transaction
+func (boltStore *BoltStore) Save(puuid string, result *models.AnalysisResult) (*models.StoredAnalysis, error) {
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode analysis: %w", err)
+	}
+
+	var key []byte
+	err = boltStore.database.Update(func(transaction *bolt.Tx) error {
+		now := boltStore.now()
+
+		playerBucket, err := transaction.Bucket(analysesBucket).CreateBucketIfNotExists([]byte(puuid))
+		if err != nil {
+			return err
+		}
+		playerSavedAt, err := transaction.Bucket(savedAtBucket).CreateBucketIfNotExists([]byte(puuid))
+		if err != nil {
+			return err
+		}
+
+		sequence, err := playerBucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		key = recordKey(analysisTime(result), sequence)
+		if err := playerBucket.Put(key, encoded); err != nil {
+			return err
+		}
+		if err := playerSavedAt.Put(key, encodeTime(now)); err != nil {
+			return err
+		}
+
+		if err := boltStore.pruneHistory(playerBucket, playerSavedAt, now); err != nil {
+			return err
+		}
+		return boltStore.sweepExpired(transaction, now)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save analysis: %w", err)
+	}
+
+	return newStoredAnalysis(puuid, key, result), nil
+}
+
+// Get returns a single stored analysis of a player
+func (boltStore *BoltStore) Get(puuid string, id string) (*models.StoredAnalysis, error) {
+	key, err := decodeID(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	var storedAnalysis *models.StoredAnalysis
+	err = boltStore.database.View(func(transaction *bolt.Tx) error {
+		playerBucket := transaction.Bucket(analysesBucket).Bucket([]byte(puuid))
+		if playerBucket == nil {
+			return ErrNotFound
+		}
+
+		encoded := playerBucket.Get(key)
+		if encoded == nil {
+			return ErrNotFound
+		}
+
+		playerSavedAt := transaction.Bucket(savedAtBucket).Bucket([]byte(puuid))
+		if boltStore.expired(savedAt(playerSavedAt, key), boltStore.now()) {
+			return ErrNotFound
+		}
+
+		storedAnalysis, err = decodeStoredAnalysis(puuid, key, encoded)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return storedAnalysis, nil
+}
+
+// List returns a page of a player's stored analyses, newest first
+func (boltStore *BoltStore) List(puuid string, query ListQuery) (*models.AnalysisHistory, error) {
+	lower, upper, err := query.bounds()
+	if err != nil {
+		return nil, err
+	}
+
+	history := &models.AnalysisHistory{PUUID: puuid, Analyses: []models.StoredAnalysis{}}
+
+	err = boltStore.database.View(func(transaction *bolt.Tx) error {
+		playerBucket := transaction.Bucket(analysesBucket).Bucket([]byte(puuid))
+		if playerBucket == nil {
+			return nil
+		}
+
+		playerSavedAt := transaction.Bucket(savedAtBucket).Bucket([]byte(puuid))
+		now := boltStore.now()
+		cursor := playerBucket.Cursor()
+
+		// Position on the newest key below the upper bound
+		var key, encoded []byte
+		if upper == nil {
+			key, encoded = cursor.Last()
+		} else if key, _ = cursor.Seek(upper); key == nil {
+			key, encoded = cursor.Last()
+		} else {
+			key, encoded = cursor.Prev()
+		}
+
+		for ; key != nil && bytes.Compare(key, lower) >= 0; key, encoded = cursor.Prev() {
+			if boltStore.expired(savedAt(playerSavedAt, key), now) {
+				continue
+			}
+
+			if len(history.Analyses) == query.pageSize() {
+				history.NextCursor = history.Analyses[len(history.Analyses)-1].ID
+				break
+			}
+
+			storedAnalysis, err := decodeStoredAnalysis(puuid, key, encoded)
+			if err != nil {
+				return err
+			}
+			history.Analyses = append(history.Analyses, *storedAnalysis)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// pruneHistory deletes a player's analyses past the retention period and the oldest ones beyond the cap
+// Keys are ordered by analysis time, so the cap drops the oldest analyses first
+func (boltStore *BoltStore) pruneHistory(playerBucket *bolt.Bucket, playerSavedAt *bolt.Bucket, now time.Time) error {
+	var keys [][]byte
+	cursor := playerBucket.Cursor()
+	for key, _ := cursor.First(); key != nil; key, _ = cursor.Next() {
+		keys = append(keys, append([]byte(nil), key...))
+	}
+
+	excess := len(keys) - boltStore.maxAnalysesPerPlayer
+	for index, key := range keys {
+		if index >= excess && !boltStore.expired(savedAt(playerSavedAt, key), now) {
+			continue
+		}
+
+		if err := playerBucket.Delete(key); err != nil {
+			return err
+		}
+		if playerSavedAt != nil {
+			if err := playerSavedAt.Delete(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sweepExpired removes expired analyses of every player, at most once per sweep interval
+// It must run inside a write transaction
+func (boltStore *BoltStore) sweepExpired(transaction *bolt.Tx, now time.Time) error {
+	if now.Sub(boltStore.lastSweep) < expirySweepInterval {
+		return nil
+	}
+	boltStore.lastSweep = now
+
+	// Player buckets are collected first as buckets cannot be changed while iterating over them
+	analyses := transaction.Bucket(analysesBucket)
+	var puuids [][]byte
+	err := analyses.ForEach(func(puuid []byte, _ []byte) error {
+		puuids = append(puuids, append([]byte(nil), puuid...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, puuid := range puuids {
+		if err := boltStore.pruneHistory(analyses.Bucket(puuid), transaction.Bucket(savedAtBucket).Bucket(puuid), now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AppendMatches adds matches to a player's history and returns the ones that were not already stored
+func (boltStore *BoltStore) AppendMatches(puuid string, matches []models.Match) ([]models.Match, error) {
+	if err := checkGameCreation(matches); err != nil {
+		return nil, err
+	}
+
+	var added []models.Match
+
+	err := boltStore.database.Update(func(transaction *bolt.Tx) error {
+		playerMatches, err := transaction.Bucket(matchesBucket).CreateBucketIfNotExists([]byte(puuid))
+		if err != nil {
+			return err
+		}
+		playerMatchIDs, err := transaction.Bucket(matchIDsBucket).CreateBucketIfNotExists([]byte(puuid))
+		if err != nil {
+			return err
+		}
+
+		for matchIndex := range matches {
+			match := &matches[matchIndex]
+			if playerMatchIDs.Get([]byte(match.MatchID)) != nil {
+				continue
+			}
+
+			encoded, err := json.Marshal(match)
+			if err != nil {
+				return fmt.Errorf("failed to encode match %s: %w", match.MatchID, err)
+			}
+
+			key := matchKey(match)
+			if err := playerMatches.Put(key, encoded); err != nil {
+				return err
+			}
+			if err := playerMatchIDs.Put([]byte(match.MatchID), key); err != nil {
+				return err
+			}
+
+			added = append(added, *match)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to append matches: %w", err)
+	}
+
+	return added, nil
+}
+
+// RecentMatches returns a player's most recent matches by game creation, oldest first
+func (boltStore *BoltStore) RecentMatches(puuid string, limit int) ([]models.Match, error) {
+	var matches []models.Match
+
+	err := boltStore.database.View(func(transaction *bolt.Tx) error {
+		playerMatches := transaction.Bucket(matchesBucket).Bucket([]byte(puuid))
+		if playerMatches == nil {
+			return nil
+		}
+
+		cursor := playerMatches.Cursor()
+		for key, encoded := cursor.Last(); key != nil && (limit <= 0 || len(matches) < limit); key, encoded = cursor.Prev() {
+			var match models.Match
+			if err := json.Unmarshal(encoded, &match); err != nil {
+				return fmt.Errorf("failed to decode stored match: %w", err)
+			}
+			matches = append(matches, match)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Collected newest first; return oldest first
+	for left, right := 0, len(matches)-1; left < right; left, right = left+1, right-1 {
+		matches[left], matches[right] = matches[right], matches[left]
+	}
+
+	if matches == nil {
+		matches = []models.Match{}
+	}
+	return matches, nil
+}
+
+// Close closes the database file
+func (boltStore *BoltStore) Close() error {
+	return boltStore.database.Close()
+}
+
+// savedAt returns when an analysis was saved
+// Analyses saved before save times were recorded fall back to their analysis time
+func savedAt(playerSavedAt *bolt.Bucket, key []byte) time.Time {
+	if playerSavedAt != nil {
+		if encoded := playerSavedAt.Get(key); len(encoded) == 8 {
+			return time.Unix(0, int64(binary.BigEndian.Uint64(encoded)))
+		}
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[:8])))
+}
+
+// encodeTime encodes a save time as Unix nanoseconds
+func encodeTime(moment time.Time) []byte {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, uint64(moment.UnixNano()))
+	return encoded
+}
+
+// decodeStoredAnalysis decodes a stored record
+// The key is copied because bbolt keys are only valid for the life of the transaction
+func decodeStoredAnalysis(puuid string, key []byte, encoded []byte) (*models.StoredAnalysis, error) {
+	var result models.AnalysisResult
+	if err := json.Unmarshal(encoded, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode stored analysis: %w", err)
+	}
+
+	return newStoredAnalysis(puuid, append([]byte(nil), key...), &result), nil
+}
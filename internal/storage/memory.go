@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// memoryRecord is a stored analysis held in memory
+type memoryRecord struct {
+	key      []byte
+	result   *models.AnalysisResult
+	storedAt time.Time
+}
+
+// memoryMatch is a stored match held in memory
+type memoryMatch struct {
+	key   []byte
+	match models.Match
+}
+
+// MemoryStore is an AnalysisStore and MatchStore that keeps everything in memory; history is lost on restart
+// The analysis history is bounded per player and expires after the retention period
+type MemoryStore struct {
+	historyLimits
+
+	mutex     sync.RWMutex
+	sequence  uint64
+	lastSweep time.Time
+	// Records per PUUID, ordered by key
+	records map[string][]memoryRecord
+	// Matches per PUUID, ordered by key
+	matches map[string][]memoryMatch
+	// Stored match IDs per PUUID
+	matchIDs map[string]map[string]bool
+	// Clock, replaced in tests
+	now func() time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore(options ...HistoryOption) *MemoryStore {
+	return &MemoryStore{
+		historyLimits: newHistoryLimits(options),
+		records:       make(map[string][]memoryRecord),
+		matches:       make(map[string][]memoryMatch),
+		matchIDs:      make(map[string]map[string]bool),
+		now:           time.Now,
+	}
+}
+
+// Save stores an analysis result for a player and returns the stored record
+func (memoryStore *MemoryStore) Save(puuid string, result *models.AnalysisResult) (*models.StoredAnalysis, error) {
+	memoryStore.mutex.Lock()
+	defer memoryStore.mutex.Unlock()
+
+	now := memoryStore.now()
+	memoryStore.sweepExpired(now)
+
+	memoryStore.sequence++
+	key := recordKey(analysisTime(result), memoryStore.sequence)
+
+	records := memoryStore.records[puuid]
+	position := sort.Search(len(records), func(index int) bool {
+		return bytes.Compare(records[index].key, key) > 0
+	})
+	records = append(records, memoryRecord{})
+	copy(records[position+1:], records[position:])
+	records[position] = memoryRecord{key: key, result: result, storedAt: now}
+
+	// Records are ordered by analysis time, so the oldest analyses are dropped beyond the cap
+	if excess := len(records) - memoryStore.maxAnalysesPerPlayer; excess > 0 {
+		records = append([]memoryRecord(nil), records[excess:]...)
+	}
+	memoryStore.records[puuid] = records
+
+	return newStoredAnalysis(puuid, key, result), nil
+}
+
+// Get returns a single stored analysis of a player
+func (memoryStore *MemoryStore) Get(puuid string, id string) (*models.StoredAnalysis, error) {
+	key, err := decodeID(id)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	memoryStore.mutex.RLock()
+	defer memoryStore.mutex.RUnlock()
+
+	now := memoryStore.now()
+	for _, record := range memoryStore.records[puuid] {
+		if bytes.Equal(record.key, key) && !memoryStore.expired(record.storedAt, now) {
+			return newStoredAnalysis(puuid, record.key, record.result), nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// List returns a page of a player's stored analyses, newest first
+func (memoryStore *MemoryStore) List(puuid string, query ListQuery) (*models.AnalysisHistory, error) {
+	lower, upper, err := query.bounds()
+	if err != nil {
+		return nil, err
+	}
+
+	memoryStore.mutex.RLock()
+	defer memoryStore.mutex.RUnlock()
+
+	history := &models.AnalysisHistory{PUUID: puuid, Analyses: []models.StoredAnalysis{}}
+	records := memoryStore.records[puuid]
+	now := memoryStore.now()
+
+	for index := len(records) - 1; index >= 0; index-- {
+		record := records[index]
+		if (upper != nil && bytes.Compare(record.key, upper) >= 0) || memoryStore.expired(record.storedAt, now) {
+			continue
+		}
+		if bytes.Compare(record.key, lower) < 0 {
+			break
+		}
+
+		if len(history.Analyses) == query.pageSize() {
+			history.NextCursor = history.Analyses[len(history.Analyses)-1].ID
+			break
+		}
+		history.Analyses = append(history.Analyses, *newStoredAnalysis(puuid, record.key, record.result))
+	}
+
+	return history, nil
+}
+
+// sweepExpired removes expired analyses of every player, at most once per sweep interval
+// The caller must hold the write lock
+func (memoryStore *MemoryStore) sweepExpired(now time.Time) {
+	if now.Sub(memoryStore.lastSweep) < expirySweepInterval {
+		return
+	}
+	memoryStore.lastSweep = now
+
+	for puuid, records := range memoryStore.records {
+		kept := records[:0]
+		for _, record := range records {
+			if !memoryStore.expired(record.storedAt, now) {
+				kept = append(kept, record)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(memoryStore.records, puuid)
+			continue
+		}
+		memoryStore.records[puuid] = kept
+	}
+}
+
+// AppendMatches adds matches to a player's history and returns the ones that were not already stored
+func (memoryStore *MemoryStore) AppendMatches(puuid string, matches []models.Match) ([]models.Match, error) {
+	if err := checkGameCreation(matches); err != nil {
+		return nil, err
+	}
+
+	memoryStore.mutex.Lock()
+	defer memoryStore.mutex.Unlock()
+
+	storedIDs, exists := memoryStore.matchIDs[puuid]
+	if !exists {
+		storedIDs = make(map[string]bool)
+		memoryStore.matchIDs[puuid] = storedIDs
+	}
+
+	var added []models.Match
+	stored := memoryStore.matches[puuid]
+	for matchIndex := range matches {
+		match := &matches[matchIndex]
+		if storedIDs[match.MatchID] {
+			continue
+		}
+		storedIDs[match.MatchID] = true
+
+		key := matchKey(match)
+		position := sort.Search(len(stored), func(index int) bool {
+			return bytes.Compare(stored[index].key, key) > 0
+		})
+		stored = append(stored, memoryMatch{})
+		copy(stored[position+1:], stored[position:])
+		stored[position] = memoryMatch{key: key, match: *match}
+
+		added = append(added, *match)
+	}
+	memoryStore.matches[puuid] = stored
+
+	return added, nil
+}
+
+// RecentMatches returns a player's most recent matches by game creation, oldest first
+func (memoryStore *MemoryStore) RecentMatches(puuid string, limit int) ([]models.Match, error) {
+	memoryStore.mutex.RLock()
+	defer memoryStore.mutex.RUnlock()
+
+	stored := memoryStore.matches[puuid]
+	if limit > 0 && limit < len(stored) {
+		stored = stored[len(stored)-limit:]
+	}
+
+	matches := make([]models.Match, 0, len(stored))
+	for _, storedMatch := range stored {
+		matches = append(matches, storedMatch.match)
+	}
+	return matches, nil
+}
+
+// Close releases the resources held by the store
+func (memoryStore *MemoryStore) Close() error {
+	return nil
+}
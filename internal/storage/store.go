@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+)
+
+// Page size limits for listing stored analyses
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Default bounds of the analysis history kept per player
+const (
+	// DefaultMaxAnalysesPerPlayer is how many analyses are kept per PUUID; older ones are dropped
+	DefaultMaxAnalysesPerPlayer = 50
+	// DefaultRetention is how long an analysis is kept after it was saved
+	DefaultRetention = 24 * time.Hour
+)
+
+// expirySweepInterval is how often analyses past the retention are removed for every player
+const expirySweepInterval = time.Minute
+
+// keyLength is the size of a record key: analysis time in Unix nanoseconds followed by a sequence number
+const keyLength = 16
+
+// ErrNotFound is returned when a stored analysis does not exist
+var ErrNotFound = errors.New("analysis not found")
+
+// ErrInvalidCursor is returned when a pagination cursor or analysis ID cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// AnalysisStore persists analysis results per PUUID
+type AnalysisStore interface {
+	// Save stores an analysis result for a player and returns the stored record
+	Save(puuid string, result *models.AnalysisResult) (*models.StoredAnalysis, error)
+	// Get returns a single stored analysis of a player
+	Get(puuid string, id string) (*models.StoredAnalysis, error)
+	// List returns a page of a player's stored analyses, newest first
+	List(puuid string, query ListQuery) (*models.AnalysisHistory, error)
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// ErrMissingGameCreation is returned when a match without a game creation time is appended, as stored
+// matches are ordered by it
+var ErrMissingGameCreation = errors.New("match has no game creation time")
+
+// MatchStore keeps each player's match history, deduplicated by match ID
+type MatchStore interface {
+	// AppendMatches adds matches to a player's history and returns the ones that were not already stored
+	// Every match needs a game creation time; otherwise nothing is stored and ErrMissingGameCreation is returned
+	AppendMatches(puuid string, matches []models.Match) ([]models.Match, error)
+	// RecentMatches returns a player's most recent matches by game creation, oldest first
+	// A limit of zero or less returns the whole history
+	RecentMatches(puuid string, limit int) ([]models.Match, error)
+}
+
+// Store persists both analysis history and match history
+type Store interface {
+	AnalysisStore
+	MatchStore
+}
+
+// historyLimits bounds the analysis history a store keeps per player
+type historyLimits struct {
+	// Analyses kept per PUUID
+	maxAnalysesPerPlayer int
+	// How long analyses are kept after they were saved
+	retention time.Duration
+}
+
+// HistoryOption configures the bounds of a store's analysis history
+type HistoryOption func(limits *historyLimits)
+
+// WithMaxAnalysesPerPlayer sets how many analyses are kept per PUUID
+func WithMaxAnalysesPerPlayer(maxAnalyses int) HistoryOption {
+	return func(limits *historyLimits) {
+		if maxAnalyses > 0 {
+			limits.maxAnalysesPerPlayer = maxAnalyses
+		}
+	}
+}
+
+// WithRetention sets how long analyses are kept after they were saved
+func WithRetention(retention time.Duration) HistoryOption {
+	return func(limits *historyLimits) {
+		if retention > 0 {
+			limits.retention = retention
+		}
+	}
+}
+
+// newHistoryLimits returns the default history bounds with the options applied
+func newHistoryLimits(options []HistoryOption) historyLimits {
+	limits := historyLimits{
+		maxAnalysesPerPlayer: DefaultMaxAnalysesPerPlayer,
+		retention:            DefaultRetention,
+	}
+	for _, option := range options {
+		option(&limits)
+	}
+	return limits
+}
+
+// expired reports whether an analysis saved at savedAt is past the retention period
+func (limits historyLimits) expired(savedAt time.Time, now time.Time) bool {
+	return now.Sub(savedAt) > limits.retention
+}
+
+// ListQuery selects a page of stored analyses
+type ListQuery struct {
+	// Earliest analysis time to include (inclusive)
+	From *time.Time
+	// Latest analysis time to include (inclusive)
+	To *time.Time
+	// Maximum number of analyses to return (DefaultPageSize when zero, capped at MaxPageSize)
+	Limit int
+	// ID of the last analysis of the previous page; only older analyses are returned
+	Cursor string
+}
+
+// pageSize returns the effective page size of the query
+func (query ListQuery) pageSize() int {
+	switch {
+	case query.Limit <= 0:
+		return DefaultPageSize
+	case query.Limit > MaxPageSize:
+		return MaxPageSize
+	default:
+		return query.Limit
+	}
+}
+
+// bounds returns the key range selected by the query: keys must be >= lower and < upper
+// A nil upper bound means no upper limit
+func (query ListQuery) bounds() (lower []byte, upper []byte, err error) {
+	lower = make([]byte, keyLength)
+	if query.From != nil {
+		lower = recordKey(*query.From, 0)
+	}
+
+	if query.To != nil {
+		upper = recordKey(query.To.Add(time.Nanosecond), 0)
+	}
+
+	if query.Cursor != "" {
+		cursorKey, err := decodeID(query.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		if upper == nil || bytes.Compare(cursorKey, upper) < 0 {
+			upper = cursorKey
+		}
+	}
+
+	return lower, upper, nil
+}
+
+// recordKey builds the key of a record so that keys sort by analysis time
+func recordKey(analyzedAt time.Time, sequence uint64) []byte {
+	key := make([]byte, keyLength)
+	binary.BigEndian.PutUint64(key[:8], uint64(analyzedAt.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], sequence)
+	return key
+}
+
+// matchKey builds the key of a stored match so that matches sort by game creation
+// The match ID is appended to keep keys unique when games start at the same time
+func matchKey(match *models.Match) []byte {
+	key := make([]byte, 8, 8+len(match.MatchID))
+	binary.BigEndian.PutUint64(key, uint64(match.GameCreation.UnixNano()))
+	return append(key, match.MatchID...)
+}
+
+// checkGameCreation rejects matches that cannot be given a sort key
+func checkGameCreation(matches []models.Match) error {
+	for matchIndex := range matches {
+		if matches[matchIndex].GameCreation.IsZero() {
+			return fmt.Errorf("match %s: %w", matches[matchIndex].MatchID, ErrMissingGameCreation)
+		}
+	}
+	return nil
+}
+
+// encodeID converts a record key to the analysis ID exposed by the API
+func encodeID(key []byte) string {
+	return hex.EncodeToString(key)
+}
+
+// decodeID converts an analysis ID back to its record key
+func decodeID(id string) ([]byte, error) {
+	key, err := hex.DecodeString(id)
+	if err != nil || len(key) != keyLength {
+		return nil, ErrInvalidCursor
+	}
+	return key, nil
+}
+
+// analysisTime returns the time an analysis is stored under, defaulting to now when unset
+func analysisTime(result *models.AnalysisResult) time.Time {
+	if result.AnalyzedAt.IsZero() {
+		return time.Now()
+	}
+	return result.AnalyzedAt
+}
+
+// newStoredAnalysis builds the stored record for a key and result
+func newStoredAnalysis(puuid string, key []byte, result *models.AnalysisResult) *models.StoredAnalysis {
+	return &models.StoredAnalysis{
+		ID:         encodeID(key),
+		PUUID:      puuid,
+		AnalyzedAt: time.Unix(0, int64(binary.BigEndian.Uint64(key[:8]))).UTC(),
+		Result:     result,
+	}
+}
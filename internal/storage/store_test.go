@@ -0,0 +1,345 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// storeFactories creates each Store implementation for shared tests
+var storeFactories = map[string]func(t *testing.T) Store{
+	"memory": func(t *testing.T) Store {
+		return NewMemoryStore()
+	},
+	"bolt": func(t *testing.T) Store {
+		boltStore, err := NewBoltStore(filepath.Join(t.TempDir(), "analyses.db"))
+		if err != nil {
+			t.Fatalf("Failed to open bolt store: %v", err)
+		}
+		return boltStore
+	},
+}
+
+// analysisAt builds an analysis result performed on the given day of January 2024
+func analysisAt(day int, totalMatches int) *models.AnalysisResult {
+	return &models.AnalysisResult{
+		PlayerStats: models.PlayerStats{PUUID: "test-puuid", TotalMatches: totalMatches},
+		AnalyzedAt:  time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// TestAnalysisStore_SaveAndGet tests round-tripping a stored analysis
+func TestAnalysisStore_SaveAndGet(t *testing.T) {
+	for name, newStore := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			defer store.Close()
+
+			stored, err := store.Save("test-puuid", analysisAt(3, 20))
+			if err != nil {
+				t.Fatalf("Failed to save analysis: %v", err)
+			}
+
+			if stored.ID == "" || stored.PUUID != "test-puuid" || !stored.AnalyzedAt.Equal(analysisAt(3, 20).AnalyzedAt) {
+				t.Errorf("Unexpected stored record %+v", stored)
+			}
+
+			loaded, err := store.Get("test-puuid", stored.ID)
+			if err != nil {
+				t.Fatalf("Failed to get analysis: %v", err)
+			}
+
+			if loaded.ID != stored.ID || loaded.Result.PlayerStats.TotalMatches != 20 {
+				t.Errorf("Expected the saved analysis back, got %+v", loaded)
+			}
+
+			if _, err := store.Get("other-puuid", stored.ID); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound for another player, got %v", err)
+			}
+
+			if _, err := store.Get("test-puuid", "not-an-id"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Expected ErrNotFound for a malformed ID, got %v", err)
+			}
+		})
+	}
+}
+
+// TestMemoryStore_BoundsHistory tests the per-player cap and the retention period of the in-memory history
+func TestMemoryStore_BoundsHistory(t *testing.T) {
+	currentTime := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	memoryStore := NewMemoryStore(WithMaxAnalysesPerPlayer(2), WithRetention(time.Hour))
+	memoryStore.now = func() time.Time { return currentTime }
+
+	for day := 1; day <= 3; day++ {
+		memoryStore.Save("test-puuid", analysisAt(day, day))
+	}
+
+	history, _ := memoryStore.List("test-puuid", ListQuery{})
+	if len(history.Analyses) != 2 || history.Analyses[1].Result.PlayerStats.TotalMatches != 2 {
+		t.Fatalf("Expected the 2 most recent analyses, got %+v", history.Analyses)
+	}
+
+	currentTime = currentTime.Add(2 * time.Hour)
+	if history, _ := memoryStore.List("test-puuid", ListQuery{}); len(history.Analyses) != 0 {
+		t.Errorf("Expected expired analyses to be hidden, got %d", len(history.Analyses))
+	}
+
+	// The next save sweeps expired analyses of every player
+	memoryStore.Save("other-puuid", analysisAt(4, 4))
+	if _, exists := memoryStore.records["test-puuid"]; exists || len(memoryStore.records) != 1 {
+		t.Errorf("Expected expired players to be removed, got %d players", len(memoryStore.records))
+	}
+}
+
+// TestBoltStore_BoundsHistory tests that the bbolt history deletes analyses beyond the cap and past the retention
+func TestBoltStore_BoundsHistory(t *testing.T) {
+	currentTime := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	boltStore, err := NewBoltStore(filepath.Join(t.TempDir(), "analyses.db"), WithMaxAnalysesPerPlayer(2), WithRetention(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	defer boltStore.Close()
+	boltStore.now = func() time.Time { return currentTime }
+
+	for day := 1; day <= 3; day++ {
+		boltStore.Save("test-puuid", analysisAt(day, day))
+	}
+
+	if stored := storedAnalysisCount(t, boltStore, "test-puuid"); stored != 2 {
+		t.Errorf("Expected the oldest analysis to be deleted beyond the cap, got %d stored", stored)
+	}
+
+	history, _ := boltStore.List("test-puuid", ListQuery{})
+	if len(history.Analyses) != 2 || history.Analyses[1].Result.PlayerStats.TotalMatches != 2 {
+		t.Fatalf("Expected the 2 most recent analyses, got %+v", history.Analyses)
+	}
+
+	currentTime = currentTime.Add(2 * time.Hour)
+	if history, _ := boltStore.List("test-puuid", ListQuery{}); len(history.Analyses) != 0 {
+		t.Errorf("Expected expired analyses to be hidden, got %d", len(history.Analyses))
+	}
+	if _, err := boltStore.Get("test-puuid", history.Analyses[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an expired analysis, got %v", err)
+	}
+
+	// The next save sweeps expired analyses of every player
+	boltStore.Save("other-puuid", analysisAt(4, 4))
+	if stored := storedAnalysisCount(t, boltStore, "test-puuid"); stored != 0 {
+		t.Errorf("Expected expired analyses to be deleted, got %d stored", stored)
+	}
+}
+
+// storedAnalysisCount counts the analyses stored in a bolt store for a player, including expired ones
+func storedAnalysisCount(t *testing.T, boltStore *BoltStore, puuid string) int {
+	t.Helper()
+
+	var count int
+	err := boltStore.database.View(func(transaction *bolt.Tx) error {
+		if playerBucket := transaction.Bucket(analysesBucket).Bucket([]byte(puuid)); playerBucket != nil {
+			count = playerBucket.Stats().KeyN
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to count stored analyses: %v", err)
+	}
+	return count
+}
+
+// TestAnalysisStore_List tests ordering, time-range filtering and cursor pagination
+func TestAnalysisStore_List(t *testing.T) {
+	for name, newStore := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			defer store.Close()
+
+			// Saved out of order; listing is newest first regardless
+			for _, day := range []int{2, 5, 1, 4, 3} {
+				if _, err := store.Save("test-puuid", analysisAt(day, day)); err != nil {
+					t.Fatalf("Failed to save analysis: %v", err)
+				}
+			}
+			store.Save("other-puuid", analysisAt(6, 6))
+
+			firstPage, err := store.List("test-puuid", ListQuery{Limit: 2})
+			if err != nil {
+				t.Fatalf("Failed to list analyses: %v", err)
+			}
+			assertDays(t, firstPage, 5, 4)
+
+			if firstPage.NextCursor == "" {
+				t.Fatal("Expected a cursor for the next page")
+			}
+
+			secondPage, _ := store.List("test-puuid", ListQuery{Limit: 2, Cursor: firstPage.NextCursor})
+			assertDays(t, secondPage, 3, 2)
+
+			lastPage, _ := store.List("test-puuid", ListQuery{Limit: 2, Cursor: secondPage.NextCursor})
+			assertDays(t, lastPage, 1)
+			if lastPage.NextCursor != "" {
+				t.Errorf("Expected no cursor on the last page, got '%s'", lastPage.NextCursor)
+			}
+
+			// The range is inclusive on both ends
+			from := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+			to := time.Date(2024, 1, 4, 12, 0, 0, 0, time.UTC)
+			rangePage, _ := store.List("test-puuid", ListQuery{From: &from, To: &to})
+			assertDays(t, rangePage, 4, 3, 2)
+
+			emptyPage, _ := store.List("unknown-puuid", ListQuery{})
+			if emptyPage.Analyses == nil || len(emptyPage.Analyses) != 0 {
+				t.Errorf("Expected an empty, non-nil page for an unknown player, got %+v", emptyPage.Analyses)
+			}
+
+			if _, err := store.List("test-puuid", ListQuery{Cursor: "zz"}); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+// TestBoltStore_Persists tests that analyses survive reopening the database
+func TestBoltStore_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analyses.db")
+
+	boltStore, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	stored, _ := boltStore.Save("test-puuid", analysisAt(1, 10))
+	boltStore.Close()
+
+	reopened, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen bolt store: %v", err)
+	}
+	defer reopened.Close()
+
+	if loaded, err := reopened.Get("test-puuid", stored.ID); err != nil || loaded.Result.PlayerStats.TotalMatches != 10 {
+		t.Errorf("Expected the analysis to persist, got %+v (%v)", loaded, err)
+	}
+}
+
+// assertDays checks that a page holds the analyses of the given days in order
+func assertDays(t *testing.T, history *models.AnalysisHistory, days ...int) {
+	t.Helper()
+
+	if len(history.Analyses) != len(days) {
+		t.Fatalf("Expected %d analyses, got %d", len(days), len(history.Analyses))
+	}
+
+	for index, day := range days {
+		if history.Analyses[index].Result.PlayerStats.TotalMatches != day {
+			t.Errorf("Expected analysis %d to be from day %d, got day %d", index, day, history.Analyses[index].Result.PlayerStats.TotalMatches)
+		}
+	}
+}
+
+// matchAt builds a match created on the given day of January 2024
+func matchAt(matchID string, day int) models.Match {
+	return models.Match{
+		MatchID:      matchID,
+		GameCreation: time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC),
+		GameDuration: 1800,
+	}
+}
+
+// matchIDs returns the IDs of matches in order
+func matchIDs(matches []models.Match) []string {
+	ids := make([]string, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.MatchID)
+	}
+	return ids
+}
+
+// TestMatchStore_AppendAndRecent tests deduplication by match ID and ordering by game creation
+func TestMatchStore_AppendAndRecent(t *testing.T) {
+	for name, newStore := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			defer store.Close()
+
+			added, err := store.AppendMatches("test-puuid", []models.Match{matchAt("EUW1_3", 3), matchAt("EUW1_1", 1)})
+			if err != nil {
+				t.Fatalf("Failed to append matches: %v", err)
+			}
+			if len(added) != 2 {
+				t.Fatalf("Expected 2 new matches, got %d", len(added))
+			}
+
+			// EUW1_3 is already stored; only EUW1_2 is new
+			added, err = store.AppendMatches("test-puuid", []models.Match{matchAt("EUW1_3", 3), matchAt("EUW1_2", 2)})
+			if err != nil {
+				t.Fatalf("Failed to append matches: %v", err)
+			}
+			if got := matchIDs(added); len(got) != 1 || got[0] != "EUW1_2" {
+				t.Errorf("Expected only EUW1_2 to be added, got %v", got)
+			}
+
+			all, err := store.RecentMatches("test-puuid", 0)
+			if err != nil {
+				t.Fatalf("Failed to load matches: %v", err)
+			}
+			if got := matchIDs(all); len(got) != 3 || got[0] != "EUW1_1" || got[2] != "EUW1_3" {
+				t.Errorf("Expected all matches oldest first, got %v", got)
+			}
+
+			recent, _ := store.RecentMatches("test-puuid", 2)
+			if got := matchIDs(recent); len(got) != 2 || got[0] != "EUW1_2" || got[1] != "EUW1_3" {
+				t.Errorf("Expected the 2 most recent matches oldest first, got %v", got)
+			}
+
+			other, _ := store.RecentMatches("other-puuid", 0)
+			if len(other) != 0 {
+				t.Errorf("Expected no matches for another player, got %v", matchIDs(other))
+			}
+		})
+	}
+}
+
+// TestMatchStore_RejectsMissingGameCreation tests that matches without a sort key are not stored
+func TestMatchStore_RejectsMissingGameCreation(t *testing.T) {
+	for name, newStore := range storeFactories {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			defer store.Close()
+
+			_, err := store.AppendMatches("test-puuid", []models.Match{matchAt("EUW1_1", 1), {MatchID: "EUW1_2"}})
+			if !errors.Is(err, ErrMissingGameCreation) {
+				t.Errorf("Expected ErrMissingGameCreation, got %v", err)
+			}
+
+			if stored, _ := store.RecentMatches("test-puuid", 0); len(stored) != 0 {
+				t.Errorf("Expected nothing to be stored, got %v", matchIDs(stored))
+			}
+		})
+	}
+}
+
+// TestBoltStore_MatchesSurviveReopen tests that stored matches are kept across restarts
+func TestBoltStore_MatchesSurviveReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "analyses.db")
+
+	boltStore, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	boltStore.AppendMatches("test-puuid", []models.Match{matchAt("EUW1_1", 1)})
+	boltStore.Close()
+
+	boltStore, err = NewBoltStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen bolt store: %v", err)
+	}
+	defer boltStore.Close()
+
+	added, _ := boltStore.AppendMatches("test-puuid", []models.Match{matchAt("EUW1_1", 1)})
+	if len(added) != 0 {
+		t.Errorf("Expected the reopened store to recognize the stored match, got %v", matchIDs(added))
+	}
+}
@@ -0,0 +1,223 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// StdoutExporter writes each span as a line of JSON, for reading traces during development
+type StdoutExporter struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+// stdoutSpan is the JSON layout written by StdoutExporter
+type stdoutSpan struct {
+	Name          string                 `json:"name"`
+	Kind          string                 `json:"kind"`
+	TraceID       string                 `json:"traceId"`
+	SpanID        string                 `json:"spanId"`
+	ParentSpanID  string                 `json:"parentSpanId,omitempty"`
+	Start         string                 `json:"start"`
+	DurationMs    float64                `json:"durationMs"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Status        string                 `json:"status,omitempty"`
+	StatusMessage string                 `json:"statusMessage,omitempty"`
+}
+
+// NewStdoutExporter creates a StdoutExporter writing to writer (os.Stdout when nil)
+func NewStdoutExporter(writer io.Writer) *StdoutExporter {
+	if writer == nil {
+		writer = os.Stdout
+	}
+	return &StdoutExporter{encoder: json.NewEncoder(writer)}
+}
+
+// ExportSpan writes a single span
+func (stdoutExporter *StdoutExporter) ExportSpan(spanData *SpanData) error {
+	line := stdoutSpan{
+		Name:          spanData.Name,
+		Kind:          spanData.Kind.String(),
+		TraceID:       spanData.SpanContext.TraceID.String(),
+		SpanID:        spanData.SpanContext.SpanID.String(),
+		Start:         spanData.StartTime.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		DurationMs:    float64(spanData.EndTime.Sub(spanData.StartTime).Microseconds()) / 1000.0,
+		Attributes:    spanData.Attributes,
+		StatusMessage: spanData.StatusMessage,
+	}
+	if spanData.ParentSpanID.IsValid() {
+		line.ParentSpanID = spanData.ParentSpanID.String()
+	}
+	if spanData.StatusCode == StatusError {
+		line.Status = "error"
+	}
+
+	stdoutExporter.mutex.Lock()
+	defer stdoutExporter.mutex.Unlock()
+	return stdoutExporter.encoder.Encode(line)
+}
+
+// Shutdown does nothing; every span is written as it ends
+func (stdoutExporter *StdoutExporter) Shutdown() error {
+	return nil
+}
+
+// OTLPFileExporter appends spans to a file in the OTLP/JSON format, one ExportTraceServiceRequest per line
+// This is the layout read by the OpenTelemetry Collector's otlpjsonfile receiver
+type OTLPFileExporter struct {
+	serviceName string
+
+	mutex   sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewOTLPFileExporter opens (or creates) path for appending spans of serviceName
+func NewOTLPFileExporter(path string, serviceName string) (*OTLPFileExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file %s: %w", path, err)
+	}
+
+	return &OTLPFileExporter{
+		serviceName: serviceName,
+		file:        file,
+		encoder:     json.NewEncoder(file),
+	}, nil
+}
+
+// ExportSpan appends a single span
+func (otlpFileExporter *OTLPFileExporter) ExportSpan(spanData *SpanData) error {
+	request := otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{{Key: "service.name", Value: otlpValue{StringValue: &otlpFileExporter.serviceName}}},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: instrumentationScope},
+				Spans: []otlpSpan{newOTLPSpan(spanData)},
+			}},
+		}},
+	}
+
+	otlpFileExporter.mutex.Lock()
+	defer otlpFileExporter.mutex.Unlock()
+	return otlpFileExporter.encoder.Encode(request)
+}
+
+// Shutdown closes the trace file
+func (otlpFileExporter *OTLPFileExporter) Shutdown() error {
+	otlpFileExporter.mutex.Lock()
+	defer otlpFileExporter.mutex.Unlock()
+	return otlpFileExporter.file.Close()
+}
+
+// instrumentationScope names the instrumentation in exported OTLP data
+const instrumentationScope = "github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
+
+// OTLP/JSON message layout (opentelemetry-proto, JSON encoding with hex trace and span IDs)
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+// otlpValue is an AnyValue; exactly one field is set. 64-bit integers are strings in OTLP/JSON
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// newOTLPSpan converts span data to its OTLP/JSON layout, with attributes sorted by key
+func newOTLPSpan(spanData *SpanData) otlpSpan {
+	span := otlpSpan{
+		TraceID:           spanData.SpanContext.TraceID.String(),
+		SpanID:            spanData.SpanContext.SpanID.String(),
+		Name:              spanData.Name,
+		Kind:              int(spanData.Kind),
+		StartTimeUnixNano: strconv.FormatInt(spanData.StartTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(spanData.EndTime.UnixNano(), 10),
+		Status:            otlpStatus{Code: int(spanData.StatusCode), Message: spanData.StatusMessage},
+	}
+	if spanData.ParentSpanID.IsValid() {
+		span.ParentSpanID = spanData.ParentSpanID.String()
+	}
+
+	keys := make([]string, 0, len(spanData.Attributes))
+	for key := range spanData.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		span.Attributes = append(span.Attributes, otlpKeyValue{Key: key, Value: newOTLPValue(spanData.Attributes[key])})
+	}
+
+	return span
+}
+
+// newOTLPValue converts an attribute value to an AnyValue, formatting unsupported types as strings
+func newOTLPValue(value interface{}) otlpValue {
+	switch typedValue := value.(type) {
+	case string:
+		return otlpValue{StringValue: &typedValue}
+	case bool:
+		return otlpValue{BoolValue: &typedValue}
+	case int:
+		formatted := strconv.Itoa(typedValue)
+		return otlpValue{IntValue: &formatted}
+	case int64:
+		formatted := strconv.FormatInt(typedValue, 10)
+		return otlpValue{IntValue: &formatted}
+	case float64:
+		return otlpValue{DoubleValue: &typedValue}
+	default:
+		formatted := fmt.Sprint(typedValue)
+		return otlpValue{StringValue: &formatted}
+	}
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStdoutExporter tests the JSON line written for a span
+func TestStdoutExporter(t *testing.T) {
+	var output bytes.Buffer
+	tracer := NewTracer(NewStdoutExporter(&output))
+
+	ctx, rootSpan := tracer.Start(context.Background(), "GET /health", SpanKindServer)
+	_, childSpan := Start(ctx, "decodeBody")
+	childSpan.End()
+	rootSpan.End()
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), output.String())
+	}
+
+	var child map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &child); err != nil {
+		t.Fatalf("Failed to decode span line: %v", err)
+	}
+
+	if child["name"] != "decodeBody" || child["kind"] != "internal" || child["parentSpanId"] != rootSpan.SpanContext().SpanID.String() {
+		t.Errorf("Unexpected span line %s", lines[0])
+	}
+}
+
+// TestOTLPFileExporter tests that spans are appended as OTLP/JSON export requests
+func TestOTLPFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	exporter, err := NewOTLPFileExporter(path, "opgl-cortex-engine")
+	if err != nil {
+		t.Fatalf("Failed to open exporter: %v", err)
+	}
+	tracer := NewTracer(exporter)
+
+	_, span := tracer.Start(context.Background(), "POST /api/v1/analyze", SpanKindServer)
+	span.SetAttribute("http.status_code", 200)
+	span.SetAttribute("http.method", "POST")
+	span.SetAttribute("cached", false)
+	span.End()
+
+	if err := tracer.Shutdown(); err != nil {
+		t.Fatalf("Failed to shut down exporter: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read trace file: %v", err)
+	}
+
+	var request otlpRequest
+	if err := json.Unmarshal(content, &request); err != nil {
+		t.Fatalf("Failed to decode OTLP line: %v", err)
+	}
+
+	resourceSpans := request.ResourceSpans[0]
+	if *resourceSpans.Resource.Attributes[0].Value.StringValue != "opgl-cortex-engine" {
+		t.Errorf("Expected the service name resource attribute, got %+v", resourceSpans.Resource)
+	}
+
+	exported := resourceSpans.ScopeSpans[0].Spans[0]
+	if exported.Name != "POST /api/v1/analyze" || exported.Kind != int(SpanKindServer) || exported.TraceID != span.SpanContext().TraceID.String() {
+		t.Errorf("Unexpected exported span %+v", exported)
+	}
+
+	// Attributes are sorted by key and integers are encoded as strings
+	if len(exported.Attributes) != 3 || exported.Attributes[0].Key != "cached" || *exported.Attributes[2].Value.IntValue != "200" {
+		t.Errorf("Unexpected attributes %+v", exported.Attributes)
+	}
+}
@@ -0,0 +1,147 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SpanKind describes the relationship of a span to its caller and callees
+type SpanKind int
+
+// Span kinds, numbered as in OTLP
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+)
+
+// String returns the lowercase name of the span kind
+func (spanKind SpanKind) String() string {
+	switch spanKind {
+	case SpanKindServer:
+		return "server"
+	default:
+		return "internal"
+	}
+}
+
+// StatusCode is the outcome of a span, numbered as in OTLP
+type StatusCode int
+
+// Span status codes
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// SpanData is the immutable record of an ended span handed to exporters
+type SpanData struct {
+	Name         string
+	Kind         SpanKind
+	SpanContext  SpanContext
+	ParentSpanID SpanID
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   map[string]interface{}
+	StatusCode   StatusCode
+	// Description of the error when StatusCode is StatusError
+	StatusMessage string
+}
+
+// Span is a timed operation within a trace
+// A nil *Span is a valid no-op span, returned when the request is not traced
+type Span struct {
+	tracer *Tracer
+
+	mutex sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the propagated part of the span
+func (span *Span) SpanContext() SpanContext {
+	if span == nil {
+		return SpanContext{}
+	}
+	return span.data.SpanContext
+}
+
+// SetAttribute records a key/value pair on the span; values should be strings, integers, floats or booleans
+func (span *Span) SetAttribute(key string, value interface{}) {
+	if span == nil {
+		return
+	}
+
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+	if !span.ended {
+		span.data.Attributes[key] = value
+	}
+}
+
+// SetError marks the span as failed with the error's message; a nil error is ignored
+func (span *Span) SetError(err error) {
+	if span == nil || err == nil {
+		return
+	}
+
+	span.mutex.Lock()
+	defer span.mutex.Unlock()
+	if !span.ended {
+		span.data.StatusCode = StatusError
+		span.data.StatusMessage = err.Error()
+	}
+}
+
+// End records the end time and exports the span when its trace is sampled
+// Calls after the first are ignored
+func (span *Span) End() {
+	if span == nil {
+		return
+	}
+
+	span.mutex.Lock()
+	if span.ended {
+		span.mutex.Unlock()
+		return
+	}
+	span.ended = true
+	span.data.EndTime = time.Now()
+	spanData := span.data
+	span.mutex.Unlock()
+
+	if spanData.SpanContext.Sampled {
+		span.tracer.export(&spanData)
+	}
+}
+
+// contextKey is the type of the keys this package stores in a context
+type contextKey int
+
+// Context keys for the active span and a remote parent
+const (
+	spanKey contextKey = iota
+	remoteParentKey
+)
+
+// SpanFromContext returns the active span of ctx, or nil when the request is not traced
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+// ContextWithRemoteParent returns a copy of ctx whose next span continues the caller's trace
+func ContextWithRemoteParent(ctx context.Context, parent SpanContext) context.Context {
+	return context.WithValue(ctx, remoteParentKey, parent)
+}
+
+// Start begins an internal span as a child of the active span of ctx
+// Without an active span nothing is recorded and a nil span is returned
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name, SpanKindInternal)
+}
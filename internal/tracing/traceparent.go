@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TraceparentHeader is the W3C Trace Context header carrying the caller's trace and span IDs
+const TraceparentHeader = "traceparent"
+
+// sampledFlag is the trace-flags bit set when the caller records the trace
+const sampledFlag = 0x01
+
+// TraceID identifies a trace across services
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// String returns the trace ID as lowercase hex
+func (traceID TraceID) String() string {
+	return hex.EncodeToString(traceID[:])
+}
+
+// IsValid reports whether the trace ID is not all zeros
+func (traceID TraceID) IsValid() bool {
+	return traceID != TraceID{}
+}
+
+// String returns the span ID as lowercase hex
+func (spanID SpanID) String() string {
+	return hex.EncodeToString(spanID[:])
+}
+
+// IsValid reports whether the span ID is not all zeros
+func (spanID SpanID) IsValid() bool {
+	return spanID != SpanID{}
+}
+
+// SpanContext is the part of a span that is propagated between services
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Whether the trace is recorded; unsampled spans are propagated but not exported
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set
+func (spanContext SpanContext) IsValid() bool {
+	return spanContext.TraceID.IsValid() && spanContext.SpanID.IsValid()
+}
+
+// Traceparent formats the span context as a version 00 traceparent header value
+func (spanContext SpanContext) Traceparent() string {
+	flags := 0
+	if spanContext.Sampled {
+		flags = sampledFlag
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", spanContext.TraceID, spanContext.SpanID, flags)
+}
+
+// ParseTraceparent parses a traceparent header value
+// Returns false for malformed values, unknown version 00 layouts and all-zero IDs, which callers must ignore
+func ParseTraceparent(value string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, false
+	}
+	// Version 00 has exactly four fields; later versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}, false
+	}
+
+	var spanContext SpanContext
+	var flags [1]byte
+	if !decodeLowerHex(parts[0], make([]byte, 1)) ||
+		!decodeLowerHex(parts[1], spanContext.TraceID[:]) ||
+		!decodeLowerHex(parts[2], spanContext.SpanID[:]) ||
+		!decodeLowerHex(parts[3], flags[:]) {
+		return SpanContext{}, false
+	}
+
+	if !spanContext.IsValid() {
+		return SpanContext{}, false
+	}
+
+	spanContext.Sampled = flags[0]&sampledFlag != 0
+	return spanContext, true
+}
+
+// decodeLowerHex decodes lowercase hex of exactly len(target) bytes into target
+func decodeLowerHex(value string, target []byte) bool {
+	if len(value) != hex.EncodedLen(len(target)) || strings.ToLower(value) != value {
+		return false
+	}
+	_, err := hex.Decode(target, []byte(value))
+	return err == nil
+}
+
+// newTraceID generates a random trace ID
+func newTraceID() TraceID {
+	var traceID TraceID
+	for !traceID.IsValid() {
+		rand.Read(traceID[:])
+	}
+	return traceID
+}
+
+// newSpanID generates a random span ID
+func newSpanID() SpanID {
+	var spanID SpanID
+	for !spanID.IsValid() {
+		rand.Read(spanID[:])
+	}
+	return spanID
+}
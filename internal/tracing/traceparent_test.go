@@ -0,0 +1,45 @@
+package tracing
+
+import "testing"
+
+// TestParseTraceparent tests parsing valid and invalid traceparent values
+func TestParseTraceparent(t *testing.T) {
+	spanContext, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if !ok {
+		t.Fatal("Expected a valid traceparent to parse")
+	}
+	if spanContext.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || spanContext.SpanID.String() != "00f067aa0ba902b7" || !spanContext.Sampled {
+		t.Errorf("Unexpected span context %+v", spanContext)
+	}
+
+	if spanContext.Traceparent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("Expected the traceparent to round-trip, got %s", spanContext.Traceparent())
+	}
+
+	unsampled, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	if !ok || unsampled.Sampled {
+		t.Errorf("Expected an unsampled span context, got %+v", unsampled)
+	}
+
+	// Future versions may append fields
+	if _, ok := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"); !ok {
+		t.Error("Expected a future version with extra fields to parse")
+	}
+
+	invalidValues := []string{
+		"",
+		"garbage",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+	}
+	for _, value := range invalidValues {
+		if _, ok := ParseTraceparent(value); ok {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
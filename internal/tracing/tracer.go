@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Exporter receives ended, sampled spans
+type Exporter interface {
+	// ExportSpan writes a single span
+	ExportSpan(spanData *SpanData) error
+	// Shutdown flushes and releases the exporter
+	Shutdown() error
+}
+
+// Tracer creates spans and hands them to an exporter when they end
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer creates a Tracer exporting to exporter
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+// Start begins a span as a child of the active span of ctx, or of a remote parent set with
+// ContextWithRemoteParent, or as the root of a new sampled trace
+func (tracer *Tracer) Start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	spanContext := SpanContext{SpanID: newSpanID()}
+	var parentSpanID SpanID
+
+	if parent := SpanFromContext(ctx); parent != nil {
+		spanContext.TraceID = parent.data.SpanContext.TraceID
+		spanContext.Sampled = parent.data.SpanContext.Sampled
+		parentSpanID = parent.data.SpanContext.SpanID
+	} else if remoteParent, ok := ctx.Value(remoteParentKey).(SpanContext); ok && remoteParent.IsValid() {
+		spanContext.TraceID = remoteParent.TraceID
+		spanContext.Sampled = remoteParent.Sampled
+		parentSpanID = remoteParent.SpanID
+	} else {
+		spanContext.TraceID = newTraceID()
+		spanContext.Sampled = true
+	}
+
+	span := &Span{
+		tracer: tracer,
+		data: SpanData{
+			Name:         name,
+			Kind:         kind,
+			SpanContext:  spanContext,
+			ParentSpanID: parentSpanID,
+			StartTime:    time.Now(),
+			Attributes:   make(map[string]interface{}),
+		},
+	}
+
+	return context.WithValue(ctx, spanKey, span), span
+}
+
+// Shutdown flushes and releases the exporter
+func (tracer *Tracer) Shutdown() error {
+	return tracer.exporter.Shutdown()
+}
+
+// export hands an ended span to the exporter; export failures are logged and never fail the request
+func (tracer *Tracer) export(spanData *SpanData) {
+	if err := tracer.exporter.ExportSpan(spanData); err != nil {
+		log.Error().Err(err).Str("span", spanData.Name).Msg("Failed to export span")
+	}
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recordingExporter keeps exported spans in memory for tests
+type recordingExporter struct {
+	mutex sync.Mutex
+	spans []*SpanData
+}
+
+func (recorder *recordingExporter) ExportSpan(spanData *SpanData) error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.spans = append(recorder.spans, spanData)
+	return nil
+}
+
+func (recorder *recordingExporter) Shutdown() error {
+	return nil
+}
+
+// TestTracer_SpanHierarchy tests that child spans share the trace and point at their parent
+func TestTracer_SpanHierarchy(t *testing.T) {
+	recorder := &recordingExporter{}
+	tracer := NewTracer(recorder)
+
+	ctx, rootSpan := tracer.Start(context.Background(), "POST /api/v1/analyze", SpanKindServer)
+	_, childSpan := Start(ctx, "calculatePlayerStats")
+	childSpan.SetAttribute("matches", 20)
+	childSpan.SetError(errors.New("boom"))
+	childSpan.End()
+	rootSpan.End()
+	rootSpan.End()
+
+	if len(recorder.spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(recorder.spans))
+	}
+
+	child, root := recorder.spans[0], recorder.spans[1]
+	if child.SpanContext.TraceID != root.SpanContext.TraceID || child.ParentSpanID != root.SpanContext.SpanID {
+		t.Errorf("Expected the child to belong to the root's trace, got %+v and %+v", child, root)
+	}
+
+	if root.ParentSpanID.IsValid() || root.Kind != SpanKindServer || child.Kind != SpanKindInternal {
+		t.Errorf("Unexpected root %+v or child kind %v", root, child.Kind)
+	}
+
+	if child.Attributes["matches"] != 20 || child.StatusCode != StatusError || child.StatusMessage != "boom" {
+		t.Errorf("Expected the child's attribute and error status, got %+v", child)
+	}
+}
+
+// TestTracer_RemoteParent tests continuing a caller's trace and honouring its sampling decision
+func TestTracer_RemoteParent(t *testing.T) {
+	recorder := &recordingExporter{}
+	tracer := NewTracer(recorder)
+
+	parent, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := tracer.Start(ContextWithRemoteParent(context.Background(), parent), "GET /health", SpanKindServer)
+	span.End()
+
+	if len(recorder.spans) != 1 || recorder.spans[0].SpanContext.TraceID != parent.TraceID || recorder.spans[0].ParentSpanID != parent.SpanID {
+		t.Errorf("Expected the span to continue the caller's trace, got %+v", recorder.spans)
+	}
+
+	unsampled, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx, span := tracer.Start(ContextWithRemoteParent(context.Background(), unsampled), "GET /health", SpanKindServer)
+	_, child := Start(ctx, "decodeBody")
+	child.End()
+	span.End()
+
+	if len(recorder.spans) != 1 {
+		t.Errorf("Expected spans of an unsampled trace not to be exported, got %d spans", len(recorder.spans))
+	}
+}
+
+// TestStart_Untraced tests that spans outside a traced request are no-ops
+func TestStart_Untraced(t *testing.T) {
+	ctx, span := Start(context.Background(), "calculatePlayerStats")
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Fatal("Expected a nil span without an active trace")
+	}
+
+	// Methods on a nil span must not panic
+	span.SetAttribute("matches", 1)
+	span.SetError(errors.New("ignored"))
+	span.End()
+}
@@ -1,18 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/OPGLOL/opgl-cortex-engine-service/internal/api"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/auth"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/metrics"
 	"github.com/OPGLOL/opgl-cortex-engine-service/internal/middleware"
 	"github.com/OPGLOL/opgl-cortex-engine-service/internal/services"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/storage"
+	"github.com/OPGLOL/opgl-cortex-engine-service/internal/tracing"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
+// shutdownTimeout bounds how long in-flight requests may take to finish after SIGINT or SIGTERM
+const shutdownTimeout = 15 * time.Second
+
 func main() {
 	// Initialize zerolog with colorized console output for development
 	log.Logger = zerolog.New(zerolog.ConsoleWriter{
@@ -35,17 +48,182 @@ func main() {
 		Str("port", port).
 		Msg("Configuration loaded")
 
-	// Initialize analysis service
+	// Initialize analysis service, loading coaching rules from RULES_FILE when set
 	analysisService := services.NewAnalysisService()
+	rulesPath := os.Getenv("RULES_FILE")
+	if rulesPath != "" {
+		var err error
+		analysisService, err = services.NewAnalysisServiceWithRules(rulesPath)
+		if err != nil {
+			log.Fatal().Err(err).Str("rules_file", rulesPath).Msg("Failed to load rule file")
+		}
+
+		log.Info().
+			Str("rules_file", rulesPath).
+			Msg("Rule file loaded")
+
+		// Reload rules on SIGHUP without restarting the service
+		reloadSignals := make(chan os.Signal, 1)
+		signal.Notify(reloadSignals, syscall.SIGHUP)
+		go func() {
+			for range reloadSignals {
+				version, err := analysisService.ReloadRules()
+				if err != nil {
+					log.Error().Err(err).Str("rules_file", rulesPath).Msg("Failed to reload rule file, keeping previous rules")
+					continue
+				}
+				log.Info().Str("version", version).Msg("Rule file reloaded")
+			}
+		}()
+	}
+
+	// Record request, analysis and improvement area metrics for /metrics
+	serviceMetrics := metrics.NewServiceMetrics()
+	analysisService.SetMetrics(serviceMetrics)
+
+	// Initialize HTTP handler, sizing the batch worker pool from BATCH_WORKERS when set
+	var handlerOptions []api.HandlerOption
+	if workers := positiveIntEnv("BATCH_WORKERS"); workers > 0 {
+		handlerOptions = append(handlerOptions, api.WithBatchWorkers(workers))
+	}
 
-	// Initialize HTTP handler
-	handler := api.NewHandler(analysisService)
+	// Default request validation mode from VALIDATION_MODE when set
+	if validationMode := os.Getenv("VALIDATION_MODE"); validationMode != "" {
+		if services.NormalizeValidationMode(validationMode) == "" {
+			log.Fatal().Str("validation_mode", validationMode).Msg("VALIDATION_MODE must be strict or lenient")
+		}
+		handlerOptions = append(handlerOptions, api.WithValidationMode(validationMode))
+	}
+
+	// Persist analysis and match history in ANALYSIS_DB_PATH when set, otherwise in memory
+	// Either way the analysis history is bounded per player (ANALYSIS_HISTORY_MAX) and expires (ANALYSIS_HISTORY_RETENTION)
+	historyOptions := []storage.HistoryOption{storage.WithMaxAnalysesPerPlayer(positiveIntEnv("ANALYSIS_HISTORY_MAX"))}
+	if historyRetention := os.Getenv("ANALYSIS_HISTORY_RETENTION"); historyRetention != "" {
+		retention, err := time.ParseDuration(historyRetention)
+		if err != nil || retention <= 0 {
+			log.Fatal().Str("analysis_history_retention", historyRetention).Msg("ANALYSIS_HISTORY_RETENTION must be a positive duration such as 24h")
+		}
+		historyOptions = append(historyOptions, storage.WithRetention(retention))
+	}
+
+	var analysisStore storage.Store = storage.NewMemoryStore(historyOptions...)
+	if databasePath := os.Getenv("ANALYSIS_DB_PATH"); databasePath != "" {
+		boltStore, err := storage.NewBoltStore(databasePath, historyOptions...)
+		if err != nil {
+			log.Fatal().Err(err).Str("analysis_db_path", databasePath).Msg("Failed to open analysis database")
+		}
+		analysisStore = boltStore
+
+		log.Info().
+			Str("analysis_db_path", databasePath).
+			Msg("Analysis database opened")
+	}
+	handlerOptions = append(handlerOptions, api.WithAnalysisStore(analysisStore))
+
+	// Pushed matches share the store with the analysis history
+	handlerOptions = append(handlerOptions, api.WithMatchIngestor(services.NewMatchIngestor(analysisService, analysisStore)))
+
+	handlerOptions = append(handlerOptions, api.WithMetrics(serviceMetrics))
+
+	// Require hashed API keys from API_KEYS_FILE and/or API_KEYS when either is set
+	var keyStore *auth.KeyStore
+	apiKeysPath := os.Getenv("API_KEYS_FILE")
+	if apiKeys := os.Getenv("API_KEYS"); apiKeysPath != "" || apiKeys != "" {
+		var err error
+		keyStore, err = auth.NewKeyStore(apiKeysPath, apiKeys)
+		if err != nil {
+			log.Fatal().Err(err).Str("api_keys_file", apiKeysPath).Msg("Failed to load API keys")
+		}
+		handlerOptions = append(handlerOptions, api.WithAPIKeys(keyStore))
+
+		log.Info().
+			Str("api_keys_file", apiKeysPath).
+			Msg("API key authentication enabled")
+
+		// Reload keys on SIGHUP so partner keys can be added or revoked without restarting
+		reloadSignals := make(chan os.Signal, 1)
+		signal.Notify(reloadSignals, syscall.SIGHUP)
+		go func() {
+			for range reloadSignals {
+				if err := keyStore.Reload(); err != nil {
+					log.Error().Err(err).Str("api_keys_file", apiKeysPath).Msg("Failed to reload API keys, keeping previous keys")
+					continue
+				}
+				log.Info().Msg("API keys reloaded")
+			}
+		}()
+	}
+
+	// Limit analyze and batch requests per client (API key, or IP when anonymous) from RATE_LIMIT_* when set
+	var analyzeLimiter, batchLimiter *middleware.RateLimiter
+	if requestsPerMinute := positiveIntEnv("RATE_LIMIT_ANALYZE"); requestsPerMinute > 0 {
+		analyzeLimiter = middleware.NewRateLimiter(requestsPerMinute, positiveIntEnv("RATE_LIMIT_ANALYZE_BURST"))
+	}
+	if requestsPerMinute := positiveIntEnv("RATE_LIMIT_BATCH"); requestsPerMinute > 0 {
+		batchLimiter = middleware.NewRateLimiter(requestsPerMinute, positiveIntEnv("RATE_LIMIT_BATCH_BURST"))
+	}
+	if analyzeLimiter != nil || batchLimiter != nil {
+		handlerOptions = append(handlerOptions, api.WithRateLimits(analyzeLimiter, batchLimiter))
+	}
+
+	// Cap the matches each client submits per UTC day from DAILY_MATCH_QUOTA when set
+	// Bodies counted for the quota are limited to QUOTA_MAX_BODY_BYTES (default: 32 MiB)
+	if dailyMatches := positiveIntEnv("DAILY_MATCH_QUOTA"); dailyMatches > 0 {
+		matchQuota := middleware.NewMatchQuota(dailyMatches, int64(positiveIntEnv("QUOTA_MAX_BODY_BYTES")))
+		handlerOptions = append(handlerOptions, api.WithMatchQuota(matchQuota))
+	}
+
+	handler := api.NewHandler(analysisService, handlerOptions...)
 
 	// Set up router
 	router := api.SetupRouter(handler)
 
-	// Wrap router with logging middleware
-	loggedRouter := middleware.LoggingMiddleware(router)
+	// Wrap router with metrics and logging middleware
+	var loggedRouter http.Handler = middleware.LoggingMiddleware(middleware.MetricsMiddleware(serviceMetrics, router))
+
+	// Identify the client before logging so request log lines carry its ID
+	if keyStore != nil {
+		loggedRouter = middleware.AuthenticationMiddleware(keyStore, loggedRouter)
+	}
+
+	// Trace requests when TRACE_EXPORTER is set: "stdout", or "otlp-file" appending OTLP/JSON to TRACE_FILE
+	var tracer *tracing.Tracer
+	if traceExporter := os.Getenv("TRACE_EXPORTER"); traceExporter != "" {
+		var exporter tracing.Exporter
+		switch traceExporter {
+		case "stdout":
+			exporter = tracing.NewStdoutExporter(os.Stdout)
+		case "otlp-file":
+			traceFile := os.Getenv("TRACE_FILE")
+			if traceFile == "" {
+				log.Fatal().Msg("TRACE_FILE is required when TRACE_EXPORTER is otlp-file")
+			}
+			otlpFileExporter, err := tracing.NewOTLPFileExporter(traceFile, "opgl-cortex-engine")
+			if err != nil {
+				log.Fatal().Err(err).Str("trace_file", traceFile).Msg("Failed to open trace file")
+			}
+			exporter = otlpFileExporter
+		default:
+			log.Fatal().Str("trace_exporter", traceExporter).Msg("TRACE_EXPORTER must be stdout or otlp-file")
+		}
+
+		tracer = tracing.NewTracer(exporter)
+		loggedRouter = middleware.TracingMiddleware(tracer, router, loggedRouter)
+
+		log.Info().
+			Str("trace_exporter", traceExporter).
+			Msg("Tracing enabled")
+	}
+
+	// Resolve client IPs for per-IP rate limits, honouring X-Forwarded-For only from TRUSTED_PROXIES
+	trustedProxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("TRUSTED_PROXIES must list proxy IPs or CIDR ranges")
+	}
+	loggedRouter = middleware.ClientIPMiddleware(trustedProxies, loggedRouter)
+
+	// The request ID middleware runs first so every log line and span of a request carries its X-Request-ID
+	loggedRouter = middleware.RequestIDMiddleware(loggedRouter)
 
 	// Start server
 	serverAddress := fmt.Sprintf(":%s", port)
@@ -54,7 +232,62 @@ func main() {
 		Str("port", port).
 		Msg("OPGL Cortex Engine listening")
 
-	if err := http.ListenAndServe(serverAddress, loggedRouter); err != nil {
-		log.Fatal().Err(err).Msg("Server failed to start")
+	server := &http.Server{Addr: serverAddress, Handler: loggedRouter}
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- server.ListenAndServe()
+	}()
+
+	// Stop accepting requests on SIGINT or SIGTERM and let in-flight requests finish
+	shutdownSignals := make(chan os.Signal, 1)
+	signal.Notify(shutdownSignals, os.Interrupt, syscall.SIGTERM)
+
+	serverFailed := false
+	select {
+	case err := <-serverErrors:
+		log.Error().Err(err).Msg("Server failed")
+		serverFailed = true
+	case received := <-shutdownSignals:
+		log.Info().Str("signal", received.String()).Msg("Shutting down OPGL Cortex Engine")
+
+		shutdownContext, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		if err := server.Shutdown(shutdownContext); err != nil {
+			log.Error().Err(err).Msg("In-flight requests did not finish before the shutdown timeout")
+		}
+		cancel()
+
+		if err := <-serverErrors; !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("Server failed")
+		}
+	}
+
+	// Flush buffered spans and close the store explicitly; deferred calls would not run before os.Exit
+	if tracer != nil {
+		if err := tracer.Shutdown(); err != nil {
+			log.Error().Err(err).Msg("Failed to flush traces")
+		}
+	}
+	if err := analysisStore.Close(); err != nil {
+		log.Error().Err(err).Msg("Failed to close analysis store")
+	}
+
+	if serverFailed {
+		os.Exit(1)
+	}
+	log.Info().Msg("OPGL Cortex Engine stopped")
+}
+
+// positiveIntEnv returns the positive integer in the environment variable name, or 0 when it is unset
+// The service refuses to start when the variable is set to anything else
+func positiveIntEnv(name string) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		log.Fatal().Str(strings.ToLower(name), value).Msg(name + " must be a positive integer")
 	}
+	return number
 }